@@ -0,0 +1,76 @@
+package checkers
+
+import (
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	core_v1 "k8s.io/api/core/v1"
+
+	"github.com/kiali/kiali/business/checkers/common"
+	"github.com/kiali/kiali/business/checkers/envoyfilters"
+	"github.com/kiali/kiali/models"
+)
+
+const EnvoyFilterCheckerType = "envoyfilter"
+
+type EnvoyFilterChecker struct {
+	EnvoyFilters     []networking_v1alpha3.EnvoyFilter
+	RootEnvoyFilters []networking_v1alpha3.EnvoyFilter
+	Pods             []core_v1.Pod
+	WorkloadList     models.WorkloadList
+}
+
+func (e EnvoyFilterChecker) Check() models.IstioValidations {
+	validations := models.IstioValidations{}
+
+	validations = validations.MergeValidations(e.runIndividualChecks())
+	validations = validations.MergeValidations(e.runGroupChecks())
+
+	return validations
+}
+
+func (e EnvoyFilterChecker) runGroupChecks() models.IstioValidations {
+	validations := models.IstioValidations{}
+
+	enabledCheckers := []GroupChecker{
+		envoyfilters.ListenerPriorityChecker{EnvoyFilters: e.EnvoyFilters, RootEnvoyFilters: e.RootEnvoyFilters},
+	}
+
+	for _, checker := range enabledCheckers {
+		validations = validations.MergeValidations(checker.Check())
+	}
+
+	return validations
+}
+
+func (e EnvoyFilterChecker) runIndividualChecks() models.IstioValidations {
+	validations := models.IstioValidations{}
+
+	pods := models.Pods{}
+	pods.Parse(e.Pods)
+
+	for _, envoyFilter := range e.EnvoyFilters {
+		validations.MergeValidations(e.runChecks(envoyFilter, pods))
+	}
+
+	return validations
+}
+
+func (e EnvoyFilterChecker) runChecks(envoyFilter networking_v1alpha3.EnvoyFilter, pods models.Pods) models.IstioValidations {
+	key, rrValidation := EmptyValidValidation(envoyFilter.Name, envoyFilter.Namespace, EnvoyFilterCheckerType)
+	selectorLabels := make(map[string]string)
+	if envoyFilter.Spec.WorkloadSelector != nil {
+		selectorLabels = envoyFilter.Spec.WorkloadSelector.Labels
+	}
+
+	enabledCheckers := []Checker{
+		common.WorkloadSelectorNoWorkloadFoundChecker(EnvoyFilterCheckerType, selectorLabels, e.WorkloadList),
+		envoyfilters.ProxyVersionChecker{EnvoyFilter: envoyFilter, Pods: pods},
+	}
+
+	for _, checker := range enabledCheckers {
+		checks, validChecker := checker.Check()
+		rrValidation.Checks = append(rrValidation.Checks, checks...)
+		rrValidation.Valid = rrValidation.Valid && validChecker
+	}
+
+	return models.IstioValidations{key: rrValidation}
+}
@@ -0,0 +1,144 @@
+package envoyfilters
+
+import (
+	"fmt"
+
+	api_networking_v1alpha3 "istio.io/api/networking/v1alpha3"
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/models"
+)
+
+const EnvoyFilterCheckerType = "envoyfilter"
+
+type ListenerPriorityChecker struct {
+	EnvoyFilters     []networking_v1alpha3.EnvoyFilter
+	RootEnvoyFilters []networking_v1alpha3.EnvoyFilter
+}
+
+type listenerPatch struct {
+	EnvoyFilter *networking_v1alpha3.EnvoyFilter
+	PatchIndex  int
+	Context     api_networking_v1alpha3.EnvoyFilter_PatchContext
+	Listener    *api_networking_v1alpha3.EnvoyFilter_ListenerMatch
+	Root        bool
+}
+
+// Check validates that no two EnvoyFilters of the same priority patch the same listener of the same workloads,
+// as the order in which Istio applies them is then only given by their creation time. The EnvoyFilters of the
+// root namespace apply to the workloads of every namespace, they are checked against the EnvoyFilters of the
+// namespace.
+func (l ListenerPriorityChecker) Check() models.IstioValidations {
+	validations := models.IstioValidations{}
+
+	patches := make([]listenerPatch, 0, len(l.EnvoyFilters)+len(l.RootEnvoyFilters))
+	patches = appendListenerPatches(patches, l.EnvoyFilters, false)
+	patches = appendListenerPatches(patches, l.RootEnvoyFilters, true)
+
+	for i, a := range patches {
+		for _, b := range patches[i+1:] {
+			// the root namespace filters are checked against each other when validating the root namespace
+			if a.EnvoyFilter == b.EnvoyFilter || (a.Root && b.Root) || !a.conflictsWith(b) {
+				continue
+			}
+			aValidation := createWarning(a)
+			bValidation := createWarning(b)
+			aValidation = aValidation.MergeReferences(bValidation)
+			bValidation = bValidation.MergeReferences(aValidation)
+			validations = validations.MergeValidations(aValidation)
+			validations = validations.MergeValidations(bValidation)
+		}
+	}
+
+	return validations
+}
+
+func appendListenerPatches(patches []listenerPatch, envoyFilters []networking_v1alpha3.EnvoyFilter, root bool) []listenerPatch {
+	for i := range envoyFilters {
+		ef := &envoyFilters[i]
+		for pi, patch := range ef.Spec.ConfigPatches {
+			if patch == nil || patch.Match == nil || patch.Match.GetListener() == nil {
+				continue
+			}
+			patches = append(patches, listenerPatch{
+				EnvoyFilter: ef,
+				PatchIndex:  pi,
+				Context:     patch.Match.Context,
+				Listener:    patch.Match.GetListener(),
+				Root:        root,
+			})
+		}
+	}
+	return patches
+}
+
+func (a listenerPatch) conflictsWith(b listenerPatch) bool {
+	return sameNamespaceWorkloads(a.EnvoyFilter, b.EnvoyFilter) &&
+		a.EnvoyFilter.Spec.Priority == b.EnvoyFilter.Spec.Priority &&
+		sameWorkloads(a.EnvoyFilter, b.EnvoyFilter) &&
+		(a.Context == b.Context || a.Context == api_networking_v1alpha3.EnvoyFilter_ANY || b.Context == api_networking_v1alpha3.EnvoyFilter_ANY) &&
+		sameListener(a.Listener, b.Listener)
+}
+
+// sameNamespaceWorkloads returns true when both EnvoyFilters may apply to the workloads of a same namespace: they
+// are in the same namespace, or one of them is in the root namespace, applying to every namespace
+func sameNamespaceWorkloads(a, b *networking_v1alpha3.EnvoyFilter) bool {
+	rootNamespace := config.Get().IstioNamespace
+	return a.Namespace == b.Namespace || a.Namespace == rootNamespace || b.Namespace == rootNamespace
+}
+
+// sameListener returns true when both matches may select the same listener and filter chain, a field not set
+// matching any value
+func sameListener(a, b *api_networking_v1alpha3.EnvoyFilter_ListenerMatch) bool {
+	aChain, bChain := a.GetFilterChain(), b.GetFilterChain()
+	return samePort(a.PortNumber, b.PortNumber) &&
+		sameValue(a.PortName, b.PortName) &&
+		sameValue(a.Name, b.Name) &&
+		sameValue(aChain.GetName(), bChain.GetName()) &&
+		sameValue(aChain.GetSni(), bChain.GetSni()) &&
+		sameValue(aChain.GetTransportProtocol(), bChain.GetTransportProtocol()) &&
+		sameValue(aChain.GetApplicationProtocols(), bChain.GetApplicationProtocols()) &&
+		samePort(aChain.GetDestinationPort(), bChain.GetDestinationPort()) &&
+		sameValue(aChain.GetFilter().GetName(), bChain.GetFilter().GetName()) &&
+		sameValue(aChain.GetFilter().GetSubFilter().GetName(), bChain.GetFilter().GetSubFilter().GetName())
+}
+
+func sameValue(a, b string) bool {
+	return a == b || a == "" || b == ""
+}
+
+func samePort(a, b uint32) bool {
+	return a == b || a == 0 || b == 0
+}
+
+// sameWorkloads returns true when both EnvoyFilters apply to the same workloads: they have the same selector
+// or one of them has no selector, applying to every workload of the namespace
+func sameWorkloads(a, b *networking_v1alpha3.EnvoyFilter) bool {
+	aSelector := selectorLabels(a)
+	bSelector := selectorLabels(b)
+	return len(aSelector) == 0 || len(bSelector) == 0 || labels.Equals(aSelector, bSelector)
+}
+
+func selectorLabels(ef *networking_v1alpha3.EnvoyFilter) labels.Set {
+	if ef.Spec.WorkloadSelector == nil {
+		return nil
+	}
+	return ef.Spec.WorkloadSelector.Labels
+}
+
+func createWarning(patch listenerPatch) models.IstioValidations {
+	key := models.IstioValidationKey{Name: patch.EnvoyFilter.Name, Namespace: patch.EnvoyFilter.Namespace, ObjectType: EnvoyFilterCheckerType}
+	check := models.Build("envoyfilter.listener.samepriority", fmt.Sprintf("spec/configPatches[%d]/match/listener", patch.PatchIndex))
+	rrValidation := &models.IstioValidation{
+		Name:       patch.EnvoyFilter.Name,
+		ObjectType: EnvoyFilterCheckerType,
+		Valid:      true,
+		Checks: []*models.IstioCheck{
+			&check,
+		},
+	}
+
+	return models.IstioValidations{key: rrValidation}
+}
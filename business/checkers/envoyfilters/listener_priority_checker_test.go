@@ -0,0 +1,187 @@
+package envoyfilters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	api_networking_v1alpha3 "istio.io/api/networking/v1alpha3"
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/tests/data"
+	"github.com/kiali/kiali/tests/testutils/validations"
+)
+
+func TestSamePriorityOnSameListener(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals := ListenerPriorityChecker{
+		EnvoyFilters: []networking_v1alpha3.EnvoyFilter{
+			*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+				data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter1", "bookinfo"))),
+			*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+				data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_OUTBOUND, 9080, "",
+					data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter2", "bookinfo")))),
+		},
+	}.Check()
+
+	assert.Len(vals, 2)
+
+	validation, ok := vals[models.BuildKey(EnvoyFilterCheckerType, "filter1", "bookinfo")]
+	assert.True(ok)
+	assert.True(validation.Valid)
+	assert.Len(validation.Checks, 1)
+	assert.Equal(models.WarningSeverity, validation.Checks[0].Severity)
+	assert.Equal("spec/configPatches[0]/match/listener", validation.Checks[0].Path)
+	assert.NoError(validations.ConfirmIstioCheckMessage("envoyfilter.listener.samepriority", validation.Checks[0]))
+	assert.Equal([]models.IstioValidationKey{models.BuildKey(EnvoyFilterCheckerType, "filter2", "bookinfo")}, validation.References)
+
+	validation, ok = vals[models.BuildKey(EnvoyFilterCheckerType, "filter2", "bookinfo")]
+	assert.True(ok)
+	assert.Len(validation.Checks, 1)
+	assert.Equal("spec/configPatches[1]/match/listener", validation.Checks[0].Path)
+}
+
+func TestSamePriorityOnNamespaceWideFilter(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals := ListenerPriorityChecker{
+		EnvoyFilters: []networking_v1alpha3.EnvoyFilter{
+			*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_ANY, 0, "",
+				data.CreateEnvoyFilter("filter1", "bookinfo")),
+			*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+				data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter2", "bookinfo"))),
+		},
+	}.Check()
+
+	assert.Len(vals, 2)
+}
+
+func TestDifferentPriorities(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	ef := data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+		data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter2", "bookinfo")))
+	ef.Spec.Priority = 10
+
+	vals := ListenerPriorityChecker{
+		EnvoyFilters: []networking_v1alpha3.EnvoyFilter{
+			*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+				data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter1", "bookinfo"))),
+			*ef,
+		},
+	}.Check()
+
+	assert.Empty(vals)
+}
+
+func TestDifferentListenersOrWorkloads(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals := ListenerPriorityChecker{
+		EnvoyFilters: []networking_v1alpha3.EnvoyFilter{
+			*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+				data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter1", "bookinfo"))),
+			// other port
+			*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9090, "",
+				data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter2", "bookinfo"))),
+			// other context
+			*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_OUTBOUND, 9080, "",
+				data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter3", "bookinfo"))),
+			// other workloads
+			*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+				data.AddSelectorToEnvoyFilter(map[string]string{"app": "ratings"}, data.CreateEnvoyFilter("filter4", "bookinfo"))),
+			// other namespace
+			*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+				data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter5", "travels"))),
+		},
+	}.Check()
+
+	assert.Empty(vals)
+}
+
+func TestSamePriorityOnDifferentFilterChains(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	withFilterChain := func(filterName string, ef *networking_v1alpha3.EnvoyFilter) *networking_v1alpha3.EnvoyFilter {
+		ef.Spec.ConfigPatches[0].Match.GetListener().FilterChain = &api_networking_v1alpha3.EnvoyFilter_ListenerMatch_FilterChainMatch{
+			Filter: &api_networking_v1alpha3.EnvoyFilter_ListenerMatch_FilterMatch{Name: filterName},
+		}
+		return ef
+	}
+
+	vals := ListenerPriorityChecker{
+		EnvoyFilters: []networking_v1alpha3.EnvoyFilter{
+			*withFilterChain("envoy.filters.network.http_connection_manager", data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+				data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter1", "bookinfo")))),
+			*withFilterChain("envoy.filters.network.tcp_proxy", data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+				data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter2", "bookinfo")))),
+		},
+	}.Check()
+
+	assert.Empty(vals)
+
+	// A patch matching any filter chain conflicts with both
+	vals = ListenerPriorityChecker{
+		EnvoyFilters: []networking_v1alpha3.EnvoyFilter{
+			*withFilterChain("envoy.filters.network.http_connection_manager", data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+				data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter1", "bookinfo")))),
+			*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+				data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter2", "bookinfo"))),
+		},
+	}.Check()
+
+	assert.Len(vals, 2)
+}
+
+func TestSamePriorityOnRootNamespaceFilter(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	rootFilters := []networking_v1alpha3.EnvoyFilter{
+		*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+			data.CreateEnvoyFilter("mesh-filter", "istio-system")),
+		*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+			data.CreateEnvoyFilter("other-mesh-filter", "istio-system")),
+	}
+
+	vals := ListenerPriorityChecker{
+		EnvoyFilters: []networking_v1alpha3.EnvoyFilter{
+			*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+				data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter1", "bookinfo"))),
+		},
+		RootEnvoyFilters: rootFilters,
+	}.Check()
+
+	// The root namespace filters conflict with the namespace filter, not with each other
+	assert.Len(vals, 3)
+	validation, ok := vals[models.BuildKey(EnvoyFilterCheckerType, "filter1", "bookinfo")]
+	assert.True(ok)
+	assert.Len(validation.Checks, 1)
+	assert.ElementsMatch([]models.IstioValidationKey{
+		models.BuildKey(EnvoyFilterCheckerType, "mesh-filter", "istio-system"),
+		models.BuildKey(EnvoyFilterCheckerType, "other-mesh-filter", "istio-system"),
+	}, validation.References)
+
+	validation, ok = vals[models.BuildKey(EnvoyFilterCheckerType, "mesh-filter", "istio-system")]
+	assert.True(ok)
+	assert.Equal([]models.IstioValidationKey{models.BuildKey(EnvoyFilterCheckerType, "filter1", "bookinfo")}, validation.References)
+
+	// The filters of another namespace do not apply to the workloads of the namespace
+	vals = ListenerPriorityChecker{
+		EnvoyFilters: []networking_v1alpha3.EnvoyFilter{
+			*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+				data.CreateEnvoyFilter("filter1", "bookinfo")),
+			*data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, "",
+				data.CreateEnvoyFilter("filter2", "travels")),
+		},
+	}.Check()
+
+	assert.Empty(vals)
+}
@@ -0,0 +1,95 @@
+package envoyfilters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kiali/kiali/models"
+)
+
+type ProxyVersionChecker struct {
+	EnvoyFilter networking_v1alpha3.EnvoyFilter
+	Pods        models.Pods
+}
+
+// Check validates that the proxy versions targeted by the patches match at least one of the sidecars running
+// in the workloads of the EnvoyFilter. Nothing is checked when the versions of the sidecars are unknown.
+func (p ProxyVersionChecker) Check() ([]*models.IstioCheck, bool) {
+	checks, valid := make([]*models.IstioCheck, 0), true
+
+	versions := p.sidecarVersions()
+	for i, patch := range p.EnvoyFilter.Spec.ConfigPatches {
+		if patch == nil || patch.Match == nil || patch.Match.Proxy == nil || patch.Match.Proxy.ProxyVersion == "" {
+			continue
+		}
+		path := fmt.Sprintf("spec/configPatches[%d]/match/proxy/proxyVersion", i)
+		versionRegexp, err := regexp.Compile(patch.Match.Proxy.ProxyVersion)
+		if err != nil {
+			check := models.Build("envoyfilter.proxy.invalidversion", path)
+			checks = append(checks, &check)
+			valid = false
+			continue
+		}
+		if len(versions) > 0 && !matchesAny(versionRegexp, versions) {
+			check := models.Build("envoyfilter.proxy.versionnotfound", path)
+			checks = append(checks, &check)
+		}
+	}
+
+	return checks, valid
+}
+
+// sidecarVersions returns the versions of the sidecars of the pods selected by the EnvoyFilter, as given by
+// the tag of their image
+func (p ProxyVersionChecker) sidecarVersions() []string {
+	selector := labels.Everything()
+	if p.EnvoyFilter.Spec.WorkloadSelector != nil {
+		selector = labels.SelectorFromSet(p.EnvoyFilter.Spec.WorkloadSelector.Labels)
+	}
+
+	versions := []string{}
+	for _, pod := range p.Pods {
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		for _, c := range pod.IstioContainers {
+			if version := imageVersion(c.Image); version != "" {
+				versions = append(versions, version)
+			}
+		}
+	}
+	return versions
+}
+
+// imageVersion returns the version of an image tagged by version, e.g. 1.11.2 for docker.io/istio/proxyv2:1.11.2
+// or docker.io/istio/proxyv2:1.11.2-distroless, and "" when the tag is not a version
+func imageVersion(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+	tag := image[i+1:]
+	if i := strings.Index(tag, "-"); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "" || tag[0] < '0' || tag[0] > '9' {
+		return ""
+	}
+	return tag
+}
+
+func matchesAny(versionRegexp *regexp.Regexp, versions []string) bool {
+	for _, version := range versions {
+		if versionRegexp.MatchString(version) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package envoyfilters
+
+import (
+	"testing"
+
+	api_networking_v1alpha3 "istio.io/api/networking/v1alpha3"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/tests/data"
+	"github.com/kiali/kiali/tests/testutils/validations"
+)
+
+func fakeSidecarPods() models.Pods {
+	return models.Pods{
+		{
+			Name:            "reviews-v1",
+			Labels:          map[string]string{"app": "reviews", "version": "v1"},
+			IstioContainers: []*models.ContainerInfo{{Name: "istio-proxy", Image: "docker.io/istio/proxyv2:1.11.2"}},
+		},
+		{
+			Name:            "reviews-v2",
+			Labels:          map[string]string{"app": "reviews", "version": "v2"},
+			IstioContainers: []*models.ContainerInfo{{Name: "istio-proxy", Image: "docker.io/istio/proxyv2:1.10.4-distroless"}},
+		},
+		{
+			Name:            "ratings-v1",
+			Labels:          map[string]string{"app": "ratings", "version": "v1"},
+			IstioContainers: []*models.ContainerInfo{{Name: "istio-proxy", Image: "registry.local:5000/istio/proxyv2@sha256:0123"}},
+		},
+	}
+}
+
+func TestProxyVersionMatching(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	for _, version := range []string{"", `^1\.11.*`, `^1\.10.*`, "1.1"} {
+		vals, valid := ProxyVersionChecker{
+			EnvoyFilter: *data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, version,
+				data.AddSelectorToEnvoyFilter(map[string]string{"app": "reviews"}, data.CreateEnvoyFilter("filter1", "bookinfo"))),
+			Pods: fakeSidecarPods(),
+		}.Check()
+
+		validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}.AssertNoValidations()
+	}
+}
+
+func TestProxyVersionNotMatching(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	vals, valid := ProxyVersionChecker{
+		EnvoyFilter: *data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, `^1\.9.*`,
+			data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, `^1\.11.*`,
+				data.CreateEnvoyFilter("filter1", "bookinfo"))),
+		Pods: fakeSidecarPods(),
+	}.Check()
+
+	tb := validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}
+	tb.AssertValidationsPresent(1, true)
+	tb.AssertValidationAt(0, models.WarningSeverity, "spec/configPatches[1]/match/proxy/proxyVersion", "envoyfilter.proxy.versionnotfound")
+}
+
+func TestProxyVersionNotMatchingSelectedWorkloads(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	vals, valid := ProxyVersionChecker{
+		EnvoyFilter: *data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, `^1\.10.*`,
+			data.AddSelectorToEnvoyFilter(map[string]string{"version": "v1"}, data.CreateEnvoyFilter("filter1", "bookinfo"))),
+		Pods: fakeSidecarPods(),
+	}.Check()
+
+	tb := validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}
+	tb.AssertValidationsPresent(1, true)
+	tb.AssertValidationAt(0, models.WarningSeverity, "spec/configPatches[0]/match/proxy/proxyVersion", "envoyfilter.proxy.versionnotfound")
+}
+
+func TestProxyVersionUnknown(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	// the version of the ratings sidecar is not known from its image
+	vals, valid := ProxyVersionChecker{
+		EnvoyFilter: *data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, `^1\.9.*`,
+			data.AddSelectorToEnvoyFilter(map[string]string{"app": "ratings"}, data.CreateEnvoyFilter("filter1", "bookinfo"))),
+		Pods: fakeSidecarPods(),
+	}.Check()
+
+	validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}.AssertNoValidations()
+}
+
+func TestProxyVersionInvalid(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	vals, valid := ProxyVersionChecker{
+		EnvoyFilter: *data.AddListenerPatchToEnvoyFilter(api_networking_v1alpha3.EnvoyFilter_SIDECAR_INBOUND, 9080, `^1\.(11.*`,
+			data.CreateEnvoyFilter("filter1", "bookinfo")),
+	}.Check()
+
+	tb := validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}
+	tb.AssertValidationsPresent(1, false)
+	tb.AssertValidationAt(0, models.ErrorSeverity, "spec/configPatches[0]/match/proxy/proxyVersion", "envoyfilter.proxy.invalidversion")
+}
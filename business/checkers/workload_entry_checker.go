@@ -0,0 +1,67 @@
+package checkers
+
+import (
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	core_v1 "k8s.io/api/core/v1"
+
+	"github.com/kiali/kiali/business/checkers/workloadentries"
+	"github.com/kiali/kiali/models"
+)
+
+const WorkloadEntryCheckerType = "workloadentry"
+
+type WorkloadEntryChecker struct {
+	WorkloadEntries []networking_v1alpha3.WorkloadEntry
+	ServiceEntries  []networking_v1alpha3.ServiceEntry
+	Services        []core_v1.Service
+}
+
+func (w WorkloadEntryChecker) Check() models.IstioValidations {
+	validations := models.IstioValidations{}
+
+	validations = validations.MergeValidations(w.runIndividualChecks())
+	validations = validations.MergeValidations(w.runGroupChecks())
+
+	return validations
+}
+
+func (w WorkloadEntryChecker) runGroupChecks() models.IstioValidations {
+	validations := models.IstioValidations{}
+
+	enabledCheckers := []GroupChecker{
+		workloadentries.MultiMatchChecker{WorkloadEntries: w.WorkloadEntries},
+	}
+
+	for _, checker := range enabledCheckers {
+		validations = validations.MergeValidations(checker.Check())
+	}
+
+	return validations
+}
+
+func (w WorkloadEntryChecker) runIndividualChecks() models.IstioValidations {
+	validations := models.IstioValidations{}
+
+	for _, we := range w.WorkloadEntries {
+		validations.MergeValidations(w.runChecks(we))
+	}
+
+	return validations
+}
+
+func (w WorkloadEntryChecker) runChecks(we networking_v1alpha3.WorkloadEntry) models.IstioValidations {
+	key, rrValidation := EmptyValidValidation(we.Name, we.Namespace, WorkloadEntryCheckerType)
+
+	enabledCheckers := []Checker{
+		workloadentries.ServiceEntryChecker{WorkloadEntry: we, ServiceEntries: w.ServiceEntries, Services: w.Services},
+		workloadentries.PortChecker{WorkloadEntry: we, ServiceEntries: w.ServiceEntries},
+	}
+
+	for _, checker := range enabledCheckers {
+		checks, validChecker := checker.Check()
+		rrValidation.Checks = append(rrValidation.Checks, checks...)
+		rrValidation.Valid = rrValidation.Valid && validChecker
+	}
+
+	return models.IstioValidations{key: rrValidation}
+}
@@ -0,0 +1,41 @@
+package checkers
+
+import (
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+
+	"github.com/kiali/kiali/business/checkers/workloadgroups"
+	"github.com/kiali/kiali/models"
+)
+
+const WorkloadGroupCheckerType = "workloadgroup"
+
+type WorkloadGroupChecker struct {
+	WorkloadGroups  []networking_v1alpha3.WorkloadGroup
+	WorkloadEntries []networking_v1alpha3.WorkloadEntry
+}
+
+func (w WorkloadGroupChecker) Check() models.IstioValidations {
+	validations := models.IstioValidations{}
+
+	for _, wg := range w.WorkloadGroups {
+		validations.MergeValidations(w.runSingleChecks(wg))
+	}
+
+	return validations
+}
+
+func (w WorkloadGroupChecker) runSingleChecks(wg networking_v1alpha3.WorkloadGroup) models.IstioValidations {
+	key, validations := EmptyValidValidation(wg.Name, wg.Namespace, WorkloadGroupCheckerType)
+
+	enabledCheckers := []Checker{
+		workloadgroups.TemplateChecker{WorkloadGroup: wg, WorkloadEntries: w.WorkloadEntries},
+	}
+
+	for _, checker := range enabledCheckers {
+		checks, validChecker := checker.Check()
+		validations.Checks = append(validations.Checks, checks...)
+		validations.Valid = validations.Valid && validChecker
+	}
+
+	return models.IstioValidations{key: validations}
+}
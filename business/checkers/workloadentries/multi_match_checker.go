@@ -0,0 +1,55 @@
+package workloadentries
+
+import (
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+
+	"github.com/kiali/kiali/models"
+)
+
+const WorkloadEntryCheckerType = "workloadentry"
+
+type MultiMatchChecker struct {
+	WorkloadEntries []networking_v1alpha3.WorkloadEntry
+}
+
+type address struct {
+	Network string
+	Address string
+}
+
+// Check validates that no two WorkloadEntries share the same address in the same network. Only the given
+// WorkloadEntries are compared: entries of other namespaces, e.g. imported by the same workload, are not checked.
+func (m MultiMatchChecker) Check() models.IstioValidations {
+	validations := models.IstioValidations{}
+
+	entriesByAddress := map[address][]models.IstioValidationKey{}
+	for _, we := range m.WorkloadEntries {
+		if we.Spec.Address == "" {
+			continue
+		}
+		a := address{Network: we.Spec.Network, Address: we.Spec.Address}
+		key := models.BuildKey(WorkloadEntryCheckerType, we.Name, we.Namespace)
+		entriesByAddress[a] = append(entriesByAddress[a], key)
+	}
+
+	for _, keys := range entriesByAddress {
+		if len(keys) < 2 {
+			continue
+		}
+		for i, key := range keys {
+			check := models.Build("workloadentry.address.multimatch", "spec/address")
+			references := make([]models.IstioValidationKey, 0, len(keys)-1)
+			references = append(references, keys[:i]...)
+			references = append(references, keys[i+1:]...)
+			validations.MergeValidations(models.IstioValidations{key: &models.IstioValidation{
+				Name:       key.Name,
+				ObjectType: WorkloadEntryCheckerType,
+				Valid:      false,
+				Checks:     []*models.IstioCheck{&check},
+				References: references,
+			}})
+		}
+	}
+
+	return validations
+}
@@ -0,0 +1,53 @@
+package workloadentries
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/tests/data"
+	"github.com/kiali/kiali/tests/testutils/validations"
+)
+
+func TestWorkloadEntriesSameAddress(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals := MultiMatchChecker{
+		WorkloadEntries: []networking_v1alpha3.WorkloadEntry{
+			*data.CreateWorkloadEntry("details-vm", "bookinfo", "2.2.2.2", map[string]string{"app": "details-legacy"}),
+			*data.CreateWorkloadEntry("ratings-vm", "bookinfo", "2.2.2.2", map[string]string{"app": "ratings-legacy"}),
+			*data.CreateWorkloadEntry("reviews-vm", "bookinfo", "3.3.3.3", map[string]string{"app": "reviews-legacy"}),
+		},
+	}.Check()
+
+	assert.Len(vals, 2)
+
+	vta := validations.ValidationsTestAsserter{T: t, Validations: vals}
+	vta.AssertValidationAt(models.BuildKey(WorkloadEntryCheckerType, "details-vm", "bookinfo"), models.ErrorSeverity, "spec/address", "workloadentry.address.multimatch")
+	vta.AssertValidationAt(models.BuildKey(WorkloadEntryCheckerType, "ratings-vm", "bookinfo"), models.ErrorSeverity, "spec/address", "workloadentry.address.multimatch")
+	assert.Equal([]models.IstioValidationKey{models.BuildKey(WorkloadEntryCheckerType, "ratings-vm", "bookinfo")},
+		vals[models.BuildKey(WorkloadEntryCheckerType, "details-vm", "bookinfo")].References)
+}
+
+func TestWorkloadEntriesSameAddressOtherNetwork(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	we := data.CreateWorkloadEntry("ratings-vm", "bookinfo", "2.2.2.2", map[string]string{"app": "ratings-legacy"})
+	we.Spec.Network = "vm-network"
+
+	vals := MultiMatchChecker{
+		WorkloadEntries: []networking_v1alpha3.WorkloadEntry{
+			*data.CreateWorkloadEntry("details-vm", "bookinfo", "2.2.2.2", map[string]string{"app": "details-legacy"}),
+			*we,
+			// entries without address are not compared
+			*data.CreateWorkloadEntry("reviews-vm", "bookinfo", "", map[string]string{"app": "reviews-legacy"}),
+			*data.CreateWorkloadEntry("productpage-vm", "bookinfo", "", map[string]string{"app": "productpage-legacy"}),
+		},
+	}.Check()
+
+	validations.ValidationsTestAsserter{T: t, Validations: vals}.AssertNoValidations()
+}
@@ -0,0 +1,50 @@
+package workloadentries
+
+import (
+	"fmt"
+	"sort"
+
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+
+	"github.com/kiali/kiali/models"
+)
+
+type PortChecker struct {
+	WorkloadEntry  networking_v1alpha3.WorkloadEntry
+	ServiceEntries []networking_v1alpha3.ServiceEntry
+}
+
+// Check validates that the ports of the WorkloadEntry are declared by the ServiceEntries selecting it, as the
+// ports are mapped by name. Nothing is checked when no ServiceEntry selects the WorkloadEntry.
+func (p PortChecker) Check() ([]*models.IstioCheck, bool) {
+	checks := make([]*models.IstioCheck, 0)
+
+	serviceEntries := selectingServiceEntries(p.WorkloadEntry, p.ServiceEntries)
+	if len(serviceEntries) == 0 {
+		return checks, true
+	}
+
+	declaredPorts := map[string]bool{}
+	for _, se := range serviceEntries {
+		for _, port := range se.Spec.Ports {
+			if port != nil {
+				declaredPorts[port.Name] = true
+			}
+		}
+	}
+
+	portNames := make([]string, 0, len(p.WorkloadEntry.Spec.Ports))
+	for name := range p.WorkloadEntry.Spec.Ports {
+		portNames = append(portNames, name)
+	}
+	sort.Strings(portNames)
+
+	for _, name := range portNames {
+		if !declaredPorts[name] {
+			check := models.Build("workloadentry.ports.notfound", fmt.Sprintf("spec/ports/%s", name))
+			checks = append(checks, &check)
+		}
+	}
+
+	return checks, true
+}
@@ -0,0 +1,50 @@
+package workloadentries
+
+import (
+	"testing"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/tests/data"
+	"github.com/kiali/kiali/tests/testutils/validations"
+)
+
+func TestWorkloadEntryPortsDeclared(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	// ports are declared by any of the selecting ServiceEntries
+	vals, valid := PortChecker{
+		WorkloadEntry: *data.AddPortToWorkloadEntry("grpc", 8090, data.AddPortToWorkloadEntry("http", 8080,
+			data.CreateWorkloadEntry("details-vm", "bookinfo", "2.2.2.2", map[string]string{"app": "details-legacy", "version": "v1"}))),
+		ServiceEntries: fakeServiceEntries(),
+	}.Check()
+
+	validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}.AssertNoValidations()
+}
+
+func TestWorkloadEntryPortsNotDeclared(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	vals, valid := PortChecker{
+		WorkloadEntry: *data.AddPortToWorkloadEntry("tcp", 3306, data.AddPortToWorkloadEntry("grpc", 8090, data.AddPortToWorkloadEntry("http", 8080,
+			data.CreateWorkloadEntry("details-vm", "bookinfo", "2.2.2.2", map[string]string{"app": "details-legacy"})))),
+		ServiceEntries: fakeServiceEntries(),
+	}.Check()
+
+	tb := validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}
+	tb.AssertValidationsPresent(2, true)
+	tb.AssertValidationAt(0, models.WarningSeverity, "spec/ports/grpc", "workloadentry.ports.notfound")
+	tb.AssertValidationAt(1, models.WarningSeverity, "spec/ports/tcp", "workloadentry.ports.notfound")
+}
+
+func TestWorkloadEntryPortsNotSelected(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	vals, valid := PortChecker{
+		WorkloadEntry: *data.AddPortToWorkloadEntry("tcp", 3306,
+			data.CreateWorkloadEntry("mysql-vm", "bookinfo", "2.2.2.2", map[string]string{"app": "mysql"})),
+		ServiceEntries: fakeServiceEntries(),
+	}.Check()
+
+	validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}.AssertNoValidations()
+}
@@ -0,0 +1,56 @@
+package workloadentries
+
+import (
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kiali/kiali/models"
+)
+
+type ServiceEntryChecker struct {
+	WorkloadEntry  networking_v1alpha3.WorkloadEntry
+	ServiceEntries []networking_v1alpha3.ServiceEntry
+	Services       []core_v1.Service
+}
+
+// Check validates that the WorkloadEntry is selected by the workloadSelector of a ServiceEntry, or by the
+// selector of a Kubernetes Service of its namespace
+func (s ServiceEntryChecker) Check() ([]*models.IstioCheck, bool) {
+	checks := make([]*models.IstioCheck, 0)
+
+	if len(selectingServiceEntries(s.WorkloadEntry, s.ServiceEntries)) == 0 && !selectedByService(s.WorkloadEntry, s.Services) {
+		check := models.Build("workloadentry.labels.serviceentrynotfound", "spec/labels")
+		checks = append(checks, &check)
+	}
+
+	return checks, true
+}
+
+// selectingServiceEntries returns the ServiceEntries whose workloadSelector matches the labels of the
+// WorkloadEntry. The selector of a ServiceEntry only applies to the WorkloadEntries of its namespace.
+func selectingServiceEntries(we networking_v1alpha3.WorkloadEntry, serviceEntries []networking_v1alpha3.ServiceEntry) []networking_v1alpha3.ServiceEntry {
+	selecting := []networking_v1alpha3.ServiceEntry{}
+	for _, se := range serviceEntries {
+		if se.Namespace != we.Namespace || se.Spec.WorkloadSelector == nil || len(se.Spec.WorkloadSelector.Labels) == 0 {
+			continue
+		}
+		if labels.SelectorFromSet(se.Spec.WorkloadSelector.Labels).Matches(labels.Set(we.Spec.Labels)) {
+			selecting = append(selecting, se)
+		}
+	}
+	return selecting
+}
+
+// selectedByService returns true when the selector of a Service of the WorkloadEntry's namespace matches its labels
+func selectedByService(we networking_v1alpha3.WorkloadEntry, services []core_v1.Service) bool {
+	for _, svc := range services {
+		if svc.Namespace != we.Namespace || len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		if labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(we.Spec.Labels)) {
+			return true
+		}
+	}
+	return false
+}
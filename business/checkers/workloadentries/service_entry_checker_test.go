@@ -0,0 +1,97 @@
+package workloadentries
+
+import (
+	"testing"
+
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/tests/data"
+	"github.com/kiali/kiali/tests/testutils/validations"
+)
+
+func fakeServiceEntries() []networking_v1alpha3.ServiceEntry {
+	return []networking_v1alpha3.ServiceEntry{
+		*data.AddPortDefinitionToServiceEntry(data.CreateEmptyPortDefinition(9080, "http", "HTTP"),
+			data.AddWorkloadSelectorToServiceEntry(map[string]string{"app": "details-legacy"},
+				data.CreateEmptyMeshInternalServiceEntry("details-svc", "bookinfo", []string{"details.bookinfo.com"}))),
+		*data.AddPortDefinitionToServiceEntry(data.CreateEmptyPortDefinition(9090, "grpc", "GRPC"),
+			data.AddWorkloadSelectorToServiceEntry(map[string]string{"app": "details-legacy", "version": "v1"},
+				data.CreateEmptyMeshInternalServiceEntry("details-grpc", "bookinfo", []string{"details-grpc.bookinfo.com"}))),
+		*data.AddPortDefinitionToServiceEntry(data.CreateEmptyPortDefinition(9080, "http", "HTTP"),
+			data.AddWorkloadSelectorToServiceEntry(map[string]string{"app": "ratings-legacy"},
+				data.CreateEmptyMeshInternalServiceEntry("ratings-svc", "travels", []string{"ratings.bookinfo.com"}))),
+	}
+}
+
+func TestWorkloadEntrySelected(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	vals, valid := ServiceEntryChecker{
+		WorkloadEntry:  *data.CreateWorkloadEntry("details-vm", "bookinfo", "2.2.2.2", map[string]string{"app": "details-legacy"}),
+		ServiceEntries: fakeServiceEntries(),
+	}.Check()
+
+	validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}.AssertNoValidations()
+}
+
+func TestWorkloadEntryNotSelected(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	// the ServiceEntry selecting these labels is in another namespace
+	vals, valid := ServiceEntryChecker{
+		WorkloadEntry:  *data.CreateWorkloadEntry("ratings-vm", "bookinfo", "2.2.2.2", map[string]string{"app": "ratings-legacy"}),
+		ServiceEntries: fakeServiceEntries(),
+	}.Check()
+
+	tb := validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}
+	tb.AssertValidationsPresent(1, true)
+	tb.AssertValidationAt(0, models.WarningSeverity, "spec/labels", "workloadentry.labels.serviceentrynotfound")
+}
+
+func fakeServices() []core_v1.Service {
+	return []core_v1.Service{
+		{
+			ObjectMeta: meta_v1.ObjectMeta{Name: "reviews", Namespace: "bookinfo"},
+			Spec:       core_v1.ServiceSpec{Selector: map[string]string{"app": "reviews"}},
+		},
+		{
+			ObjectMeta: meta_v1.ObjectMeta{Name: "ratings", Namespace: "travels"},
+			Spec:       core_v1.ServiceSpec{Selector: map[string]string{"app": "ratings"}},
+		},
+		{
+			// a Service without selector does not select any WorkloadEntry
+			ObjectMeta: meta_v1.ObjectMeta{Name: "external", Namespace: "bookinfo"},
+		},
+	}
+}
+
+func TestWorkloadEntrySelectedByService(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	vals, valid := ServiceEntryChecker{
+		WorkloadEntry:  *data.CreateWorkloadEntry("reviews-vm", "bookinfo", "2.2.2.2", map[string]string{"app": "reviews", "version": "v4"}),
+		ServiceEntries: fakeServiceEntries(),
+		Services:       fakeServices(),
+	}.Check()
+
+	validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}.AssertNoValidations()
+}
+
+func TestWorkloadEntryNotSelectedByService(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	// the Service selecting these labels is in another namespace
+	vals, valid := ServiceEntryChecker{
+		WorkloadEntry:  *data.CreateWorkloadEntry("ratings-vm", "bookinfo", "2.2.2.2", map[string]string{"app": "ratings"}),
+		ServiceEntries: fakeServiceEntries(),
+		Services:       fakeServices(),
+	}.Check()
+
+	tb := validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}
+	tb.AssertValidationsPresent(1, true)
+	tb.AssertValidationAt(0, models.WarningSeverity, "spec/labels", "workloadentry.labels.serviceentrynotfound")
+}
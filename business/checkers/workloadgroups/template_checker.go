@@ -0,0 +1,60 @@
+package workloadgroups
+
+import (
+	"fmt"
+	"sort"
+
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kiali/kiali/models"
+)
+
+type TemplateChecker struct {
+	WorkloadGroup   networking_v1alpha3.WorkloadGroup
+	WorkloadEntries []networking_v1alpha3.WorkloadEntry
+}
+
+// Check validates that the template of the WorkloadGroup agrees with the existing WorkloadEntries having the
+// labels of the group: the entries registered from the template would otherwise differ from them in the
+// service account, the network or the port numbers.
+func (t TemplateChecker) Check() ([]*models.IstioCheck, bool) {
+	checks := make([]*models.IstioCheck, 0)
+
+	template := t.WorkloadGroup.Spec.Template
+	if template == nil || t.WorkloadGroup.Spec.Metadata == nil || len(t.WorkloadGroup.Spec.Metadata.Labels) == 0 {
+		return checks, true
+	}
+	selector := labels.SelectorFromSet(t.WorkloadGroup.Spec.Metadata.Labels)
+
+	conflicts := map[string]bool{}
+	for _, we := range t.WorkloadEntries {
+		if we.Namespace != t.WorkloadGroup.Namespace || !selector.Matches(labels.Set(we.Spec.Labels)) {
+			continue
+		}
+		if template.ServiceAccount != "" && we.Spec.ServiceAccount != "" && template.ServiceAccount != we.Spec.ServiceAccount {
+			conflicts["spec/template/serviceAccount"] = true
+		}
+		if template.Network != "" && we.Spec.Network != "" && template.Network != we.Spec.Network {
+			conflicts["spec/template/network"] = true
+		}
+		for name, number := range template.Ports {
+			if weNumber, ok := we.Spec.Ports[name]; ok && weNumber != number {
+				conflicts[fmt.Sprintf("spec/template/ports/%s", name)] = true
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(conflicts))
+	for path := range conflicts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		check := models.Build("workloadgroup.template.entryconflict", path)
+		checks = append(checks, &check)
+	}
+
+	return checks, true
+}
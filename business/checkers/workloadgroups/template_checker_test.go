@@ -0,0 +1,59 @@
+package workloadgroups
+
+import (
+	"testing"
+
+	api_networking_v1alpha3 "istio.io/api/networking/v1alpha3"
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/tests/data"
+	"github.com/kiali/kiali/tests/testutils/validations"
+)
+
+func fakeWorkloadEntries() []networking_v1alpha3.WorkloadEntry {
+	we := data.AddPortToWorkloadEntry("http", 8080,
+		data.CreateWorkloadEntry("details-vm", "bookinfo", "2.2.2.2", map[string]string{"app": "details-legacy", "version": "v1"}))
+	we.Spec.ServiceAccount = "details-legacy"
+	return []networking_v1alpha3.WorkloadEntry{*we}
+}
+
+func TestWorkloadGroupTemplateAgrees(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	vals, valid := TemplateChecker{
+		WorkloadGroup: *data.CreateWorkloadGroup("details-legacy", "bookinfo", map[string]string{"app": "details-legacy"},
+			&api_networking_v1alpha3.WorkloadEntry{ServiceAccount: "details-legacy", Ports: map[string]uint32{"http": 8080, "grpc": 8090}}),
+		WorkloadEntries: fakeWorkloadEntries(),
+	}.Check()
+
+	validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}.AssertNoValidations()
+}
+
+func TestWorkloadGroupTemplateConflicts(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	vals, valid := TemplateChecker{
+		WorkloadGroup: *data.CreateWorkloadGroup("details-legacy", "bookinfo", map[string]string{"app": "details-legacy"},
+			&api_networking_v1alpha3.WorkloadEntry{ServiceAccount: "default", Ports: map[string]uint32{"http": 9080}}),
+		WorkloadEntries: fakeWorkloadEntries(),
+	}.Check()
+
+	tb := validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}
+	tb.AssertValidationsPresent(2, true)
+	tb.AssertValidationAt(0, models.WarningSeverity, "spec/template/ports/http", "workloadgroup.template.entryconflict")
+	tb.AssertValidationAt(1, models.WarningSeverity, "spec/template/serviceAccount", "workloadgroup.template.entryconflict")
+}
+
+func TestWorkloadGroupTemplateOtherEntries(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	vals, valid := TemplateChecker{
+		WorkloadGroup: *data.CreateWorkloadGroup("ratings-legacy", "bookinfo", map[string]string{"app": "ratings-legacy"},
+			&api_networking_v1alpha3.WorkloadEntry{ServiceAccount: "default", Ports: map[string]uint32{"http": 9080}}),
+		WorkloadEntries: fakeWorkloadEntries(),
+	}.Check()
+
+	validations.IstioCheckTestAsserter{T: t, Validations: vals, Valid: valid}.AssertNoValidations()
+}
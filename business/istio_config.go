@@ -7,6 +7,7 @@ import (
 	"strings"
 	"sync"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	security_v1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
 	api_errors "k8s.io/apimachinery/pkg/api/errors"
@@ -646,6 +647,114 @@ func (in *IstioConfigService) CreateIstioConfigDetail(namespace, resourceType st
 	return istioConfigDetail, err
 }
 
+// GetProposedIstioConfigDetail returns the Istio object that CreateIstioConfigDetail would create, without creating it
+func (in *IstioConfigService) GetProposedIstioConfigDetail(namespace, resourceType string, body []byte) (models.IstioConfigDetails, error) {
+	istioConfigDetail := models.IstioConfigDetails{}
+	istioConfigDetail.Namespace = models.Namespace{Name: namespace}
+	istioConfigDetail.ObjectType = resourceType
+
+	var object meta_v1.Object
+	switch resourceType {
+	case kubernetes.DestinationRules:
+		istioConfigDetail.DestinationRule = &networking_v1alpha3.DestinationRule{}
+		object = istioConfigDetail.DestinationRule
+	case kubernetes.EnvoyFilters:
+		istioConfigDetail.EnvoyFilter = &networking_v1alpha3.EnvoyFilter{}
+		object = istioConfigDetail.EnvoyFilter
+	case kubernetes.Gateways:
+		istioConfigDetail.Gateway = &networking_v1alpha3.Gateway{}
+		object = istioConfigDetail.Gateway
+	case kubernetes.ServiceEntries:
+		istioConfigDetail.ServiceEntry = &networking_v1alpha3.ServiceEntry{}
+		object = istioConfigDetail.ServiceEntry
+	case kubernetes.Sidecars:
+		istioConfigDetail.Sidecar = &networking_v1alpha3.Sidecar{}
+		object = istioConfigDetail.Sidecar
+	case kubernetes.VirtualServices:
+		istioConfigDetail.VirtualService = &networking_v1alpha3.VirtualService{}
+		object = istioConfigDetail.VirtualService
+	case kubernetes.WorkloadEntries:
+		istioConfigDetail.WorkloadEntry = &networking_v1alpha3.WorkloadEntry{}
+		object = istioConfigDetail.WorkloadEntry
+	case kubernetes.WorkloadGroups:
+		istioConfigDetail.WorkloadGroup = &networking_v1alpha3.WorkloadGroup{}
+		object = istioConfigDetail.WorkloadGroup
+	case kubernetes.AuthorizationPolicies:
+		istioConfigDetail.AuthorizationPolicy = &security_v1beta1.AuthorizationPolicy{}
+		object = istioConfigDetail.AuthorizationPolicy
+	case kubernetes.PeerAuthentications:
+		istioConfigDetail.PeerAuthentication = &security_v1beta1.PeerAuthentication{}
+		object = istioConfigDetail.PeerAuthentication
+	case kubernetes.RequestAuthentications:
+		istioConfigDetail.RequestAuthentication = &security_v1beta1.RequestAuthentication{}
+		object = istioConfigDetail.RequestAuthentication
+	default:
+		return istioConfigDetail, fmt.Errorf("object type not found: %v", resourceType)
+	}
+
+	if err := json.Unmarshal(body, object); err != nil {
+		return istioConfigDetail, api_errors.NewBadRequest(err.Error())
+	}
+	if object.GetName() == "" {
+		return istioConfigDetail, api_errors.NewBadRequest("object name is required")
+	}
+	// The object is created in the namespace of the request
+	object.SetNamespace(namespace)
+
+	return istioConfigDetail, nil
+}
+
+// GetPatchedIstioConfigDetail returns the Istio object as UpdateIstioConfigDetail would update it with the
+// Json Merge Patch, without updating it
+func (in *IstioConfigService) GetPatchedIstioConfigDetail(namespace, resourceType, name, jsonPatch string) (models.IstioConfigDetails, error) {
+	current, err := in.GetIstioConfigDetails(namespace, resourceType, name)
+	if err != nil {
+		return current, err
+	}
+
+	var object interface{}
+	switch resourceType {
+	case kubernetes.DestinationRules:
+		object = current.DestinationRule
+	case kubernetes.EnvoyFilters:
+		object = current.EnvoyFilter
+	case kubernetes.Gateways:
+		object = current.Gateway
+	case kubernetes.ServiceEntries:
+		object = current.ServiceEntry
+	case kubernetes.Sidecars:
+		object = current.Sidecar
+	case kubernetes.VirtualServices:
+		object = current.VirtualService
+	case kubernetes.WorkloadEntries:
+		object = current.WorkloadEntry
+	case kubernetes.WorkloadGroups:
+		object = current.WorkloadGroup
+	case kubernetes.AuthorizationPolicies:
+		object = current.AuthorizationPolicy
+	case kubernetes.PeerAuthentications:
+		object = current.PeerAuthentication
+	case kubernetes.RequestAuthentications:
+		object = current.RequestAuthentication
+	}
+
+	original, err := json.Marshal(object)
+	if err != nil {
+		return current, err
+	}
+	patched, err := jsonpatch.MergePatch(original, []byte(jsonPatch))
+	if err != nil {
+		return current, api_errors.NewBadRequest(err.Error())
+	}
+
+	proposed, err := in.GetProposedIstioConfigDetail(namespace, resourceType, patched)
+	if err != nil {
+		return proposed, err
+	}
+	proposed.Permissions = current.Permissions
+	return proposed, nil
+}
+
 func (in *IstioConfigService) GetIstioConfigPermissions(namespaces []string) models.IstioConfigPermissions {
 	istioConfigPermissions := make(models.IstioConfigPermissions, len(namespaces))
 
@@ -824,3 +933,21 @@ func ParseIstioConfigCriteria(namespace, objects, labelSelector, workloadSelecto
 	}
 	return criteria
 }
+
+// GetK8sGateways returns the Kubernetes Gateway API Gateways of the namespace, or none if the Gateway API
+// is not enabled in the Kiali configuration or not installed in the cluster.
+func (in *IstioConfigService) GetK8sGateways(namespace string) ([]kubernetes.K8sGateway, error) {
+	if !config.Get().Extensions.GatewayAPI.Enabled || !in.k8s.IsGatewayAPI() {
+		return []kubernetes.K8sGateway{}, nil
+	}
+	return in.k8s.GetK8sGateways(namespace)
+}
+
+// GetK8sHTTPRoutes returns the Kubernetes Gateway API HTTPRoutes of the namespace, or none if the Gateway API
+// is not enabled in the Kiali configuration or not installed in the cluster.
+func (in *IstioConfigService) GetK8sHTTPRoutes(namespace string) ([]kubernetes.K8sHTTPRoute, error) {
+	if !config.Get().Extensions.GatewayAPI.Enabled || !in.k8s.IsGatewayAPI() {
+		return []kubernetes.K8sHTTPRoute{}, nil
+	}
+	return in.k8s.GetK8sHTTPRoutes(namespace)
+}
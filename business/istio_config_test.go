@@ -1,6 +1,7 @@
 package business
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -11,6 +12,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	auth_v1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/gogo/protobuf/types"
 	"github.com/kiali/kiali/config"
@@ -498,6 +501,53 @@ func TestCreateIstioConfigDetails(t *testing.T) {
 	assert.Nil(err)
 }
 
+func TestGetProposedIstioConfigDetail(t *testing.T) {
+	assert := assert.New(t)
+	configService := mockCreateIstioConfigDetails()
+
+	proposed, err := configService.GetProposedIstioConfigDetail("test", "virtualservices", []byte(`{"metadata":{"name":"reviews","namespace":"other"},"spec":{"hosts":["reviews"]}}`))
+	assert.Nil(err)
+	assert.Equal("test", proposed.Namespace.Name)
+	assert.Equal("reviews", proposed.VirtualService.Name)
+	assert.Equal("test", proposed.VirtualService.Namespace)
+	assert.Equal([]string{"reviews"}, proposed.VirtualService.Spec.Hosts)
+
+	// nothing is created
+	vsl, err := configService.k8s.Istio().NetworkingV1alpha3().VirtualServices("test").List(context.TODO(), meta_v1.ListOptions{})
+	assert.Nil(err)
+	assert.Empty(vsl.Items)
+
+	_, err = configService.GetProposedIstioConfigDetail("test", "virtualservices", []byte("{}"))
+	assert.True(errors.IsBadRequest(err))
+	_, err = configService.GetProposedIstioConfigDetail("test", "virtualservices", []byte("{"))
+	assert.True(errors.IsBadRequest(err))
+	_, err = configService.GetProposedIstioConfigDetail("test", "unknowns", []byte("{}"))
+	assert.Error(err)
+}
+
+func TestGetPatchedIstioConfigDetail(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	config.Set(conf)
+
+	configService := mockGetIstioConfigDetails()
+
+	patched, err := configService.GetPatchedIstioConfigDetail("test", "virtualservices", "reviews", `{"spec":{"hosts":["reviews.test.svc.cluster.local"]}}`)
+	assert.Nil(err)
+	assert.Equal("reviews", patched.VirtualService.Name)
+	assert.Equal([]string{"reviews.test.svc.cluster.local"}, patched.VirtualService.Spec.Hosts)
+	assert.Len(patched.VirtualService.Spec.Http, 1)
+	assert.True(patched.Permissions.Update)
+
+	// nothing is updated
+	current, err := configService.GetIstioConfigDetails("test", "virtualservices", "reviews")
+	assert.Nil(err)
+	assert.Equal([]string{"reviews"}, current.VirtualService.Spec.Hosts)
+
+	_, err = configService.GetPatchedIstioConfigDetail("test", "virtualservices", "reviews", "{")
+	assert.True(errors.IsBadRequest(err))
+}
+
 func TestFilterIstioObjectsForWorkloadSelector(t *testing.T) {
 	assert := assert.New(t)
 
@@ -5,9 +5,11 @@ import (
 	"sync"
 
 	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	security_v1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
 	apps_v1 "k8s.io/api/apps/v1"
 	core_v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/kiali/kiali/business/checkers"
 	"github.com/kiali/kiali/config"
@@ -30,6 +32,104 @@ type ObjectChecker interface {
 // all the enabled checkers. If service is "" then the whole namespace is validated.
 // If service is not empty string, then all of its associated Istio objects are validated.
 func (in *IstioValidationsService) GetValidations(namespace, service string) (models.IstioValidations, error) {
+	return in.getValidations(namespace, service, nil)
+}
+
+// GetProposedValidations returns the validations of the proposed object's namespace as they would be with the
+// proposed object, created or replacing the object of the same name. The object is merged in memory into the
+// namespace's Istio config, the cluster is not changed. The validations of the whole namespace are returned, as
+// the proposed object may change the validations of the objects referring to it or referred by it. The namespaces
+// the object is exported to, or was exported to before, are validated as well.
+func (in *IstioValidationsService) GetProposedValidations(proposed models.IstioConfigDetails) (models.IstioValidations, error) {
+	validations, err := in.getValidations(proposed.Namespace.Name, "", &proposed)
+	if err != nil {
+		return nil, err
+	}
+
+	affectedNamespaces, err := in.getExportedNamespaces(proposed)
+	if err != nil {
+		return nil, err
+	}
+	for _, namespace := range affectedNamespaces {
+		nsValidations, err := in.getValidations(namespace, "", &proposed)
+		if err != nil {
+			return nil, err
+		}
+		validations = validations.MergeValidations(nsValidations)
+	}
+
+	return validations, nil
+}
+
+// getExportedNamespaces returns the namespaces, other than its own, the proposed object is exported to, or the
+// existing object of the same name is exported to. Only DestinationRules, ServiceEntries and VirtualServices are
+// exported.
+func (in *IstioValidationsService) getExportedNamespaces(proposed models.IstioConfigDetails) ([]string, error) {
+	criteria := IstioConfigCriteria{Namespace: proposed.Namespace.Name}
+	switch proposed.ObjectType {
+	case kubernetes.DestinationRules:
+		criteria.IncludeDestinationRules = true
+	case kubernetes.ServiceEntries:
+		criteria.IncludeServiceEntries = true
+	case kubernetes.VirtualServices:
+		criteria.IncludeVirtualServices = true
+	default:
+		return []string{}, nil
+	}
+
+	istioConfigList, err := in.businessLayer.IstioConfig.GetIstioConfigList(criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	// The exportTo of the proposed object and, if any, of the existing object it replaces
+	exportTos := [][]string{}
+	switch proposed.ObjectType {
+	case kubernetes.DestinationRules:
+		exportTos = append(exportTos, proposed.DestinationRule.Spec.ExportTo)
+		for _, dr := range istioConfigList.DestinationRules {
+			if dr.Name == proposed.DestinationRule.Name {
+				exportTos = append(exportTos, dr.Spec.ExportTo)
+			}
+		}
+	case kubernetes.ServiceEntries:
+		exportTos = append(exportTos, proposed.ServiceEntry.Spec.ExportTo)
+		for _, se := range istioConfigList.ServiceEntries {
+			if se.Name == proposed.ServiceEntry.Name {
+				exportTos = append(exportTos, se.Spec.ExportTo)
+			}
+		}
+	case kubernetes.VirtualServices:
+		exportTos = append(exportTos, proposed.VirtualService.Spec.ExportTo)
+		for _, vs := range istioConfigList.VirtualServices {
+			if vs.Name == proposed.VirtualService.Name {
+				exportTos = append(exportTos, vs.Spec.ExportTo)
+			}
+		}
+	}
+
+	nss, err := in.businessLayer.Namespace.GetNamespaces()
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := []string{}
+	for _, ns := range nss {
+		if ns.Name == proposed.Namespace.Name {
+			continue
+		}
+		for _, exportTo := range exportTos {
+			if isExportedTo(exportTo, ns.Name) {
+				namespaces = append(namespaces, ns.Name)
+				break
+			}
+		}
+	}
+
+	return namespaces, nil
+}
+
+func (in *IstioValidationsService) getValidations(namespace, service string, proposed *models.IstioConfigDetails) (models.IstioValidations, error) {
 	// Check if user has access to the namespace (RBAC) in cache scenarios and/or
 	// if namespace is accessible from Kiali (Deployment.AccessibleNamespaces)
 	if _, err := in.businessLayer.Namespace.GetNamespace(namespace); err != nil {
@@ -66,13 +166,12 @@ func (in *IstioValidationsService) GetValidations(namespace, service string) (mo
 	var deployments []apps_v1.Deployment
 	var registryStatus []*kubernetes.RegistryStatus
 
-	wg.Add(9) // We need to add these here to make sure we don't execute wg.Wait() before scheduler has started goroutines
+	wg.Add(10) // We need to add these here to make sure we don't execute wg.Wait() before scheduler has started goroutines
 
 	if service != "" {
 		// These resources are not used if no service is targeted
-		wg.Add(2)
+		wg.Add(1)
 		go in.fetchDeployments(&deployments, namespace, errChan, &wg)
-		go in.fetchPods(&pods, namespace, errChan, &wg)
 	}
 
 	// We fetch without target service as some validations will require full-namespace details
@@ -85,6 +184,7 @@ func (in *IstioValidationsService) GetValidations(namespace, service string) (mo
 	go in.fetchAuthorizationDetails(&rbacDetails, namespace, errChan, &wg)
 	go in.fetchServices(&services, namespace, errChan, &wg)
 	go in.fetchRegistryStatus(&registryStatus, errChan, &wg)
+	go in.fetchPods(&pods, namespace, errChan, &wg)
 
 	wg.Wait()
 	close(errChan)
@@ -94,14 +194,19 @@ func (in *IstioValidationsService) GetValidations(namespace, service string) (mo
 		}
 	}
 
-	objectCheckers := in.getAllObjectCheckers(namespace, istioConfigList, exportedResources, services, workloadsPerNamespace, workloadsPerNamespace[namespace], gatewaysPerNamespace, mtlsDetails, rbacDetails, namespaces, registryStatus)
+	if proposed != nil {
+		mergeProposedObject(*proposed, namespace, &istioConfigList, &exportedResources, &gatewaysPerNamespace, &mtlsDetails, &rbacDetails)
+	}
+
+	objectCheckers := in.getAllObjectCheckers(namespace, istioConfigList, exportedResources, services, workloadsPerNamespace, workloadsPerNamespace[namespace], pods, gatewaysPerNamespace, mtlsDetails, rbacDetails, namespaces, registryStatus)
 
 	if service != "" {
 		objectCheckers = append(objectCheckers, in.getServiceCheckers(namespace, services, deployments, pods)...)
 	}
 
 	// Get group validations for same kind istio objects
-	validations := runObjectCheckers(objectCheckers)
+	objects := validatedObjects(istioConfigList, exportedResources, services, gatewaysPerNamespace, mtlsDetails, rbacDetails)
+	validations := runObjectCheckers(objectCheckers, objects)
 	if service != "" {
 		validations = validations.FilterBySingleType("service", service)
 	}
@@ -115,7 +220,7 @@ func (in *IstioValidationsService) getServiceCheckers(namespace string, services
 	}
 }
 
-func (in *IstioValidationsService) getAllObjectCheckers(namespace string, istioConfigList models.IstioConfigList, exportedResources kubernetes.ExportedResources, services []core_v1.Service, workloadsPerNamespace map[string]models.WorkloadList, workloads models.WorkloadList, gatewaysPerNamespace [][]networking_v1alpha3.Gateway, mtlsDetails kubernetes.MTLSDetails, rbacDetails kubernetes.RBACDetails, namespaces []models.Namespace, registryStatus []*kubernetes.RegistryStatus) []ObjectChecker {
+func (in *IstioValidationsService) getAllObjectCheckers(namespace string, istioConfigList models.IstioConfigList, exportedResources kubernetes.ExportedResources, services []core_v1.Service, workloadsPerNamespace map[string]models.WorkloadList, workloads models.WorkloadList, pods []core_v1.Pod, gatewaysPerNamespace [][]networking_v1alpha3.Gateway, mtlsDetails kubernetes.MTLSDetails, rbacDetails kubernetes.RBACDetails, namespaces []models.Namespace, registryStatus []*kubernetes.RegistryStatus) []ObjectChecker {
 	return []ObjectChecker{
 		checkers.NoServiceChecker{Namespace: namespace, Namespaces: namespaces, IstioConfigList: istioConfigList, ExportedResources: &exportedResources, Services: services, WorkloadList: workloads, GatewaysPerNamespace: gatewaysPerNamespace, AuthorizationDetails: &rbacDetails, RegistryStatus: registryStatus},
 		checkers.VirtualServiceChecker{Namespace: namespace, Namespaces: namespaces, DestinationRules: istioConfigList.DestinationRules, VirtualServices: istioConfigList.VirtualServices, ExportedDestinationRules: exportedResources.DestinationRules, ExportedVirtualServices: exportedResources.VirtualServices},
@@ -126,6 +231,9 @@ func (in *IstioValidationsService) getAllObjectCheckers(namespace string, istioC
 		checkers.AuthorizationPolicyChecker{AuthorizationPolicies: rbacDetails.AuthorizationPolicies, Namespace: namespace, Namespaces: namespaces, Services: services, ServiceEntries: istioConfigList.ServiceEntries, ExportedServiceEntries: exportedResources.ServiceEntries, WorkloadList: workloads, MtlsDetails: mtlsDetails, VirtualServices: istioConfigList.VirtualServices, RegistryStatus: registryStatus},
 		checkers.SidecarChecker{Sidecars: istioConfigList.Sidecars, Namespaces: namespaces, WorkloadList: workloads, Services: services, ServiceEntries: istioConfigList.ServiceEntries, ExportedServiceEntries: exportedResources.ServiceEntries},
 		checkers.RequestAuthenticationChecker{RequestAuthentications: istioConfigList.RequestAuthentications, WorkloadList: workloads},
+		checkers.EnvoyFilterChecker{EnvoyFilters: istioConfigList.EnvoyFilters, RootEnvoyFilters: exportedResources.EnvoyFilters, Pods: pods, WorkloadList: workloads},
+		checkers.WorkloadEntryChecker{WorkloadEntries: istioConfigList.WorkloadEntries, ServiceEntries: istioConfigList.ServiceEntries, Services: services},
+		checkers.WorkloadGroupChecker{WorkloadGroups: istioConfigList.WorkloadGroups, WorkloadEntries: istioConfigList.WorkloadEntries},
 	}
 }
 
@@ -137,6 +245,7 @@ func (in *IstioValidationsService) GetIstioObjectValidations(namespace string, o
 	var services []core_v1.Service
 	var workloads models.WorkloadList
 	var workloadsPerNamespace map[string]models.WorkloadList
+	var pods []core_v1.Pod
 	var gatewaysPerNamespace [][]networking_v1alpha3.Gateway
 	var mtlsDetails kubernetes.MTLSDetails
 	var rbacDetails kubernetes.RBACDetails
@@ -158,7 +267,7 @@ func (in *IstioValidationsService) GetIstioObjectValidations(namespace string, o
 	errChan := make(chan error, 1)
 
 	// Get all the Istio objects from a Namespace and all gateways from every namespace
-	wg.Add(10)
+	wg.Add(11)
 	go in.fetchNamespaces(&namespaces, errChan, &wg)
 	go in.fetchIstioConfigList(&istioConfigList, namespace, errChan, &wg)
 	go in.fetchExportedResources(&exportedResources, namespace, errChan, &wg)
@@ -169,6 +278,7 @@ func (in *IstioValidationsService) GetIstioObjectValidations(namespace string, o
 	go in.fetchNonLocalmTLSConfigs(&mtlsDetails, namespace, errChan, &wg)
 	go in.fetchAuthorizationDetails(&rbacDetails, namespace, errChan, &wg)
 	go in.fetchRegistryStatus(&registryStatus, errChan, &wg)
+	go in.fetchPods(&pods, namespace, errChan, &wg)
 	wg.Wait()
 
 	noServiceChecker := checkers.NoServiceChecker{Namespace: namespace, Namespaces: namespaces, IstioConfigList: istioConfigList, ExportedResources: &exportedResources, Services: services, WorkloadList: workloads, GatewaysPerNamespace: gatewaysPerNamespace, AuthorizationDetails: &rbacDetails, RegistryStatus: registryStatus}
@@ -201,15 +311,18 @@ func (in *IstioValidationsService) GetIstioObjectValidations(namespace string, o
 		peerAuthnChecker := checkers.PeerAuthenticationChecker{PeerAuthentications: mtlsDetails.PeerAuthentications, MTLSDetails: mtlsDetails, WorkloadList: workloads}
 		objectCheckers = []ObjectChecker{peerAuthnChecker}
 	case kubernetes.WorkloadEntries:
-		// Validation on WorkloadEntries are not yet in place
+		workloadEntryChecker := checkers.WorkloadEntryChecker{WorkloadEntries: istioConfigList.WorkloadEntries, ServiceEntries: istioConfigList.ServiceEntries, Services: services}
+		objectCheckers = []ObjectChecker{workloadEntryChecker}
 	case kubernetes.WorkloadGroups:
-		// Validation on WorkloadGroups are not yet in place
+		workloadGroupChecker := checkers.WorkloadGroupChecker{WorkloadGroups: istioConfigList.WorkloadGroups, WorkloadEntries: istioConfigList.WorkloadEntries}
+		objectCheckers = []ObjectChecker{workloadGroupChecker}
 	case kubernetes.RequestAuthentications:
 		// Validation on RequestAuthentications are not yet in place
 		requestAuthnChecker := checkers.RequestAuthenticationChecker{RequestAuthentications: istioConfigList.RequestAuthentications, WorkloadList: workloads}
 		objectCheckers = []ObjectChecker{requestAuthnChecker}
 	case kubernetes.EnvoyFilters:
-		// Validation on EnvoyFilters are not yet in place
+		envoyFilterChecker := checkers.EnvoyFilterChecker{EnvoyFilters: istioConfigList.EnvoyFilters, RootEnvoyFilters: exportedResources.EnvoyFilters, Pods: pods, WorkloadList: workloads}
+		objectCheckers = []ObjectChecker{envoyFilterChecker}
 	default:
 		err = fmt.Errorf("object type not found: %v", objectType)
 	}
@@ -225,10 +338,11 @@ func (in *IstioValidationsService) GetIstioObjectValidations(namespace string, o
 		return models.IstioValidations{}, err
 	}
 
-	return runObjectCheckers(objectCheckers).FilterByKey(models.ObjectTypeSingular[objectType], object), nil
+	objects := validatedObjects(istioConfigList, exportedResources, services, gatewaysPerNamespace, mtlsDetails, rbacDetails)
+	return runObjectCheckers(objectCheckers, objects).FilterByKey(models.ObjectTypeSingular[objectType], object), nil
 }
 
-func runObjectCheckers(objectCheckers []ObjectChecker) models.IstioValidations {
+func runObjectCheckers(objectCheckers []ObjectChecker, objects map[models.IstioValidationKey]meta_v1.ObjectMeta) models.IstioValidations {
 	objectTypeValidations := models.IstioValidations{}
 
 	// Run checks for each IstioObject type
@@ -237,6 +351,7 @@ func runObjectCheckers(objectCheckers []ObjectChecker) models.IstioValidations {
 	}
 
 	objectTypeValidations.StripIgnoredChecks()
+	objectTypeValidations.ApplyValidationRules(objects)
 
 	return objectTypeValidations
 }
@@ -248,6 +363,282 @@ func runObjectChecker(objectChecker ObjectChecker) models.IstioValidations {
 	return objectChecker.Check()
 }
 
+// validatedObjects returns the labels and annotations of the objects fetched for the validations, by validation key,
+// to apply the validation rules
+func validatedObjects(istioConfigList models.IstioConfigList, exportedResources kubernetes.ExportedResources, services []core_v1.Service, gatewaysPerNamespace [][]networking_v1alpha3.Gateway, mtlsDetails kubernetes.MTLSDetails, rbacDetails kubernetes.RBACDetails) map[models.IstioValidationKey]meta_v1.ObjectMeta {
+	objects := map[models.IstioValidationKey]meta_v1.ObjectMeta{}
+	add := func(objectType string, meta meta_v1.ObjectMeta) {
+		objects[models.BuildKey(objectType, meta.Name, meta.Namespace)] = meta
+	}
+
+	for _, drs := range [][]networking_v1alpha3.DestinationRule{istioConfigList.DestinationRules, exportedResources.DestinationRules, mtlsDetails.DestinationRules} {
+		for _, dr := range drs {
+			add("destinationrule", dr.ObjectMeta)
+		}
+	}
+	for _, efs := range [][]networking_v1alpha3.EnvoyFilter{istioConfigList.EnvoyFilters, exportedResources.EnvoyFilters} {
+		for _, ef := range efs {
+			add("envoyfilter", ef.ObjectMeta)
+		}
+	}
+	for _, gws := range gatewaysPerNamespace {
+		for _, gw := range gws {
+			add("gateway", gw.ObjectMeta)
+		}
+	}
+	for _, ses := range [][]networking_v1alpha3.ServiceEntry{istioConfigList.ServiceEntries, exportedResources.ServiceEntries} {
+		for _, se := range ses {
+			add("serviceentry", se.ObjectMeta)
+		}
+	}
+	for _, sc := range istioConfigList.Sidecars {
+		add("sidecar", sc.ObjectMeta)
+	}
+	for _, vss := range [][]networking_v1alpha3.VirtualService{istioConfigList.VirtualServices, exportedResources.VirtualServices} {
+		for _, vs := range vss {
+			add("virtualservice", vs.ObjectMeta)
+		}
+	}
+	for _, we := range istioConfigList.WorkloadEntries {
+		add("workloadentry", we.ObjectMeta)
+	}
+	for _, wg := range istioConfigList.WorkloadGroups {
+		add("workloadgroup", wg.ObjectMeta)
+	}
+	for _, ap := range rbacDetails.AuthorizationPolicies {
+		add("authorizationpolicy", ap.ObjectMeta)
+	}
+	for _, pas := range [][]security_v1beta1.PeerAuthentication{mtlsDetails.PeerAuthentications, mtlsDetails.MeshPeerAuthentications} {
+		for _, pa := range pas {
+			add("peerauthentication", pa.ObjectMeta)
+		}
+	}
+	for _, ra := range istioConfigList.RequestAuthentications {
+		add("requestauthentication", ra.ObjectMeta)
+	}
+	for _, svc := range services {
+		add("service", svc.ObjectMeta)
+	}
+
+	return objects
+}
+
+// mergeProposedObject replaces the object of the same name by the proposed object, or adds it, in the objects
+// fetched for the validations of the namespace. When the namespace is not the proposed object's namespace, only
+// the objects fetched from every namespace are changed, and the proposed object is exported to the namespace
+// only when its exportTo allows it.
+func mergeProposedObject(proposed models.IstioConfigDetails, namespace string, istioConfigList *models.IstioConfigList, exportedResources *kubernetes.ExportedResources, gatewaysPerNamespace *[][]networking_v1alpha3.Gateway, mtlsDetails *kubernetes.MTLSDetails, rbacDetails *kubernetes.RBACDetails) {
+	local := proposed.Namespace.Name == namespace
+
+	switch proposed.ObjectType {
+	case kubernetes.DestinationRules:
+		dr := *proposed.DestinationRule
+		mtlsDetails.DestinationRules = append(removeDestinationRule(mtlsDetails.DestinationRules, dr), dr)
+		if local {
+			istioConfigList.DestinationRules = append(removeDestinationRule(istioConfigList.DestinationRules, dr), dr)
+		} else {
+			exportedResources.DestinationRules = removeDestinationRule(exportedResources.DestinationRules, dr)
+			if isExportedTo(dr.Spec.ExportTo, namespace) {
+				exportedResources.DestinationRules = append(exportedResources.DestinationRules, dr)
+			}
+		}
+	case kubernetes.EnvoyFilters:
+		ef := *proposed.EnvoyFilter
+		if local {
+			istioConfigList.EnvoyFilters = append(removeEnvoyFilter(istioConfigList.EnvoyFilters, ef), ef)
+		} else if ef.Namespace == config.Get().IstioNamespace {
+			exportedResources.EnvoyFilters = append(removeEnvoyFilter(exportedResources.EnvoyFilters, ef), ef)
+		}
+	case kubernetes.Gateways:
+		gw := *proposed.Gateway
+		if local {
+			istioConfigList.Gateways = append(removeGateway(istioConfigList.Gateways, gw), gw)
+		}
+		gwss := make([][]networking_v1alpha3.Gateway, 0, len(*gatewaysPerNamespace)+1)
+		for _, gws := range *gatewaysPerNamespace {
+			gwss = append(gwss, removeGateway(gws, gw))
+		}
+		*gatewaysPerNamespace = append(gwss, []networking_v1alpha3.Gateway{gw})
+	case kubernetes.ServiceEntries:
+		se := *proposed.ServiceEntry
+		if local {
+			istioConfigList.ServiceEntries = append(removeServiceEntry(istioConfigList.ServiceEntries, se), se)
+		} else {
+			exportedResources.ServiceEntries = removeServiceEntry(exportedResources.ServiceEntries, se)
+			if isExportedTo(se.Spec.ExportTo, namespace) {
+				exportedResources.ServiceEntries = append(exportedResources.ServiceEntries, se)
+			}
+		}
+	case kubernetes.Sidecars:
+		sc := *proposed.Sidecar
+		if local {
+			istioConfigList.Sidecars = append(removeSidecar(istioConfigList.Sidecars, sc), sc)
+		}
+	case kubernetes.VirtualServices:
+		vs := *proposed.VirtualService
+		if local {
+			istioConfigList.VirtualServices = append(removeVirtualService(istioConfigList.VirtualServices, vs), vs)
+		} else {
+			exportedResources.VirtualServices = removeVirtualService(exportedResources.VirtualServices, vs)
+			if isExportedTo(vs.Spec.ExportTo, namespace) {
+				exportedResources.VirtualServices = append(exportedResources.VirtualServices, vs)
+			}
+		}
+	case kubernetes.WorkloadEntries:
+		we := *proposed.WorkloadEntry
+		if local {
+			istioConfigList.WorkloadEntries = append(removeWorkloadEntry(istioConfigList.WorkloadEntries, we), we)
+		}
+	case kubernetes.WorkloadGroups:
+		wg := *proposed.WorkloadGroup
+		if local {
+			istioConfigList.WorkloadGroups = append(removeWorkloadGroup(istioConfigList.WorkloadGroups, wg), wg)
+		}
+	case kubernetes.AuthorizationPolicies:
+		ap := *proposed.AuthorizationPolicy
+		if local {
+			rbacDetails.AuthorizationPolicies = append(removeAuthorizationPolicy(rbacDetails.AuthorizationPolicies, ap), ap)
+		}
+	case kubernetes.PeerAuthentications:
+		pa := *proposed.PeerAuthentication
+		if local {
+			mtlsDetails.PeerAuthentications = append(removePeerAuthentication(mtlsDetails.PeerAuthentications, pa), pa)
+		}
+		if pa.Namespace == config.Get().IstioNamespace {
+			mtlsDetails.MeshPeerAuthentications = append(removePeerAuthentication(mtlsDetails.MeshPeerAuthentications, pa), pa)
+		}
+	case kubernetes.RequestAuthentications:
+		ra := *proposed.RequestAuthentication
+		if local {
+			istioConfigList.RequestAuthentications = append(removeRequestAuthentication(istioConfigList.RequestAuthentications, ra), ra)
+		}
+	}
+}
+
+// isExportedTo returns true when an object with the given exportTo is visible from the other namespace. An object
+// without exportTo is exported to all namespaces.
+func isExportedTo(exportTo []string, namespace string) bool {
+	if len(exportTo) == 0 {
+		return true
+	}
+	for _, exportToNs := range exportTo {
+		if exportToNs == "*" || exportToNs == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+func removeDestinationRule(drs []networking_v1alpha3.DestinationRule, removed networking_v1alpha3.DestinationRule) []networking_v1alpha3.DestinationRule {
+	result := []networking_v1alpha3.DestinationRule{}
+	for _, dr := range drs {
+		if dr.Name != removed.Name || dr.Namespace != removed.Namespace {
+			result = append(result, dr)
+		}
+	}
+	return result
+}
+
+func removeEnvoyFilter(efs []networking_v1alpha3.EnvoyFilter, removed networking_v1alpha3.EnvoyFilter) []networking_v1alpha3.EnvoyFilter {
+	result := []networking_v1alpha3.EnvoyFilter{}
+	for _, ef := range efs {
+		if ef.Name != removed.Name || ef.Namespace != removed.Namespace {
+			result = append(result, ef)
+		}
+	}
+	return result
+}
+
+func removeGateway(gws []networking_v1alpha3.Gateway, removed networking_v1alpha3.Gateway) []networking_v1alpha3.Gateway {
+	result := []networking_v1alpha3.Gateway{}
+	for _, gw := range gws {
+		if gw.Name != removed.Name || gw.Namespace != removed.Namespace {
+			result = append(result, gw)
+		}
+	}
+	return result
+}
+
+func removeServiceEntry(ses []networking_v1alpha3.ServiceEntry, removed networking_v1alpha3.ServiceEntry) []networking_v1alpha3.ServiceEntry {
+	result := []networking_v1alpha3.ServiceEntry{}
+	for _, se := range ses {
+		if se.Name != removed.Name || se.Namespace != removed.Namespace {
+			result = append(result, se)
+		}
+	}
+	return result
+}
+
+func removeSidecar(scs []networking_v1alpha3.Sidecar, removed networking_v1alpha3.Sidecar) []networking_v1alpha3.Sidecar {
+	result := []networking_v1alpha3.Sidecar{}
+	for _, sc := range scs {
+		if sc.Name != removed.Name || sc.Namespace != removed.Namespace {
+			result = append(result, sc)
+		}
+	}
+	return result
+}
+
+func removeVirtualService(vss []networking_v1alpha3.VirtualService, removed networking_v1alpha3.VirtualService) []networking_v1alpha3.VirtualService {
+	result := []networking_v1alpha3.VirtualService{}
+	for _, vs := range vss {
+		if vs.Name != removed.Name || vs.Namespace != removed.Namespace {
+			result = append(result, vs)
+		}
+	}
+	return result
+}
+
+func removeWorkloadEntry(wes []networking_v1alpha3.WorkloadEntry, removed networking_v1alpha3.WorkloadEntry) []networking_v1alpha3.WorkloadEntry {
+	result := []networking_v1alpha3.WorkloadEntry{}
+	for _, we := range wes {
+		if we.Name != removed.Name || we.Namespace != removed.Namespace {
+			result = append(result, we)
+		}
+	}
+	return result
+}
+
+func removeWorkloadGroup(wgs []networking_v1alpha3.WorkloadGroup, removed networking_v1alpha3.WorkloadGroup) []networking_v1alpha3.WorkloadGroup {
+	result := []networking_v1alpha3.WorkloadGroup{}
+	for _, wg := range wgs {
+		if wg.Name != removed.Name || wg.Namespace != removed.Namespace {
+			result = append(result, wg)
+		}
+	}
+	return result
+}
+
+func removeAuthorizationPolicy(aps []security_v1beta1.AuthorizationPolicy, removed security_v1beta1.AuthorizationPolicy) []security_v1beta1.AuthorizationPolicy {
+	result := []security_v1beta1.AuthorizationPolicy{}
+	for _, ap := range aps {
+		if ap.Name != removed.Name || ap.Namespace != removed.Namespace {
+			result = append(result, ap)
+		}
+	}
+	return result
+}
+
+func removePeerAuthentication(pas []security_v1beta1.PeerAuthentication, removed security_v1beta1.PeerAuthentication) []security_v1beta1.PeerAuthentication {
+	result := []security_v1beta1.PeerAuthentication{}
+	for _, pa := range pas {
+		if pa.Name != removed.Name || pa.Namespace != removed.Namespace {
+			result = append(result, pa)
+		}
+	}
+	return result
+}
+
+func removeRequestAuthentication(ras []security_v1beta1.RequestAuthentication, removed security_v1beta1.RequestAuthentication) []security_v1beta1.RequestAuthentication {
+	result := []security_v1beta1.RequestAuthentication{}
+	for _, ra := range ras {
+		if ra.Name != removed.Name || ra.Namespace != removed.Namespace {
+			result = append(result, ra)
+		}
+	}
+	return result
+}
+
 // The following idea is used underneath: if errChan has at least one record, we'll effectively cancel the request (if scheduled in such order). On the other hand, if we can't
 // write to the buffered errChan, we just ignore the error as select does not block even if channel is full. This is because a single error is enough to cancel the whole request.
 
@@ -415,10 +806,13 @@ func (in *IstioValidationsService) fetchIstioConfigList(rValue *models.IstioConf
 		criteria := IstioConfigCriteria{
 			Namespace:                     namespace,
 			IncludeDestinationRules:       true,
+			IncludeEnvoyFilters:           true,
 			IncludeGateways:               true,
 			IncludeServiceEntries:         true,
 			IncludeSidecars:               true,
 			IncludeVirtualServices:        true,
+			IncludeWorkloadEntries:        true,
+			IncludeWorkloadGroups:         true,
 			IncludeRequestAuthentications: true,
 		}
 		istioConfigList, err := in.businessLayer.IstioConfig.GetIstioConfigList(criteria)
@@ -453,12 +847,15 @@ func (in *IstioValidationsService) fetchExportedResources(exportedResources *kub
 			IncludeDestinationRules: true,
 			IncludeServiceEntries:   true,
 			IncludeVirtualServices:  true,
+			// the EnvoyFilters of the root namespace apply to every namespace
+			IncludeEnvoyFilters: ns.Name == config.Get().IstioNamespace,
 		}
 		istioConfigList, err := in.businessLayer.IstioConfig.GetIstioConfigList(criteria)
 		if err != nil {
 			errChan <- err
 			return
 		}
+		exportedResources.EnvoyFilters = append(exportedResources.EnvoyFilters, istioConfigList.EnvoyFilters...)
 		// Filter VS
 		filteredVSs := in.filterVSExportToNamespaces(namespace, istioConfigList.VirtualServices)
 		exportedResources.VirtualServices = append(exportedResources.VirtualServices, filteredVSs...)
@@ -476,15 +873,8 @@ func (in *IstioValidationsService) fetchExportedResources(exportedResources *kub
 func (in *IstioValidationsService) filterVSExportToNamespaces(namespace string, vs []networking_v1alpha3.VirtualService) []networking_v1alpha3.VirtualService {
 	var result []networking_v1alpha3.VirtualService
 	for _, v := range vs {
-		if len(v.Spec.ExportTo) > 0 {
-			for _, exportToNs := range v.Spec.ExportTo {
-				// take only namespaces where it is exported to, or if it is exported to all namespaces
-				if exportToNs == "*" || exportToNs == namespace {
-					result = append(result, v)
-				}
-			}
-		} else {
-			// no exportTo field, means object exported to all namespaces
+		// take only the objects exported to the namespace, or to all namespaces
+		if isExportedTo(v.Spec.ExportTo, namespace) {
 			result = append(result, v)
 		}
 	}
@@ -494,15 +884,8 @@ func (in *IstioValidationsService) filterVSExportToNamespaces(namespace string,
 func (in *IstioValidationsService) filterDRExportToNamespaces(namespace string, dr []networking_v1alpha3.DestinationRule) []networking_v1alpha3.DestinationRule {
 	var result []networking_v1alpha3.DestinationRule
 	for _, d := range dr {
-		if len(d.Spec.ExportTo) > 0 {
-			for _, exportToNs := range d.Spec.ExportTo {
-				// take only namespaces where it is exported to, or if it is exported to all namespaces
-				if exportToNs == "*" || exportToNs == namespace {
-					result = append(result, d)
-				}
-			}
-		} else {
-			// no exportTo field, means object exported to all namespaces
+		// take only the objects exported to the namespace, or to all namespaces
+		if isExportedTo(d.Spec.ExportTo, namespace) {
 			result = append(result, d)
 		}
 	}
@@ -512,15 +895,8 @@ func (in *IstioValidationsService) filterDRExportToNamespaces(namespace string,
 func (in *IstioValidationsService) filterSEExportToNamespaces(namespace string, se []networking_v1alpha3.ServiceEntry) []networking_v1alpha3.ServiceEntry {
 	var result []networking_v1alpha3.ServiceEntry
 	for _, s := range se {
-		if len(s.Spec.ExportTo) > 0 {
-			for _, exportToNs := range s.Spec.ExportTo {
-				// take only namespaces where it is exported to, or if it is exported to all namespaces
-				if exportToNs == "*" || exportToNs == namespace {
-					result = append(result, s)
-				}
-			}
-		} else {
-			// no exportTo field, means object exported to all namespaces
+		// take only the objects exported to the namespace, or to all namespaces
+		if isExportedTo(s.Spec.ExportTo, namespace) {
 			result = append(result, s)
 		}
 	}
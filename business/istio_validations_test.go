@@ -48,6 +48,159 @@ func TestGetIstioObjectValidations(t *testing.T) {
 	assert.NotEmpty(validations)
 }
 
+func TestGetProposedValidations(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	config.Set(conf)
+
+	vs := mockCombinedValidationService(fakeCombinedIstioConfigList(), []string{"details", "product", "customer"}, fakePods())
+
+	// Dropping the v1 subset breaks the VirtualService routing to it
+	proposed := models.IstioConfigDetails{
+		Namespace:       models.Namespace{Name: "test"},
+		ObjectType:      "destinationrules",
+		DestinationRule: data.CreateEmptyDestinationRule("test", "product-dr", "product"),
+	}
+	validations, err := vs.GetProposedValidations(proposed)
+	assert.NoError(err)
+
+	vsValidation, ok := validations[models.IstioValidationKey{ObjectType: "virtualservice", Namespace: "test", Name: "product-vs"}]
+	assert.True(ok)
+	assert.True(hasCheckCode(vsValidation, "KIA1107"))
+
+	// The proposed object replaces the existing one
+	_, ok = validations[models.IstioValidationKey{ObjectType: "destinationrule", Namespace: "test", Name: "product-dr"}]
+	assert.True(ok)
+
+	// The cluster objects are still valid
+	validations, err = vs.GetValidations("test", "")
+	assert.NoError(err)
+	assert.False(hasCheckCode(validations[models.IstioValidationKey{ObjectType: "virtualservice", Namespace: "test", Name: "product-vs"}], "KIA1107"))
+}
+
+func TestGetProposedValidationsExported(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	config.Set(conf)
+
+	vs := mockCombinedValidationService(fakeCombinedIstioConfigList(), []string{"details", "product", "customer"}, fakePods())
+
+	// A DestinationRule of another namespace, exported to all namespaces, for the same host and subset as product-dr
+	dr := data.AddSubsetToDestinationRule(data.CreateSubset("v1", "v1"), data.CreateEmptyDestinationRule("test2", "product-dr2", "product.test.svc.cluster.local"))
+	proposed := models.IstioConfigDetails{
+		Namespace:       models.Namespace{Name: "test2"},
+		ObjectType:      "destinationrules",
+		DestinationRule: dr,
+	}
+	validations, err := vs.GetProposedValidations(proposed)
+	assert.NoError(err)
+
+	// The namespace the DestinationRule is exported to is validated as well
+	drValidation, ok := validations[models.IstioValidationKey{ObjectType: "destinationrule", Namespace: "test", Name: "product-dr"}]
+	assert.True(ok)
+	assert.True(hasCheckCode(drValidation, "KIA0201"))
+	_, ok = validations[models.IstioValidationKey{ObjectType: "virtualservice", Namespace: "test", Name: "product-vs"}]
+	assert.True(ok)
+
+	// Not exported, the other namespace is not validated
+	dr.Spec.ExportTo = []string{"."}
+	validations, err = vs.GetProposedValidations(proposed)
+	assert.NoError(err)
+	_, ok = validations[models.IstioValidationKey{ObjectType: "virtualservice", Namespace: "test", Name: "product-vs"}]
+	assert.False(ok)
+}
+
+func hasCheckCode(validation *models.IstioValidation, code string) bool {
+	for _, check := range validation.Checks {
+		if check.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGetEnvoyFilterValidations(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	config.Set(conf)
+
+	istioConfigList := fakeCombinedIstioConfigList()
+	istioConfigList.EnvoyFilters = []networking_v1alpha3.EnvoyFilter{
+		*data.AddSelectorToEnvoyFilter(map[string]string{"app": "unknown"}, data.CreateEnvoyFilter("unknown-filter", "test")),
+	}
+	vs := mockCombinedValidationService(istioConfigList, []string{"details", "product", "customer"}, fakePods())
+
+	validations, err := vs.GetIstioObjectValidations("test", "envoyfilters", "unknown-filter")
+	assert.NoError(err)
+	validation, ok := validations[models.IstioValidationKey{ObjectType: "envoyfilter", Namespace: "test", Name: "unknown-filter"}]
+	assert.True(ok)
+	assert.True(hasCheckCode(validation, "KIA0004"))
+
+	validations, err = vs.GetValidations("test", "")
+	assert.NoError(err)
+	assert.True(hasCheckCode(validations[models.IstioValidationKey{ObjectType: "envoyfilter", Namespace: "test", Name: "unknown-filter"}], "KIA0004"))
+}
+
+func TestGetWorkloadEntryValidations(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	config.Set(conf)
+
+	istioConfigList := fakeCombinedIstioConfigList()
+	istioConfigList.WorkloadEntries = []networking_v1alpha3.WorkloadEntry{
+		*data.CreateWorkloadEntry("details-vm", "test", "2.2.2.2", map[string]string{"app": "details-legacy"}),
+		*data.CreateWorkloadEntry("ratings-vm", "test", "2.2.2.2", map[string]string{"app": "ratings-legacy"}),
+	}
+	istioConfigList.WorkloadGroups = []networking_v1alpha3.WorkloadGroup{
+		*data.CreateWorkloadGroup("details-legacy", "test", map[string]string{"app": "details-legacy"}, nil),
+	}
+	vs := mockCombinedValidationService(istioConfigList, []string{"details", "product", "customer"}, fakePods())
+
+	validations, err := vs.GetIstioObjectValidations("test", "workloadentries", "details-vm")
+	assert.NoError(err)
+	assert.Len(validations, 1)
+	validation, ok := validations[models.IstioValidationKey{ObjectType: "workloadentry", Namespace: "test", Name: "details-vm"}]
+	assert.True(ok)
+	assert.False(validation.Valid)
+	assert.True(hasCheckCode(validation, "KIA1301"))
+	assert.True(hasCheckCode(validation, "KIA1302"))
+
+	validations, err = vs.GetValidations("test", "")
+	assert.NoError(err)
+	assert.True(hasCheckCode(validations[models.IstioValidationKey{ObjectType: "workloadentry", Namespace: "test", Name: "ratings-vm"}], "KIA1302"))
+	assert.True(validations[models.IstioValidationKey{ObjectType: "workloadgroup", Namespace: "test", Name: "details-legacy"}].Valid)
+}
+
+func TestGetValidationsWithRules(t *testing.T) {
+	assert := assert.New(t)
+
+	// the fake workloads reset the config
+	vs := mockCombinedValidationService(fakeCombinedIstioConfigList(), []string{"details", "product", "customer"}, fakePods())
+
+	conf := config.NewConfig()
+	conf.KialiFeatureFlags.Validations.Rules = []config.ValidationRule{
+		{Code: "KIA1104", Severity: "error", Namespaces: []string{"test"}},
+	}
+	config.Set(conf)
+
+	validations, err := vs.GetValidations("test", "")
+	assert.NoError(err)
+	validation := validations[models.IstioValidationKey{ObjectType: "virtualservice", Namespace: "test", Name: "product-vs"}]
+	assert.True(hasCheckCode(validation, "KIA1104"))
+	assert.False(validation.Valid)
+
+	conf.KialiFeatureFlags.Validations.Rules = []config.ValidationRule{
+		{Code: "KIA1104", Ignore: true, Namespaces: []string{"test"}},
+	}
+	config.Set(conf)
+
+	validations, err = vs.GetIstioObjectValidations("test", "virtualservices", "product-vs")
+	assert.NoError(err)
+	validation = validations[models.IstioValidationKey{ObjectType: "virtualservice", Namespace: "test", Name: "product-vs"}]
+	assert.False(hasCheckCode(validation, "KIA1104"))
+	assert.True(validation.Valid)
+}
+
 func TestGatewayValidation(t *testing.T) {
 	assert := assert.New(t)
 	conf := config.NewConfig()
@@ -160,6 +313,15 @@ func mockCombinedValidationService(istioConfigList *models.IstioConfigList, serv
 	for _, r := range istioConfigList.RequestAuthentications {
 		fakeIstioObjects = append(fakeIstioObjects, r.DeepCopyObject())
 	}
+	for _, e := range istioConfigList.EnvoyFilters {
+		fakeIstioObjects = append(fakeIstioObjects, e.DeepCopyObject())
+	}
+	for _, w := range istioConfigList.WorkloadEntries {
+		fakeIstioObjects = append(fakeIstioObjects, w.DeepCopyObject())
+	}
+	for _, w := range istioConfigList.WorkloadGroups {
+		fakeIstioObjects = append(fakeIstioObjects, w.DeepCopyObject())
+	}
 	for _, v := range fakeCombinedIstioConfigList().VirtualServices {
 		fakeIstioObjects = append(fakeIstioObjects, v.DeepCopyObject())
 	}
@@ -7,6 +7,7 @@ import (
 
 	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
 
+	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/jaeger"
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/models"
@@ -247,6 +248,35 @@ func (in *JaegerService) GetStatus() (accessible bool, err error) {
 	return client.GetServiceStatus()
 }
 
+// GetNamespaceApps returns the names of the namespace apps reporting traces. When the Jaeger service names
+// are not qualified by namespace (see tracing namespace_selector) every reporting service is returned.
+func (in *JaegerService) GetNamespaceApps(ns string) ([]string, error) {
+	client, err := in.client()
+	if err != nil {
+		return nil, err
+	}
+	services, err := client.GetServices()
+	if err != nil {
+		return nil, err
+	}
+
+	conf := config.Get()
+	apps := []string{}
+	if !conf.ExternalServices.Tracing.NamespaceSelector {
+		return append(apps, services.Data...), nil
+	}
+	for _, service := range services.Data {
+		if ns == conf.IstioNamespace {
+			if !strings.Contains(service, ".") {
+				apps = append(apps, service)
+			}
+		} else if strings.HasSuffix(service, "."+ns) {
+			apps = append(apps, strings.TrimSuffix(service, "."+ns))
+		}
+	}
+	return apps, nil
+}
+
 func matchesWorkload(trace *jaegerModels.Trace, namespace, workload string) bool {
 	for _, span := range trace.Spans {
 		if process, ok := trace.Processes[span.ProcessID]; ok {
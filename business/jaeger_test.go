@@ -6,7 +6,9 @@ import (
 	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/jaeger"
+	"github.com/kiali/kiali/jaeger/jaegertest"
 )
 
 var trace1 = jaegerModels.Trace{
@@ -159,3 +161,30 @@ func TestTracesToSpanWithWorkloadFilter(t *testing.T) {
 	assert.Equal("t2_process_2", string(spans[0].ProcessID))
 	assert.Equal("t2_process_3", string(spans[1].ProcessID))
 }
+
+func TestGetNamespaceApps(t *testing.T) {
+	assert := assert.New(t)
+
+	conf := config.NewConfig()
+	conf.ExternalServices.Tracing.NamespaceSelector = true
+	config.Set(conf)
+
+	j := new(jaegertest.JaegerClientMock)
+	j.On("GetServices").Return(&jaeger.JaegerServices{Data: []string{"istio-ingressgateway", "productpage.bookinfo", "reviews.bookinfo", "ratings.default"}}, nil)
+	service := JaegerService{jaeger: j}
+
+	apps, err := service.GetNamespaceApps("bookinfo")
+	assert.NoError(err)
+	assert.Equal([]string{"productpage", "reviews"}, apps)
+
+	apps, err = service.GetNamespaceApps(conf.IstioNamespace)
+	assert.NoError(err)
+	assert.Equal([]string{"istio-ingressgateway"}, apps)
+
+	// without the namespace selector every service may be an app of the namespace
+	conf.ExternalServices.Tracing.NamespaceSelector = false
+	config.Set(conf)
+	apps, err = service.GetNamespaceApps("bookinfo")
+	assert.NoError(err)
+	assert.Len(apps, 4)
+}
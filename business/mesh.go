@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v2"
@@ -425,8 +427,6 @@ func (in *MeshService) resolveKialiNetwork() (string, error) {
 // visible to the adjacent mesh control plane. This assumes that the Istio namespace is
 // named the same as in Kiali's Cluster.
 func (in *MeshService) resolveRemoteClustersFromSecrets() ([]Cluster, error) {
-	conf := config.Get()
-
 	// For the ControlPlane to be able to "see" remote clusters, some "remote secrets" need to be in
 	// place. These remote secrets contain <kubeconfig files> that the ControlPlane uses to
 	// query the remote clusters. Without them, the control plane is not capable of pushing traffic
@@ -437,6 +437,47 @@ func (in *MeshService) resolveRemoteClustersFromSecrets() ([]Cluster, error) {
 	// Strictly speaking, this list may be incomplete: it's list of visible clusters for a control plane.
 	// But, for now, let's use it as the absolute "list of clusters in the mesh (excluding home cluster)".
 
+	remoteSecrets, err := in.getRemoteSecrets()
+	if err != nil {
+		return []Cluster{}, err
+	}
+
+	clusters := make([]Cluster, 0, len(remoteSecrets))
+
+	for _, remoteSecret := range remoteSecrets {
+		clusterName := remoteSecret.clusterName
+		parsedSecret := remoteSecret.kubeconfig
+
+		meshCluster := Cluster{
+			Name:        clusterName,
+			SecretName:  remoteSecret.name,
+			ApiEndpoint: parsedSecret.Clusters[0].Cluster.Server,
+		}
+
+		networkName := in.resolveNetwork(clusterName, parsedSecret)
+		if len(networkName) != 0 {
+			meshCluster.Network = networkName
+		}
+
+		meshCluster.KialiInstances = in.findRemoteKiali(clusterName, parsedSecret)
+		clusters = append(clusters, meshCluster)
+	}
+
+	return clusters, nil
+}
+
+// remoteSecret is a parsed Istio remote secret, holding the kubeconfig file to access a remote cluster
+type remoteSecret struct {
+	clusterName string
+	kubeconfig  *kubernetes.RemoteSecret
+	name        string
+}
+
+// getRemoteSecrets returns the Istio remote secrets of the Istio namespace. Secrets that can't be parsed
+// are ignored.
+func (in *MeshService) getRemoteSecrets() ([]remoteSecret, error) {
+	conf := config.Get()
+
 	// "Remote secrets" are created using the command `istioctl x create-remote-secret` which
 	// labels the secrets with istio/multiCluster=true. Let's use that label to fetch the secrets of interest.
 	secrets, err := in.k8s.GetSecrets(conf.IstioNamespace, "istio/multiCluster=true")
@@ -447,18 +488,14 @@ func (in *MeshService) resolveRemoteClustersFromSecrets() ([]Cluster, error) {
 			// because it is known that the environment is a single-cluster. So, return
 			// and empty list of clusters, avoid the warning error and use a trace log message.
 			log.Trace("Not enough privileges to list secrets with istio/multiCluster=true label.")
-			return []Cluster{}, nil
+			return []remoteSecret{}, nil
 		}
-		return []Cluster{}, err
-	}
-
-	if len(secrets) == 0 {
-		return []Cluster{}, nil
+		return []remoteSecret{}, err
 	}
 
-	clusters := make([]Cluster, 0, len(secrets))
+	remoteSecrets := make([]remoteSecret, 0, len(secrets))
 
-	// Inspect the secret to extract the cluster_id and api_endpoint of each remote cluster.
+	// Inspect the secret to extract the cluster_id and kubeconfig file of each remote cluster.
 	for _, secret := range secrets {
 		clusterName, ok := secret.Annotations["networking.istio.io/cluster"]
 		if !ok {
@@ -482,22 +519,75 @@ func (in *MeshService) resolveRemoteClustersFromSecrets() ([]Cluster, error) {
 			continue
 		}
 
-		meshCluster := Cluster{
-			Name:        clusterName,
-			SecretName:  secret.Name,
-			ApiEndpoint: parsedSecret.Clusters[0].Cluster.Server,
+		remoteSecrets = append(remoteSecrets, remoteSecret{clusterName: clusterName, kubeconfig: parsedSecret, name: secret.Name})
+	}
+
+	return remoteSecrets, nil
+}
+
+// RemotePrometheus locates the Prometheus of a remote cluster of the mesh
+type RemotePrometheus struct {
+	// Cluster is the CLUSTER_ID as known by the Control Plane
+	Cluster string
+
+	// RestConfig holds the credentials of the remote secret, to access the cluster's API server
+	RestConfig *rest.Config
+
+	// URL is the Prometheus URL, proxied by the cluster's API server
+	URL string
+}
+
+// GetRemotePrometheus resolves the Prometheus of the remote clusters found in the Istio remote secrets. The
+// service, as "<name>:<port>", is assumed to be in the Istio namespace and is reached through the service proxy
+// of the remote API server, using the remote secret credentials.
+func (in *MeshService) GetRemotePrometheus(service string) ([]RemotePrometheus, error) {
+	remoteSecrets, err := in.getRemoteSecrets()
+	if err != nil {
+		return nil, err
+	}
+
+	istioNamespace := config.Get().IstioNamespace
+	remotePrometheus := make([]RemotePrometheus, 0, len(remoteSecrets))
+	for _, remoteSecret := range remoteSecrets {
+		restConfig, restConfigErr := kubernetes.UseRemoteCreds(remoteSecret.kubeconfig)
+		if restConfigErr != nil {
+			log.Errorf("Error using remote creds of cluster [%s]: %v", remoteSecret.clusterName, restConfigErr)
+			continue
+		}
+		if len(remoteSecret.kubeconfig.Users) > 0 {
+			restConfig.BearerToken = remoteSecret.kubeconfig.Users[0].User.Token
 		}
 
-		networkName := in.resolveNetwork(clusterName, parsedSecret)
-		if len(networkName) != 0 {
-			meshCluster.Network = networkName
+		remotePrometheus = append(remotePrometheus, RemotePrometheus{
+			Cluster:    remoteSecret.clusterName,
+			RestConfig: restConfig,
+			URL:        fmt.Sprintf("%s/api/v1/namespaces/%s/services/%s/proxy", restConfig.Host, istioNamespace, service),
+		})
+	}
+
+	return remotePrometheus, nil
+}
+
+// GetRemoteSecretsVersion returns the version of the Istio remote secrets, as their names and resource
+// versions. It changes whenever a remote secret is added, updated or removed, allowing to cache what is
+// resolved from the secrets without parsing them again.
+func (in *MeshService) GetRemoteSecretsVersion() (string, error) {
+	secrets, err := in.k8s.GetSecrets(config.Get().IstioNamespace, "istio/multiCluster=true")
+	if err != nil {
+		if errors.IsForbidden(err) {
+			// Same as getRemoteSecrets, no remote secret is visible without the privileges to list them
+			return "", nil
 		}
+		return "", err
+	}
 
-		meshCluster.KialiInstances = in.findRemoteKiali(clusterName, parsedSecret)
-		clusters = append(clusters, meshCluster)
+	versions := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		versions = append(versions, secret.Name+"="+secret.ResourceVersion)
 	}
+	sort.Strings(versions)
 
-	return clusters, nil
+	return strings.Join(versions, ","), nil
 }
 
 // resolveNetwork tries to resolve the NETWORK_ID (as know by the Control Plane) of the
@@ -223,6 +223,71 @@ func TestGetClustersResolvesRemoteClusters(t *testing.T) {
 	check.Equal("kiali-service", a[0].KialiInstances[0].ServiceName, "GetClusters didn't set the right service name of the Kiali instance")
 }
 
+// TestGetRemotePrometheus verifies that the Prometheus of the remote clusters is
+// resolved from the remote secrets, proxied by the remote API server
+func TestGetRemotePrometheus(t *testing.T) {
+	check := assert.New(t)
+
+	k8s := new(kubetest.K8SClientMock)
+	conf := config.NewConfig()
+	config.Set(conf)
+
+	remoteSecretData := kubernetes.RemoteSecret{
+		Clusters: []kubernetes.RemoteSecretClusterListItem{
+			{
+				Name: "west",
+				Cluster: kubernetes.RemoteSecretCluster{
+					CertificateAuthorityData: "eAo=",
+					Server:                   "https://192.168.144.17:123",
+				},
+			},
+		},
+		Users: []kubernetes.RemoteSecretUser{
+			{
+				Name: "foo",
+				User: kubernetes.RemoteSecretUserToken{
+					Token: "bar",
+				},
+			},
+		},
+	}
+	marshalledRemoteSecretData, _ := yaml.Marshal(remoteSecretData)
+
+	secretMock := core_v1.Secret{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "istio-remote-secret-west",
+			Annotations: map[string]string{
+				"networking.istio.io/cluster": "west",
+			},
+		},
+		Data: map[string][]byte{
+			"west": marshalledRemoteSecretData,
+		},
+	}
+	unparseableSecretMock := core_v1.Secret{
+		ObjectMeta: v1.ObjectMeta{
+			Name: "istio-remote-secret-east",
+			Annotations: map[string]string{
+				"networking.istio.io/cluster": "east",
+			},
+		},
+		Data: map[string][]byte{
+			"other": marshalledRemoteSecretData,
+		},
+	}
+
+	k8s.On("IsOpenShift").Return(false)
+	k8s.On("GetSecrets", conf.IstioNamespace, "istio/multiCluster=true").Return([]core_v1.Secret{secretMock, unparseableSecretMock}, nil)
+
+	layer := NewWithBackends(k8s, nil, nil)
+	remotePrometheus, err := layer.Mesh.GetRemotePrometheus("prometheus:9090")
+	check.Nil(err)
+	check.Len(remotePrometheus, 1)
+	check.Equal("west", remotePrometheus[0].Cluster)
+	check.Equal("https://192.168.144.17:123/api/v1/namespaces/istio-system/services/prometheus:9090/proxy", remotePrometheus[0].URL)
+	check.Equal("bar", remotePrometheus[0].RestConfig.BearerToken)
+}
+
 // TestIsMeshConfiguredIsCached verifies that IsMeshConfigured is properly caching
 // it's findings and the cached value is being returned.
 func TestIsMeshConfiguredIsCached(t *testing.T) {
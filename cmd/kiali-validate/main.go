@@ -0,0 +1,143 @@
+// Kiali-validate runs the Kiali validations on Istio YAML files, without a cluster, e.g. to validate
+// manifests in CI before they are applied.
+//
+// Usage:
+//
+//	kiali-validate [flags] <file or directory>...
+//
+// The files, and the .yaml/.yml files of the directories, may hold Istio objects as well as the Kubernetes
+// objects they refer to (Namespaces, Services, Deployments, Pods, the Istio ConfigMap, etc.). The validations
+// only know about the loaded objects. The validation rules of the configuration file and the
+// kiali.io/validations-ignore annotations of the objects apply. The exit code is 0 when no check fails, 1 when
+// a check fails and 2 when the command fails (invalid flags, unreadable files, etc.).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/rs/zerolog"
+	zl "github.com/rs/zerolog/log"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes/offline"
+	"github.com/kiali/kiali/models"
+)
+
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitError  = 2
+)
+
+// Command line arguments
+var (
+	argConfigFile = flag.String("config", "", "Path to the Kiali YAML configuration file, e.g. to set the Istio namespace or the validations to ignore.")
+	argFailOn     = flag.String("fail-on", string(models.ErrorSeverity), "The severity of the checks failing the validation: error or warning.")
+	argNamespace  = flag.String("namespace", "default", "The namespace of the objects without a namespace.")
+	argOutput     = flag.String("output", outputText, "The output format: text, json or sarif.")
+	argVerbose    = flag.Bool("verbose", false, "Log debug messages to stderr.")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <file or directory>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	os.Exit(run())
+}
+
+func run() int {
+	// log to stderr, stdout is for the report
+	zl.Logger = zl.Output(zerolog.ConsoleWriter{Out: os.Stderr, NoColor: true})
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	if *argVerbose {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	}
+
+	if err := validateFlags(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		return exitError
+	}
+
+	cfg := config.NewConfig()
+	if *argConfigFile != "" {
+		var err error
+		if cfg, err = config.LoadFromFile(*argConfigFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		if err := models.ValidateValidationRules(cfg.KialiFeatureFlags.Validations.Rules); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+	}
+	// every loaded namespace is validated
+	cfg.API.Namespaces.Exclude = []string{}
+	cfg.Deployment.AccessibleNamespaces = []string{"**"}
+	config.Set(cfg)
+
+	objects := offline.NewObjects(*argNamespace)
+	if err := objects.LoadPaths(flag.Args()...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	validations, err := validate(objects)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	r := newReport(validations, objects.Locations)
+	if err := r.write(os.Stdout, *argOutput); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	if r.Summary.Errors > 0 || (*argFailOn == string(models.WarningSeverity) && r.Summary.Warnings > 0) {
+		return exitFailed
+	}
+	return exitOK
+}
+
+func validateFlags() error {
+	if flag.NArg() == 0 {
+		return fmt.Errorf("no file or directory to validate")
+	}
+	switch *argFailOn {
+	case string(models.ErrorSeverity), string(models.WarningSeverity):
+	default:
+		return fmt.Errorf("invalid fail-on severity [%s]", *argFailOn)
+	}
+	switch *argOutput {
+	case outputJSON, outputSARIF, outputText:
+	default:
+		return fmt.Errorf("invalid output [%s]", *argOutput)
+	}
+	return nil
+}
+
+// validate runs the validations of every namespace of the objects, as the Kiali server would do with the
+// objects in a cluster
+func validate(objects *offline.Objects) (models.IstioValidations, error) {
+	layer := business.NewWithBackends(offline.NewClient(objects), nil, nil)
+
+	namespaces := objects.NamespaceNames()
+	sort.Strings(namespaces)
+
+	validations := models.IstioValidations{}
+	for _, ns := range namespaces {
+		nsValidations, err := layer.Validations.GetValidations(ns, "")
+		if err != nil {
+			return nil, fmt.Errorf("unable to validate namespace [%s]: %v", ns, err)
+		}
+		validations.MergeValidations(nsValidations)
+	}
+	return validations, nil
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kiali/kiali/kubernetes/offline"
+	"github.com/kiali/kiali/models"
+)
+
+// The supported output formats
+const (
+	outputJSON  = "json"
+	outputSARIF = "sarif"
+	outputText  = "text"
+)
+
+// result is the validation of an object, with the location of the object in the YAML files when known
+type result struct {
+	models.IstioValidation
+	File      string `json:"file,omitempty"`
+	Line      int    `json:"line,omitempty"`
+	Namespace string `json:"namespace"`
+}
+
+// report holds the validations with checks, sorted by location then object, and the summary of all validations
+type report struct {
+	Summary     models.IstioValidationSummary `json:"summary"`
+	Validations []result                      `json:"validations"`
+}
+
+func newReport(validations models.IstioValidations, locations map[models.IstioValidationKey]offline.Location) report {
+	r := report{Validations: []result{}}
+	for key, v := range validations {
+		r.Summary.ObjectCount++
+		for _, c := range v.Checks {
+			switch c.Severity {
+			case models.ErrorSeverity:
+				r.Summary.Errors++
+			case models.WarningSeverity:
+				r.Summary.Warnings++
+			}
+		}
+		if len(v.Checks) == 0 {
+			continue
+		}
+		location := locations[key]
+		r.Validations = append(r.Validations, result{IstioValidation: *v, File: location.File, Line: location.Line, Namespace: key.Namespace})
+	}
+
+	sort.Slice(r.Validations, func(i, j int) bool {
+		a, b := r.Validations[i], r.Validations[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.ObjectType != b.ObjectType {
+			return a.ObjectType < b.ObjectType
+		}
+		return a.Name < b.Name
+	})
+
+	return r
+}
+
+func (r report) write(w io.Writer, output string) error {
+	switch output {
+	case outputJSON:
+		return writeJSON(w, r)
+	case outputSARIF:
+		return writeJSON(w, r.sarif())
+	case outputText:
+		return r.writeText(w)
+	}
+	return fmt.Errorf("unsupported output [%s]", output)
+}
+
+func writeJSON(w io.Writer, value interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(value)
+}
+
+// writeText writes a line per check, prefixed by the object location (as compilers do) and a summary line
+func (r report) writeText(w io.Writer) error {
+	for _, v := range r.Validations {
+		location := v.Namespace + "/" + v.Name
+		if v.File != "" {
+			location = fmt.Sprintf("%s:%d", v.File, v.Line)
+		}
+		for _, c := range v.Checks {
+			path := ""
+			if c.Path != "" {
+				path = " (" + c.Path + ")"
+			}
+			if _, err := fmt.Fprintf(w, "%s: %s: %s %s/%s: %s [%s]%s\n", location, c.Severity, v.ObjectType, v.Namespace, v.Name, c.Message, c.Code, path); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d objects validated: %d errors, %d warnings\n", r.Summary.ObjectCount, r.Summary.Errors, r.Summary.Warnings)
+	return err
+}
+
+// SARIF 2.1.0, only the properties used by the report are defined.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+const (
+	sarifSchema       = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion      = "2.1.0"
+	validationsDocURI = "https://kiali.io/docs/features/validations/"
+)
+
+type sarifLog struct {
+	Runs    []sarifRun `json:"runs"`
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+}
+
+type sarifRun struct {
+	Results []sarifResult `json:"results"`
+	Tool    sarifTool     `json:"tool"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	InformationURI string      `json:"informationUri"`
+	Name           string      `json:"name"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	HelpURI          string       `json:"helpUri"`
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	Level     string          `json:"level"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+	Message   sarifMessage    `json:"message"`
+	RuleID    string          `json:"ruleId"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarif returns the report as a SARIF log with a result per check, the check codes being the rules
+func (r report) sarif() sarifLog {
+	run := sarifRun{
+		Results: []sarifResult{},
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				InformationURI: validationsDocURI,
+				Name:           "kiali",
+				Rules:          []sarifRule{},
+			},
+		},
+	}
+
+	rules := make(map[string]bool)
+	for _, v := range r.Validations {
+		for _, c := range v.Checks {
+			if !rules[c.Code] {
+				rules[c.Code] = true
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+					HelpURI:          validationsDocURI + "#" + strings.ToLower(c.Code),
+					ID:               c.Code,
+					ShortDescription: sarifMessage{Text: c.Message},
+				})
+			}
+
+			res := sarifResult{
+				Level:   sarifLevel(c.Severity),
+				Message: sarifMessage{Text: fmt.Sprintf("%s %s/%s: %s", v.ObjectType, v.Namespace, v.Name, c.Message)},
+				RuleID:  c.Code,
+			}
+			if v.File != "" {
+				res.Locations = []sarifLocation{{
+					PhysicalLocation: sarifPhysicalLocation{
+						ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(v.File)},
+						Region:           sarifRegion{StartLine: v.Line},
+					},
+				}}
+			}
+			run.Results = append(run.Results, res)
+		}
+	}
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID
+	})
+
+	return sarifLog{Runs: []sarifRun{run}, Schema: sarifSchema, Version: sarifVersion}
+}
+
+func sarifLevel(severity models.SeverityLevel) string {
+	switch severity {
+	case models.ErrorSeverity:
+		return "error"
+	case models.WarningSeverity:
+		return "warning"
+	}
+	return "note"
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/kubernetes/offline"
+	"github.com/kiali/kiali/models"
+)
+
+func testReport() report {
+	validations := models.IstioValidations{
+		models.BuildKey("virtualservice", "reviews", "bookinfo"): {
+			Name:       "reviews",
+			ObjectType: "virtualservice",
+			Checks: []*models.IstioCheck{
+				{Code: "KIA1102", Message: "VirtualService is pointing to a non-existent gateway", Severity: models.ErrorSeverity, Path: "spec/gateways[0]"},
+				{Code: "KIA1107", Message: "Subset not found", Severity: models.WarningSeverity, Path: "spec/http[0]/route[0]/destination"},
+			},
+		},
+		models.BuildKey("gateway", "ingress", "bookinfo"): {
+			Name:       "ingress",
+			ObjectType: "gateway",
+			Checks: []*models.IstioCheck{
+				{Code: "KIA0302", Message: "No matching workload found for gateway selector in this namespace", Severity: models.WarningSeverity, Path: "spec/selector"},
+			},
+		},
+		models.BuildKey("destinationrule", "reviews", "bookinfo"): {
+			Name:       "reviews",
+			ObjectType: "destinationrule",
+			Valid:      true,
+			Checks:     []*models.IstioCheck{},
+		},
+	}
+	locations := map[models.IstioValidationKey]offline.Location{
+		models.BuildKey("virtualservice", "reviews", "bookinfo"):  {File: "manifests/bookinfo.yaml", Line: 33},
+		models.BuildKey("destinationrule", "reviews", "bookinfo"): {File: "manifests/bookinfo.yaml", Line: 1},
+	}
+	return newReport(validations, locations)
+}
+
+func TestReport(t *testing.T) {
+	assert := assert.New(t)
+
+	r := testReport()
+	assert.Equal(models.IstioValidationSummary{Errors: 1, ObjectCount: 3, Warnings: 2}, r.Summary)
+
+	// objects without checks are not reported, objects without location come first
+	assert.Len(r.Validations, 2)
+	assert.Equal("ingress", r.Validations[0].Name)
+	assert.Equal("", r.Validations[0].File)
+	assert.Equal("reviews", r.Validations[1].Name)
+	assert.Equal("manifests/bookinfo.yaml", r.Validations[1].File)
+	assert.Equal(33, r.Validations[1].Line)
+}
+
+func TestReportText(t *testing.T) {
+	assert := assert.New(t)
+
+	var b bytes.Buffer
+	assert.NoError(testReport().write(&b, outputText))
+	assert.Equal(`bookinfo/ingress: warning: gateway bookinfo/ingress: No matching workload found for gateway selector in this namespace [KIA0302] (spec/selector)
+manifests/bookinfo.yaml:33: error: virtualservice bookinfo/reviews: VirtualService is pointing to a non-existent gateway [KIA1102] (spec/gateways[0])
+manifests/bookinfo.yaml:33: warning: virtualservice bookinfo/reviews: Subset not found [KIA1107] (spec/http[0]/route[0]/destination)
+3 objects validated: 1 errors, 2 warnings
+`, b.String())
+
+	assert.Error(testReport().write(&b, "xml"))
+}
+
+func TestReportSarif(t *testing.T) {
+	assert := assert.New(t)
+
+	sarif := testReport().sarif()
+	assert.Equal("2.1.0", sarif.Version)
+	assert.Len(sarif.Runs, 1)
+
+	run := sarif.Runs[0]
+	assert.Equal("kiali", run.Tool.Driver.Name)
+	assert.Len(run.Tool.Driver.Rules, 3)
+	assert.Equal("KIA0302", run.Tool.Driver.Rules[0].ID)
+	assert.Equal("https://kiali.io/docs/features/validations/#kia0302", run.Tool.Driver.Rules[0].HelpURI)
+
+	assert.Len(run.Results, 3)
+	assert.Equal("warning", run.Results[0].Level)
+	assert.Equal("KIA0302", run.Results[0].RuleID)
+	assert.Empty(run.Results[0].Locations)
+
+	assert.Equal("error", run.Results[1].Level)
+	assert.Equal("KIA1102", run.Results[1].RuleID)
+	assert.Equal("virtualservice bookinfo/reviews: VirtualService is pointing to a non-existent gateway", run.Results[1].Message.Text)
+	assert.Len(run.Results[1].Locations, 1)
+	assert.Equal("manifests/bookinfo.yaml", run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(33, run.Results[1].Locations[0].PhysicalLocation.Region.StartLine)
+}
@@ -212,10 +212,16 @@ type Iter8Config struct {
 	Namespace string `yaml:"namespace"`
 }
 
+// GatewayAPIConfig enables reading the Kubernetes Gateway API resources, when the API is installed in the cluster
+type GatewayAPIConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
 // Extensions struct describes configuration for Kiali add-ons (extensions)
 // New add-on/extension configuration should create a specific config and be located under this
 type Extensions struct {
-	Iter8 Iter8Config `yaml:"iter_8,omitempty"`
+	GatewayAPI GatewayAPIConfig `yaml:"gateway_api,omitempty"`
+	Iter8      Iter8Config      `yaml:"iter_8,omitempty"`
 }
 
 // ExternalServices holds configurations for other systems that Kiali depends on
@@ -327,6 +333,52 @@ type DeploymentConfig struct {
 	ViewOnlyMode         bool     `yaml:"view_only_mode,omitempty"`
 }
 
+// GraphConfig describes server-side graph generation
+type GraphConfig struct {
+	// Cache duration expressed in seconds. Graph requests within the same CacheDuration window (of queryTime) share the cached result
+	CacheDuration int `yaml:"cache_duration,omitempty"`
+	// Enable cache for generated graphs
+	CacheEnabled bool `yaml:"cache_enabled,omitempty"`
+	// Federation of the telemetry of the remote clusters of the mesh
+	Federation GraphFederationConfig `yaml:"federation,omitempty"`
+	// Persistence of saved graphs, for later replay
+	Snapshots GraphSnapshotConfig `yaml:"snapshots,omitempty"`
+}
+
+// GraphFederationConfig describes the multi-cluster graph federation. When enabled, namespace graphs
+// are generated from the Prometheus of each cluster, and merged.
+type GraphFederationConfig struct {
+	// Prometheus of the remote clusters. It overrides the discovered Prometheus of the same cluster
+	Clusters []GraphFederationCluster `yaml:"clusters,omitempty"`
+	// Discover the remote clusters from the Istio remote secrets, their Prometheus is reached through the cluster API server proxy
+	Discover bool `yaml:"discover,omitempty"`
+	// Enable the graph federation
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Prometheus service of the discovered clusters, as <name>:<port> in the Istio namespace
+	PrometheusService string `yaml:"prometheus_service,omitempty"`
+	// Timeout in seconds of the graph of each remote cluster, a remote cluster not providing its graph in time is skipped
+	RemoteTimeout int `yaml:"remote_timeout,omitempty"`
+}
+
+// GraphFederationCluster describes the Prometheus of a remote cluster
+type GraphFederationCluster struct {
+	// Cluster name, as known by the control plane
+	Name       string           `yaml:"name"`
+	Prometheus PrometheusConfig `yaml:"prometheus,omitempty"`
+}
+
+// GraphSnapshotConfig describes the persistence of graph snapshots
+type GraphSnapshotConfig struct {
+	// Directory holding the snapshots, for the file store
+	Directory string `yaml:"directory,omitempty"`
+	// Max age expressed in seconds, older snapshots are removed. 0 for no limit
+	MaxAge int `yaml:"max_age,omitempty"`
+	// Max number of snapshots of a set of namespaces, the oldest snapshots beyond it are removed. 0 for no limit
+	MaxCount int `yaml:"max_count,omitempty"`
+	// Snapshot store: configmap | file. ConfigMaps are created in the Kiali deployment namespace
+	Store string `yaml:"store,omitempty"`
+}
+
 // GraphFindOption defines a single Graph Find/Hide Option
 type GraphFindOption struct {
 	Description string `yaml:"description,omitempty" json:"description,omitempty"`
@@ -373,7 +425,19 @@ type UIDefaults struct {
 
 // Validations defines default settings configured for the Validations subsystem
 type Validations struct {
-	Ignore []string `yaml:"ignore,omitempty" json:"ignore,omitempty"`
+	Ignore []string         `yaml:"ignore,omitempty" json:"ignore,omitempty"`
+	Rules  []ValidationRule `yaml:"rules,omitempty" json:"rules,omitempty"`
+}
+
+// ValidationRule ignores the checks of a code or overrides their severity (error or warning). A rule may be
+// limited to the objects of some namespaces, given as regular expressions, and/or to the objects matching a
+// label selector. The first rule matching a check is applied.
+type ValidationRule struct {
+	Code          string   `yaml:"code" json:"code"`
+	Ignore        bool     `yaml:"ignore,omitempty" json:"ignore,omitempty"`
+	LabelSelector string   `yaml:"label_selector,omitempty" json:"labelSelector,omitempty"`
+	Namespaces    []string `yaml:"namespaces,omitempty" json:"namespaces,omitempty"`
+	Severity      string   `yaml:"severity,omitempty" json:"severity,omitempty"`
 }
 
 // CertificatesInformationIndicators defines configuration to enable the feature and to grant read permissions to a list of secrets
@@ -422,6 +486,7 @@ type Config struct {
 	Deployment               DeploymentConfig                    `yaml:"deployment,omitempty"`
 	Extensions               Extensions                          `yaml:"extensions,omitempty"`
 	ExternalServices         ExternalServices                    `yaml:"external_services,omitempty"`
+	Graph                    GraphConfig                         `yaml:"graph,omitempty"`
 	HealthConfig             HealthConfig                        `yaml:"health_config,omitempty" json:"healthConfig,omitempty"`
 	Identity                 security.Identity                   `yaml:",omitempty"`
 	InCluster                bool                                `yaml:"in_cluster,omitempty"`
@@ -479,6 +544,9 @@ func NewConfig() (c *Config) {
 			ViewOnlyMode:         false,
 		},
 		Extensions: Extensions{
+			GatewayAPI: GatewayAPIConfig{
+				Enabled: false,
+			},
 			Iter8: Iter8Config{
 				Enabled:   false,
 				Namespace: "iter8",
@@ -565,6 +633,23 @@ func NewConfig() (c *Config) {
 				WhiteListIstioSystem: []string{"jaeger-query", "istio-ingressgateway"},
 			},
 		},
+		Graph: GraphConfig{
+			CacheDuration: 10,
+			CacheEnabled:  true,
+			Federation: GraphFederationConfig{
+				Clusters:          []GraphFederationCluster{},
+				Discover:          false,
+				Enabled:           false,
+				PrometheusService: "prometheus:9090",
+				RemoteTimeout:     10,
+			},
+			Snapshots: GraphSnapshotConfig{
+				Directory: "/tmp/kiali/graph-snapshots",
+				MaxAge:    7 * 24 * 60 * 60,
+				MaxCount:  50,
+				Store:     "file",
+			},
+		},
 		IstioLabels: IstioLabels{
 			AppLabelName:       "app",
 			InjectionLabelName: "istio-injection",
@@ -614,6 +699,7 @@ func NewConfig() (c *Config) {
 			},
 			Validations: Validations{
 				Ignore: make([]string, 0),
+				Rules:  make([]ValidationRule, 0),
 			},
 			CertificatesInformationIndicators: CertificatesInformationIndicators{
 				Enabled: true,
@@ -715,6 +801,11 @@ func (conf Config) String() (str string) {
 	obf.ExternalServices.Grafana.Auth.Obfuscate()
 	obf.ExternalServices.Prometheus.Auth.Obfuscate()
 	obf.ExternalServices.Tracing.Auth.Obfuscate()
+	// copy the federated clusters, the slice is shared with conf
+	obf.Graph.Federation.Clusters = append([]GraphFederationCluster{}, conf.Graph.Federation.Clusters...)
+	for i := range obf.Graph.Federation.Clusters {
+		obf.Graph.Federation.Clusters[i].Prometheus.Auth.Obfuscate()
+	}
 	obf.Identity.Obfuscate()
 	obf.LoginToken.Obfuscate()
 	obf.Auth.OpenId.ClientSecret = "xxx"
@@ -70,6 +70,7 @@ func TestSensitiveDataObfuscation(t *testing.T) {
 	conf.ExternalServices.Tracing.Auth.Username = "my-username"
 	conf.ExternalServices.Tracing.Auth.Password = "my-password"
 	conf.ExternalServices.Tracing.Auth.Token = "my-token"
+	conf.Graph.Federation.Clusters = []GraphFederationCluster{{Name: "west", Prometheus: PrometheusConfig{Auth: Auth{Password: "my-password", Token: "my-token"}}}}
 	conf.LoginToken.SigningKey = "my-signkey"
 	conf.LoginToken.ExpirationSeconds = 12345
 
@@ -86,6 +87,7 @@ func TestSensitiveDataObfuscation(t *testing.T) {
 	assert.Equal(t, "my-password", conf.ExternalServices.Prometheus.Auth.Password)
 	assert.Equal(t, "my-token", conf.ExternalServices.Tracing.Auth.Token)
 	assert.Equal(t, "my-signkey", conf.LoginToken.SigningKey)
+	assert.Equal(t, "my-token", conf.Graph.Federation.Clusters[0].Prometheus.Auth.Token)
 }
 
 func TestMarshalUnmarshalStaticContentRootDirectory(t *testing.T) {
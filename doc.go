@@ -4,7 +4,9 @@ import (
 	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
 
 	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/graph/snapshot"
 	"github.com/kiali/kiali/handlers"
 	"github.com/kiali/kiali/jaeger"
 	"github.com/kiali/kiali/models"
@@ -116,6 +118,16 @@ type ObjectTypeParam struct {
 	Name string `json:"object_type"`
 }
 
+// swagger:parameters istioConfigUpdate istioConfigCreate
+type DryRunParam struct {
+	// When true, the object is not changed and the namespace validations as they would be with the change are
+	// returned. Default is false.
+	//
+	// in: query
+	// required: false
+	Name bool `json:"dryRun"`
+}
+
 // swagger:parameters podDetails podLogs podProxyDump podProxyResource podProxyLogging
 type PodParam struct {
 	// The pod name.
@@ -194,9 +206,29 @@ type WorkloadParam struct {
 // - keep this alphabetized
 /////////////////////
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesPath graphNamespacesStream graphService graphSnapshotSave graphWorkload
+type AnomalyBaselineParam struct {
+	// Used only with anomaly appender. One of: 24h | lastWeek.
+	//
+	// in: query
+	// required: false
+	// default: 24h
+	Name string `json:"anomalyBaseline"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesPath graphNamespacesStream graphService graphSnapshotSave graphWorkload
+type AnomalyThresholdParam struct {
+	// Used only with anomaly appender. The absolute z-score at which traffic is anomalous.
+	//
+	// in: query
+	// required: false
+	// default: 3
+	Name string `json:"anomalyThreshold"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesPath graphNamespacesStream graphService graphSnapshotSave graphWorkload
 type AppendersParam struct {
-	// Comma-separated list of Appenders to run. Available appenders: [aggregateNode, deadNode, healthConfig, idleNode, istio, responseTime, securityPolicy, serviceEntry, sidecarsCheck, throughput].
+	// Comma-separated list of Appenders to run. Available appenders: [aggregateNode, anomaly, deadNode, healthConfig, idleNode, istio, percentiles, responseTime, rootCause, securityPolicy, serviceEntry, sidecarsCheck, sparkline, throughput]. The anomaly, percentiles, rootCause and sparkline appenders run only when listed.
 	//
 	// in: query
 	// required: false
@@ -204,9 +236,28 @@ type AppendersParam struct {
 	Name string `json:"appenders"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphNamespacesDiff
+type BaseDurationParam struct {
+	// Query time-range duration of the base time window (Golang string duration).
+	//
+	// in: query
+	// required: false
+	// default: duration
+	Name string `json:"baseDuration"`
+}
+
+// swagger:parameters graphNamespacesDiff
+type BaseQueryTimeParam struct {
+	// Unix time (seconds) for the base query such that the base time range is [baseQueryTime-baseDuration..baseQueryTime].
+	//
+	// in: query
+	// required: true
+	Name string `json:"baseQueryTime"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesStream graphService graphSnapshotSave graphWorkload
 type BoxByParam struct {
-	// Comma-separated list of desired node boxing. Available boxings: [app, cluster, namespace, none].
+	// Comma-separated list of desired node boxing. Available boxings: [app, cluster, label:<key>, namespace, none]. Label boxes nest within a namespace, in the listed order.
 	//
 	// in: query
 	// required: false
@@ -214,7 +265,16 @@ type BoxByParam struct {
 	Name string `json:"boxBy"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphNamespacesPath
+type DestParam struct {
+	// The TrafficMap ID of the path destination node.
+	//
+	// in: query
+	// required: true
+	Name string `json:"dest"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesMatrix graphNamespacesPath graphNamespacesStream graphService graphSnapshotSave graphWorkload
 type DurationGraphParam struct {
 	// Query time-range duration (Golang string duration).
 	//
@@ -224,7 +284,17 @@ type DurationGraphParam struct {
 	Name string `json:"duration"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphNamespacesMatrix
+type FormatMatrixParam struct {
+	// The matrix format. One of: json | csv.
+	//
+	// in: query
+	// required: false
+	// default: json
+	Name string `json:"format"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesMatrix graphNamespacesPath graphNamespacesStream graphService graphSnapshotSave graphWorkload
 type GraphTypeParam struct {
 	// Graph type. Available graph types: [app, service, versionedApp, workload].
 	//
@@ -234,7 +304,16 @@ type GraphTypeParam struct {
 	Name string `json:"graphType"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphWorkload
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesMatrix graphNamespacesPath graphNamespacesStream graphService graphSnapshotSave graphWorkload
+type HideParam struct {
+	// Find/hide expression for the nodes and edges to remove from the graph, e.g. "ns = foo", "http > 10 && %httperr > 5". Edges of a hidden node, and nodes left without edges, are also removed.
+	//
+	// in: query
+	// required: false
+	Name string `json:"hide"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesPath graphNamespacesStream graphSnapshotSave graphWorkload
 type IncludeIdleEdges struct {
 	// Flag for including edges that have no request traffic for the time period.
 	//
@@ -244,7 +323,17 @@ type IncludeIdleEdges struct {
 	Name string `json:"includeIdleEdges"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphWorkload
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesPath graphNamespacesStream graphSnapshotSave graphWorkload
+type IncludeRoutes struct {
+	// Flag for breaking down the request traffic of each response code by route (route name or request path, where present in the telemetry).
+	//
+	// in: query
+	// required: false
+	// default: false
+	Name string `json:"includeRoutes"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesPath graphNamespacesStream graphSnapshotSave graphWorkload
 type InjectServiceNodes struct {
 	// Flag for injecting the requested service node between source and destination nodes.
 	//
@@ -254,7 +343,27 @@ type InjectServiceNodes struct {
 	Name string `json:"injectServiceNodes"`
 }
 
-// swagger:parameters graphNamespaces
+// swagger:parameters graphNamespacesPath
+type LimitPathParam struct {
+	// The maximum number of paths returned.
+	//
+	// in: query
+	// required: false
+	// default: 10
+	Name string `json:"limit"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesStream graphService graphSnapshotSave graphWorkload
+type MaxNodesParam struct {
+	// The maximum number of nodes in the graph. Low-traffic leaf nodes are collapsed into an aggregate node per namespace to honor the limit, when possible. 0 for no limit.
+	//
+	// in: query
+	// required: false
+	// default: 0
+	Name string `json:"maxNodes"`
+}
+
+// swagger:parameters graphNamespaces graphNamespacesDiff graphNamespacesMatrix graphNamespacesPath graphNamespacesStream graphSnapshotSave
 type NamespacesParam struct {
 	// Comma-separated list of namespaces to include in the graph. The namespaces must be accessible to the client.
 	//
@@ -263,7 +372,17 @@ type NamespacesParam struct {
 	Name string `json:"namespaces"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesPath graphNamespacesStream graphService graphSnapshotSave graphWorkload
+type PercentilesParam struct {
+	// Used only with percentiles appender. Comma-separated list of percentiles, each in (0, 100].
+	//
+	// in: query
+	// required: false
+	// default: 50,90,99
+	Name string `json:"percentiles"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesMatrix graphNamespacesPath graphService graphSnapshotSave graphWorkload
 type QueryTimeParam struct {
 	// Unix time (seconds) for query such that time range is [queryTime-duration..queryTime]. Default is now.
 	//
@@ -273,7 +392,17 @@ type QueryTimeParam struct {
 	Name string `json:"queryTime"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphNamespacesPath
+type RankByParam struct {
+	// How to rank the paths, in descending order. One of: errorRate | responseTime.
+	//
+	// in: query
+	// required: false
+	// default: responseTime
+	Name string `json:"rankBy"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesMatrix graphNamespacesPath graphNamespacesStream graphService graphSnapshotSave graphWorkload
 type RateGrpcParam struct {
 	// How to calculate gRPC traffic rate. One of: none | received (i.e. response_messages) | requests | sent (i.e. request_messages) | total (i.e. sent+received).
 	//
@@ -283,7 +412,7 @@ type RateGrpcParam struct {
 	Name string `json:"rateGrpc"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesMatrix graphNamespacesPath graphNamespacesStream graphService graphSnapshotSave graphWorkload
 type RateHttpParam struct {
 	// How to calculate HTTP traffic rate. One of: none | requests.
 	//
@@ -293,9 +422,9 @@ type RateHttpParam struct {
 	Name string `json:"rateHttp"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesMatrix graphNamespacesPath graphNamespacesStream graphService graphSnapshotSave graphWorkload
 type RateTcpParam struct {
-	// How to calculate TCP traffic rate. One of: none | received (i.e. received_bytes) | sent (i.e. sent_bytes) | total (i.e. sent+received).
+	// How to calculate TCP traffic rate. One of: connections (i.e. connections opened, with closed and long-lived connections) | none | received (i.e. received_bytes) | sent (i.e. sent_bytes) | total (i.e. sent+received).
 	//
 	// in: query
 	// required: false
@@ -303,7 +432,17 @@ type RateTcpParam struct {
 	Name string `json:"rateTcp"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphNamespacesStream
+type RefreshIntervalParam struct {
+	// How often the streamed graph is regenerated (Golang string duration). Minimum is 5s, maximum is 10s.
+	//
+	// in: query
+	// required: false
+	// default: 5s
+	Name string `json:"refreshInterval"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesPath graphNamespacesStream graphService graphSnapshotSave graphWorkload
 type ResponseTimeParam struct {
 	// Used only with responseTime appender. One of: avg | 50 | 95 | 99.
 	//
@@ -313,7 +452,44 @@ type ResponseTimeParam struct {
 	Name string `json:"responseTime"`
 }
 
-// swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
+// swagger:parameters graphSnapshotSave
+type SnapshotNameParam struct {
+	// Optional name of the saved graph snapshot.
+	//
+	// in: query
+	// required: false
+	Name string `json:"name"`
+}
+
+// swagger:parameters graphSnapshot
+type SnapshotParam struct {
+	// The ID of the graph snapshot.
+	//
+	// in: path
+	// required: true
+	Name string `json:"snapshot"`
+}
+
+// swagger:parameters graphNamespacesPath
+type SourceParam struct {
+	// The TrafficMap ID of the path source node.
+	//
+	// in: query
+	// required: true
+	Name string `json:"source"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesPath graphNamespacesStream graphService graphSnapshotSave graphWorkload
+type SparklinePointsParam struct {
+	// Used only with sparkline appender. The number of request rates in each series, in [2, 200].
+	//
+	// in: query
+	// required: false
+	// default: 20
+	Name string `json:"sparklinePoints"`
+}
+
+// swagger:parameters graphApp graphAppVersion graphNamespaces graphNamespacesDiff graphNamespacesPath graphNamespacesStream graphService graphSnapshotSave graphWorkload
 type ThroughputParam struct {
 	// Used only with throughput appender. One of: request | response.
 	//
@@ -574,6 +750,36 @@ type GraphResponse struct {
 	Body cytoscape.Config
 }
 
+// swagger:response graphMatrixResponse
+type GraphMatrixResponse struct {
+	// in:body
+	Body graph.NamespaceMatrix
+}
+
+// swagger:response graphPathResponse
+type GraphPathResponse struct {
+	// in:body
+	Body graph.PathAnalysis
+}
+
+// swagger:response graphSnapshotResponse
+type GraphSnapshotResponse struct {
+	// in:body
+	Body snapshot.Snapshot
+}
+
+// swagger:response graphSnapshotInfoResponse
+type GraphSnapshotInfoResponse struct {
+	// in:body
+	Body snapshot.Info
+}
+
+// swagger:response graphSnapshotsResponse
+type GraphSnapshotsResponse struct {
+	// in:body
+	Body []snapshot.Info
+}
+
 // HTTP status code 200 and IstioConfigList model in data
 // swagger:response istioConfigList
 type IstioConfigResponse struct {
@@ -7,7 +7,11 @@ import (
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/graph/config/dot"
+	"github.com/kiali/kiali/graph/config/graphml"
+	"github.com/kiali/kiali/graph/config/jgf"
 	"github.com/kiali/kiali/graph/telemetry/istio"
+	_ "github.com/kiali/kiali/graph/telemetry/jaeger" // registers the jaeger TelemetryVendor
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/prometheus"
 	"github.com/kiali/kiali/prometheus/internalmetrics"
@@ -19,14 +23,10 @@ func GraphNamespaces(business *business.Layer, o graph.Options) (code int, confi
 	promtimer := internalmetrics.GetGraphGenerationTimePrometheusTimer(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes)
 	defer promtimer.ObserveDuration()
 
-	switch o.TelemetryVendor {
-	case graph.VendorIstio:
-		prom, err := prometheus.NewClient()
-		graph.CheckError(err)
-		code, config = graphNamespacesIstio(business, prom, o)
-	default:
-		graph.Error(fmt.Sprintf("TelemetryVendor [%s] not supported", o.TelemetryVendor))
-	}
+	vendor := getTelemetryVendor(o.TelemetryVendor)
+	prom, err := prometheus.NewClient()
+	graph.CheckError(err)
+	code, config = graphNamespaces(business, vendor, prom, o)
 
 	// update metrics
 	internalmetrics.SetGraphNodes(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes, 0)
@@ -36,17 +36,122 @@ func GraphNamespaces(business *business.Layer, o graph.Options) (code int, confi
 
 // graphNamespacesIstio provides a test hook that accepts mock clients
 func graphNamespacesIstio(business *business.Layer, prom *prometheus.Client, o graph.Options) (code int, config interface{}) {
+	return graphNamespaces(business, istio.Vendor{}, prom, o)
+}
+
+// graphNamespaces provides a test hook that accepts a mock vendor and clients
+func graphNamespaces(business *business.Layer, vendor graph.TelemetryVendor, prom *prometheus.Client, o graph.Options) (code int, config interface{}) {
 
 	// Create a 'global' object to store the business. Global only to the request.
 	globalInfo := graph.NewAppenderGlobalInfo()
 	globalInfo.Business = business
 
-	trafficMap := istio.BuildNamespacesTrafficMap(o.TelemetryOptions, prom, globalInfo)
+	trafficMap, o := graphCache.getOrBuild(o, func() graph.TrafficMap {
+		trafficMap := buildNamespacesTrafficMap(business, vendor, prom, o.TelemetryOptions, globalInfo)
+		graph.HideTrafficMap(trafficMap, o.Hide)
+		return trafficMap
+	})
+	graph.CollapseTrafficMap(trafficMap, o.MaxNodes)
+	addBoxByLabels(business, trafficMap, o.BoxByLabels())
 	code, config = generateGraph(trafficMap, o)
 
 	return code, config
 }
 
+// GraphNamespacesDiff generates a namespaces graph comparing two time windows, using the provided options
+func GraphNamespacesDiff(business *business.Layer, o graph.DiffOptions) (code int, config interface{}) {
+	// time how long it takes to generate this graph
+	promtimer := internalmetrics.GetGraphGenerationTimePrometheusTimer(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes)
+	defer promtimer.ObserveDuration()
+
+	vendor := getTelemetryVendor(o.TelemetryVendor)
+	prom, err := prometheus.NewClient()
+	graph.CheckError(err)
+	code, config = graphNamespacesDiff(business, vendor, prom, o)
+
+	// update metrics
+	internalmetrics.SetGraphNodes(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes, 0)
+
+	return code, config
+}
+
+// graphNamespacesDiff provides a test hook that accepts a mock vendor and clients
+func graphNamespacesDiff(business *business.Layer, vendor graph.TelemetryVendor, prom *prometheus.Client, o graph.DiffOptions) (code int, config interface{}) {
+
+	// Create a 'global' object for each time window, the appender cache is only valid for a single graph.
+	baseGlobalInfo := graph.NewAppenderGlobalInfo()
+	baseGlobalInfo.Business = business
+	baseTrafficMap := buildNamespacesTrafficMap(business, vendor, prom, o.Base.TelemetryOptions, baseGlobalInfo)
+
+	globalInfo := graph.NewAppenderGlobalInfo()
+	globalInfo.Business = business
+	trafficMap := buildNamespacesTrafficMap(business, vendor, prom, o.TelemetryOptions, globalInfo)
+
+	diffTrafficMap := graph.DiffTrafficMaps(baseTrafficMap, trafficMap)
+	graph.HideTrafficMap(diffTrafficMap, o.Hide)
+	graph.CollapseTrafficMap(diffTrafficMap, o.MaxNodes)
+	addBoxByLabels(business, diffTrafficMap, o.BoxByLabels())
+	code, config = generateGraph(diffTrafficMap, o.Options)
+
+	return code, config
+}
+
+// GraphNamespacesPath finds the paths between two nodes of a namespaces graph using the provided options
+func GraphNamespacesPath(business *business.Layer, o graph.PathOptions) (code int, analysis interface{}) {
+	vendor := getTelemetryVendor(o.TelemetryVendor)
+	prom, err := prometheus.NewClient()
+	graph.CheckError(err)
+	code, analysis = graphNamespacesPath(business, vendor, prom, o)
+
+	return code, analysis
+}
+
+// graphNamespacesPath provides a test hook that accepts a mock vendor and clients
+func graphNamespacesPath(business *business.Layer, vendor graph.TelemetryVendor, prom *prometheus.Client, o graph.PathOptions) (code int, analysis interface{}) {
+
+	// Create a 'global' object to store the business. Global only to the request.
+	globalInfo := graph.NewAppenderGlobalInfo()
+	globalInfo.Business = business
+
+	trafficMap, _ := graphCache.getOrBuild(o.Options, func() graph.TrafficMap {
+		trafficMap := buildNamespacesTrafficMap(business, vendor, prom, o.TelemetryOptions, globalInfo)
+		graph.HideTrafficMap(trafficMap, o.Hide)
+		return trafficMap
+	})
+
+	return http.StatusOK, graph.FindPaths(trafficMap, o.Source, o.Dest, o.RankBy, o.Limit)
+}
+
+// GraphNamespacesMatrix reduces a namespaces graph to a namespace dependency matrix using the provided options
+func GraphNamespacesMatrix(business *business.Layer, o graph.MatrixOptions) (code int, matrix interface{}) {
+	vendor := getTelemetryVendor(o.TelemetryVendor)
+	prom, err := prometheus.NewClient()
+	graph.CheckError(err)
+	code, matrix = graphNamespacesMatrix(business, vendor, prom, o)
+
+	return code, matrix
+}
+
+// graphNamespacesMatrix provides a test hook that accepts a mock vendor and clients
+func graphNamespacesMatrix(business *business.Layer, vendor graph.TelemetryVendor, prom *prometheus.Client, o graph.MatrixOptions) (code int, matrix interface{}) {
+
+	// Create a 'global' object to store the business. Global only to the request.
+	globalInfo := graph.NewAppenderGlobalInfo()
+	globalInfo.Business = business
+
+	trafficMap, _ := graphCache.getOrBuild(o.Options, func() graph.TrafficMap {
+		trafficMap := buildNamespacesTrafficMap(business, vendor, prom, o.TelemetryOptions, globalInfo)
+		graph.HideTrafficMap(trafficMap, o.Hide)
+		return trafficMap
+	})
+
+	namespaceMatrix := graph.BuildNamespaceMatrix(trafficMap, o.Options)
+	if o.Format == graph.MatrixFormatCSV {
+		return http.StatusOK, graph.NamespaceMatrixCSV{NamespaceMatrix: namespaceMatrix}
+	}
+	return http.StatusOK, namespaceMatrix
+}
+
 // GraphNode generates a node graph using the provided options
 func GraphNode(business *business.Layer, o graph.Options) (code int, config interface{}) {
 	if len(o.Namespaces) != 1 {
@@ -57,14 +162,11 @@ func GraphNode(business *business.Layer, o graph.Options) (code int, config inte
 	promtimer := internalmetrics.GetGraphGenerationTimePrometheusTimer(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes)
 	defer promtimer.ObserveDuration()
 
-	switch o.TelemetryVendor {
-	case graph.VendorIstio:
-		prom, err := prometheus.NewClient()
-		graph.CheckError(err)
-		code, config = graphNodeIstio(business, prom, o)
-	default:
-		graph.Error(fmt.Sprintf("TelemetryVendor [%s] not supported", o.TelemetryVendor))
-	}
+	vendor := getTelemetryVendor(o.TelemetryVendor)
+	prom, err := prometheus.NewClient()
+	graph.CheckError(err)
+	code, config = graphNode(business, vendor, prom, o)
+
 	// update metrics
 	internalmetrics.SetGraphNodes(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes, 0)
 
@@ -73,17 +175,37 @@ func GraphNode(business *business.Layer, o graph.Options) (code int, config inte
 
 // graphNodeIstio provides a test hook that accepts mock clients
 func graphNodeIstio(business *business.Layer, client *prometheus.Client, o graph.Options) (code int, config interface{}) {
+	return graphNode(business, istio.Vendor{}, client, o)
+}
+
+// graphNode provides a test hook that accepts a mock vendor and clients
+func graphNode(business *business.Layer, vendor graph.TelemetryVendor, client *prometheus.Client, o graph.Options) (code int, config interface{}) {
 
 	// Create a 'global' object to store the business. Global only to the request.
 	globalInfo := graph.NewAppenderGlobalInfo()
 	globalInfo.Business = business
 
-	trafficMap := istio.BuildNodeTrafficMap(o.TelemetryOptions, client, globalInfo)
+	trafficMap, o := graphCache.getOrBuild(o, func() graph.TrafficMap {
+		trafficMap := vendor.BuildNodeTrafficMap(o.TelemetryOptions, client, globalInfo)
+		graph.HideTrafficMap(trafficMap, o.Hide)
+		return trafficMap
+	})
+	graph.CollapseTrafficMap(trafficMap, o.MaxNodes)
+	addBoxByLabels(business, trafficMap, o.BoxByLabels())
 	code, config = generateGraph(trafficMap, o)
 
 	return code, config
 }
 
+// getTelemetryVendor returns the registered TelemetryVendor, the name is validated by the graph options
+func getTelemetryVendor(name string) graph.TelemetryVendor {
+	vendor, found := graph.GetTelemetryVendor(name)
+	if !found {
+		graph.Error(fmt.Sprintf("TelemetryVendor [%s] not supported", name))
+	}
+	return vendor
+}
+
 func generateGraph(trafficMap graph.TrafficMap, o graph.Options) (int, interface{}) {
 	log.Tracef("Generating config for [%s] graph...", o.ConfigVendor)
 
@@ -94,6 +216,12 @@ func generateGraph(trafficMap graph.TrafficMap, o graph.Options) (int, interface
 	switch o.ConfigVendor {
 	case graph.VendorCytoscape:
 		vendorConfig = cytoscape.NewConfig(trafficMap, o.ConfigOptions)
+	case graph.VendorDot:
+		vendorConfig = dot.NewConfig(trafficMap, o.ConfigOptions)
+	case graph.VendorGraphML:
+		vendorConfig = graphml.NewConfig(trafficMap, o.ConfigOptions)
+	case graph.VendorJGF:
+		vendorConfig = jgf.NewConfig(trafficMap, o.ConfigOptions)
 	default:
 		graph.Error(fmt.Sprintf("ConfigVendor [%s] not supported", o.ConfigVendor))
 	}
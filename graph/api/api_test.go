@@ -33,6 +33,7 @@ import (
 func setupMocked() (*prometheus.Client, *prometheustest.PromAPIMock, *kubetest.K8SClientMock, error) {
 	conf := config.NewConfig()
 	conf.KubernetesConfig.CacheEnabled = false
+	conf.Graph.CacheEnabled = false
 	config.Set(conf)
 
 	k8s := new(kubetest.K8SClientMock)
@@ -86,6 +87,7 @@ func setupMocked() (*prometheus.Client, *prometheustest.PromAPIMock, *kubetest.K
 func setupMockedWithIstioComponentNamespaces() (*prometheus.Client, *prometheustest.PromAPIMock, *kubetest.K8SClientMock, error) {
 	testConfig := config.NewConfig()
 	testConfig.KubernetesConfig.CacheEnabled = false
+	testConfig.Graph.CacheEnabled = false
 	config.Set(testConfig)
 	k8s := new(kubetest.K8SClientMock)
 
@@ -1047,6 +1049,87 @@ func mockNamespaceRatesGraph(t *testing.T) (*prometheus.Client, *prometheustest.
 	return client, api, nil
 }
 
+// mockNamespaceConnectionsGraph provides TCP connection mocks, with no request or message traffic
+func mockNamespaceConnectionsGraph(t *testing.T) (*prometheus.Client, *prometheustest.PromAPIMock, error) {
+	client, api, _, err := setupMocked()
+	if err != nil {
+		return client, api, err
+	}
+
+	gatewayToTcp := model.Metric{
+		"source_workload_namespace":      "istio-system",
+		"source_workload":                "ingressgateway-unknown",
+		"source_canonical_service":       "ingressgateway",
+		"source_canonical_revision":      "latest",
+		"destination_service_namespace":  "bookinfo",
+		"destination_service":            "tcp:9080",
+		"destination_service_name":       "tcp",
+		"destination_workload_namespace": "bookinfo",
+		"destination_workload":           "tcp-v1",
+		"destination_canonical_service":  "tcp",
+		"destination_canonical_revision": "v1",
+		"response_flags":                 "-"}
+	reviewsToTcp := model.Metric{
+		"source_workload_namespace":      "bookinfo",
+		"source_workload":                "reviews-v1",
+		"source_canonical_service":       "reviews",
+		"source_canonical_revision":      "v1",
+		"destination_service_namespace":  "bookinfo",
+		"destination_service":            "tcp:9080",
+		"destination_service_name":       "tcp",
+		"destination_workload_namespace": "bookinfo",
+		"destination_workload":           "tcp-v1",
+		"destination_canonical_service":  "tcp",
+		"destination_canonical_revision": "v1",
+		"response_flags":                 "-"}
+
+	for _, q := range []string{
+		`round(sum(rate(istio_tcp_connections_opened_total{reporter="source",source_workload_namespace!="bookinfo",destination_workload_namespace="unknown",destination_workload="unknown",destination_service=~"^.+\\.bookinfo\\..+$"} [600s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags) > 0,0.001)`,
+		`round(sum(rate(istio_tcp_connections_closed_total{reporter="source",source_workload_namespace!="bookinfo",destination_workload_namespace="unknown",destination_workload="unknown",destination_service=~"^.+\\.bookinfo\\..+$"} [600s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags) > 0,0.001)`,
+		`round(((sum(istio_tcp_connections_opened_total{reporter="source",source_workload_namespace!="bookinfo",destination_workload_namespace="unknown",destination_workload="unknown",destination_service=~"^.+\\.bookinfo\\..+$"}) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags) - sum(istio_tcp_connections_closed_total{reporter="source",source_workload_namespace!="bookinfo",destination_workload_namespace="unknown",destination_workload="unknown",destination_service=~"^.+\\.bookinfo\\..+$"}) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags)) or sum(istio_tcp_connections_opened_total{reporter="source",source_workload_namespace!="bookinfo",destination_workload_namespace="unknown",destination_workload="unknown",destination_service=~"^.+\\.bookinfo\\..+$"}) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags)) - sum(increase(istio_tcp_connections_opened_total{reporter="source",source_workload_namespace!="bookinfo",destination_workload_namespace="unknown",destination_workload="unknown",destination_service=~"^.+\\.bookinfo\\..+$"} [600s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags) > 0,0.001)`,
+		`round(sum(rate(istio_tcp_connections_opened_total{reporter="source",source_workload_namespace="bookinfo"} [600s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags) > 0,0.001)`,
+		`round(sum(rate(istio_tcp_connections_closed_total{reporter="source",source_workload_namespace="bookinfo"} [600s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags) > 0,0.001)`,
+	} {
+		mockQuery(api, q, &model.Vector{})
+	}
+
+	qOpened := `round(sum(rate(istio_tcp_connections_opened_total{reporter="destination",destination_workload_namespace="bookinfo"} [600s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags) > 0,0.001)`
+	vOpened := model.Vector{
+		&model.Sample{
+			Metric: gatewayToTcp,
+			Value:  10}}
+
+	qClosed := `round(sum(rate(istio_tcp_connections_closed_total{reporter="destination",destination_workload_namespace="bookinfo"} [600s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags) > 0,0.001)`
+	vClosed := model.Vector{
+		&model.Sample{
+			Metric: gatewayToTcp,
+			Value:  8}}
+
+	// reviews only has long-lived connections, opened prior to the time window
+	qLongLived := `round(((sum(istio_tcp_connections_opened_total{reporter="destination",destination_workload_namespace="bookinfo"}) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags) - sum(istio_tcp_connections_closed_total{reporter="destination",destination_workload_namespace="bookinfo"}) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags)) or sum(istio_tcp_connections_opened_total{reporter="destination",destination_workload_namespace="bookinfo"}) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags)) - sum(increase(istio_tcp_connections_opened_total{reporter="destination",destination_workload_namespace="bookinfo"} [600s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags) > 0,0.001)`
+	vLongLived := model.Vector{
+		&model.Sample{
+			Metric: gatewayToTcp,
+			Value:  3},
+		&model.Sample{
+			Metric: reviewsToTcp,
+			Value:  5}}
+
+	// the same long-lived connections are reported by the source proxy
+	qLongLivedOut := `round(((sum(istio_tcp_connections_opened_total{reporter="source",source_workload_namespace="bookinfo"}) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags) - sum(istio_tcp_connections_closed_total{reporter="source",source_workload_namespace="bookinfo"}) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags)) or sum(istio_tcp_connections_opened_total{reporter="source",source_workload_namespace="bookinfo"}) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags)) - sum(increase(istio_tcp_connections_opened_total{reporter="source",source_workload_namespace="bookinfo"} [600s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags) > 0,0.001)`
+	vLongLivedOut := model.Vector{
+		&model.Sample{
+			Metric: reviewsToTcp,
+			Value:  5}}
+
+	mockQuery(api, qOpened, &vOpened)
+	mockQuery(api, qClosed, &vClosed)
+	mockQuery(api, qLongLived, &vLongLived)
+	mockQuery(api, qLongLivedOut, &vLongLivedOut)
+
+	return client, api, nil
+}
+
 func respond(w http.ResponseWriter, code int, payload interface{}) {
 	response, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
@@ -1371,6 +1454,45 @@ func TestRatesGraphNone(t *testing.T) {
 	assert.Equal(t, 200, resp.StatusCode)
 }
 
+func TestRatesGraphConnections(t *testing.T) {
+	client, _, err := mockNamespaceConnectionsGraph(t)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	var fut func(b *business.Layer, p *prometheus.Client, o graph.Options) (int, interface{})
+
+	mr := mux.NewRouter()
+	mr.HandleFunc("/api/namespaces/graph", http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			context := context.WithValue(r.Context(), "authInfo", &api.AuthInfo{Token: "test"})
+			code, config := fut(nil, client, graph.NewOptions(r.WithContext(context)))
+			respond(w, code, config)
+		}))
+
+	ts := httptest.NewServer(mr)
+	defer ts.Close()
+
+	fut = graphNamespacesIstio
+	url := ts.URL + "/api/namespaces/graph?namespaces=bookinfo&graphType=workload&appenders&queryTime=1523364075&rateGrpc=none&rateHttp=none&rateTcp=connections"
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _ := ioutil.ReadAll(resp.Body)
+	expected, _ := ioutil.ReadFile("testdata/test_rates_connections_graph.expected")
+	if runtime.GOOS == "windows" {
+		expected = bytes.Replace(expected, []byte("\r\n"), []byte("\n"), -1)
+	}
+	expected = expected[:len(expected)-1] // remove EOF byte
+
+	if !assert.Equal(t, expected, actual) {
+		fmt.Printf("\nActual:\n%v", string(actual))
+	}
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
 func TestWorkloadNodeGraph(t *testing.T) {
 	q0 := `round(sum(rate(istio_requests_total{reporter="destination",destination_workload_namespace="bookinfo",destination_workload="productpage-v1"} [600s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol,response_code,grpc_response_status,response_flags) > 0,0.001)`
 	q0m0 := model.Metric{
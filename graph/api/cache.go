@@ -0,0 +1,217 @@
+package api
+
+// Cache.go provides a cache of generated TrafficMaps. Requests of the same user with the same normalized graph
+// options, and a queryTime within the same cache duration window, share a single TrafficMap generation. The
+// TrafficMap is cached before any endpoint-specific processing (e.g. collapsing, label boxing), which is
+// applied to a copy of the cached TrafficMap.
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus/internalmetrics"
+)
+
+// normalizedParams are the query params already reflected in the normalized options, other params
+// (e.g. vendor-specific params) are added to the cache key as-is.
+var normalizedParams = []string{
+	"appenders", "baseDuration", "baseQueryTime", "boxBy", "cluster", "configVendor", "dest", "duration", "graphType", "includeIdleEdges",
+	"injectServiceNodes", "limit", "maxNodes", "namespaces", "queryTime", "rankBy", "rateGrpc", "rateHttp", "rateTcp", "refreshInterval",
+	"source", "telemetryVendor",
+}
+
+type graphCacheEntry struct {
+	expiration time.Time
+	queryTime  int64
+	trafficMap graph.TrafficMap
+}
+
+type trafficMapCache struct {
+	flight  singleflight.Group
+	mutex   sync.RWMutex
+	entries map[string]graphCacheEntry // key=cacheKey
+}
+
+// buildPanic holds a panic raised by a TrafficMap build, to be re-raised by every waiting request
+type buildPanic struct {
+	value interface{}
+}
+
+func (b *buildPanic) Error() string {
+	return fmt.Sprintf("%v", b.value)
+}
+
+var graphCache = newTrafficMapCache()
+
+func newTrafficMapCache() *trafficMapCache {
+	return &trafficMapCache{entries: make(map[string]graphCacheEntry)}
+}
+
+// getOrBuild returns a copy of the cached TrafficMap for the options or, if there is no valid cache entry,
+// builds it. Concurrent requests for the same key, and so of the same user, share a single build. The
+// returned options reflect the queryTime of the returned TrafficMap.
+func (c *trafficMapCache) getOrBuild(o graph.Options, build func() graph.TrafficMap) (graph.TrafficMap, graph.Options) {
+	conf := config.Get().Graph
+	if !conf.CacheEnabled || conf.CacheDuration <= 0 {
+		return build(), o
+	}
+
+	cacheDuration := time.Duration(conf.CacheDuration) * time.Second
+	key := cacheKey(o, cacheDuration)
+
+	c.mutex.RLock()
+	entry, found := c.entries[key]
+	c.mutex.RUnlock()
+
+	built := false
+	if !found || !time.Now().Before(entry.expiration) {
+		result, err, _ := c.flight.Do(key, func() (interface{}, error) {
+			built = true
+			return c.build(key, o, cacheDuration, build)
+		})
+		if err != nil {
+			panic(err.(*buildPanic).value)
+		}
+		entry = result.(graphCacheEntry)
+	}
+
+	if built {
+		log.Tracef("[Graph Cache] miss [%s]", key)
+		internalmetrics.GetGraphCacheMissesMetric(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes).Inc()
+	} else {
+		log.Tracef("[Graph Cache] hit [%s]", key)
+		internalmetrics.GetGraphCacheHitsMetric(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes).Inc()
+	}
+
+	o.ConfigOptions.QueryTime = entry.queryTime
+	o.TelemetryOptions.QueryTime = entry.queryTime
+	return copyTrafficMap(entry.trafficMap), o
+}
+
+// build builds and caches the TrafficMap, removing any expired entries. A panic is returned as a buildPanic error.
+func (c *trafficMapCache) build(key string, o graph.Options, cacheDuration time.Duration, build func() graph.TrafficMap) (entry graphCacheEntry, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &buildPanic{value: r}
+		}
+	}()
+
+	entry = graphCacheEntry{
+		queryTime:  o.TelemetryOptions.QueryTime,
+		trafficMap: build(),
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	for k, e := range c.entries {
+		if !now.Before(e.expiration) {
+			delete(c.entries, k)
+		}
+	}
+	entry.expiration = now.Add(cacheDuration)
+	c.entries[key] = entry
+
+	return entry, nil
+}
+
+// cacheKey returns the canonical form of the options relevant to TrafficMap generation. The queryTime
+// is reduced to its cacheDuration window. The user's credentials and accessible namespaces are included,
+// the TrafficMap is generated under the user's RBAC.
+func cacheKey(o graph.Options, cacheDuration time.Duration) string {
+	to := o.TelemetryOptions
+
+	namespaces := make([]string, 0, len(to.Namespaces))
+	for _, ns := range to.Namespaces {
+		namespaces = append(namespaces, fmt.Sprintf("%s:%d", ns.Name, int64(ns.Duration.Seconds())))
+	}
+	sort.Strings(namespaces)
+
+	appenders := "*"
+	if !to.Appenders.All {
+		names := append([]string{}, to.Appenders.AppenderNames...)
+		sort.Strings(names)
+		appenders = strings.Join(names, ",")
+	}
+
+	params := url.Values{}
+	for k, v := range to.Params {
+		params[k] = v
+	}
+	for _, k := range normalizedParams {
+		params.Del(k)
+	}
+
+	window := to.QueryTime / int64(cacheDuration.Seconds())
+
+	accessibleNamespaces := make([]string, 0, len(to.AccessibleNamespaces))
+	for ns := range to.AccessibleNamespaces {
+		accessibleNamespaces = append(accessibleNamespaces, ns)
+	}
+	sort.Strings(accessibleNamespaces)
+
+	return fmt.Sprintf("%s|%s|%s|%d|%d|%s|%s|%t|%t|%s|%s|%s|%+v|%s|%s|%s",
+		o.TelemetryVendor,
+		to.GetGraphKind(),
+		to.GraphType,
+		window,
+		int64(to.Duration.Seconds()),
+		strings.Join(namespaces, ","),
+		appenders,
+		to.IncludeIdleEdges,
+		to.InjectServiceNodes,
+		to.Rates.Grpc,
+		to.Rates.Http,
+		to.Rates.Tcp,
+		to.NodeOptions,
+		params.Encode(),
+		strings.Join(accessibleNamespaces, ","),
+		to.UserKey)
+}
+
+// copyTrafficMap returns a copy of the TrafficMap with new nodes, edges and metadata maps. Metadata values
+// are shared, they are not modified by the post-processing or the config vendors.
+func copyTrafficMap(trafficMap graph.TrafficMap) graph.TrafficMap {
+	result := graph.NewTrafficMap()
+
+	for id, n := range trafficMap {
+		node := *n
+		node.Metadata = copyMetadata(n.Metadata)
+		result[id] = &node
+	}
+	for id, n := range trafficMap {
+		source := result[id]
+		source.Edges = make([]*graph.Edge, len(n.Edges))
+		for i, e := range n.Edges {
+			dest, ok := result[e.Dest.ID]
+			if !ok {
+				dest = e.Dest
+			}
+			source.Edges[i] = &graph.Edge{
+				Source:   source,
+				Dest:     dest,
+				Metadata: copyMetadata(e.Metadata),
+			}
+		}
+	}
+
+	return result
+}
+
+func copyMetadata(md graph.Metadata) graph.Metadata {
+	result := graph.NewMetadata()
+	for k, v := range md {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+)
+
+func setupGraphCache(cacheDuration int) {
+	conf := config.NewConfig()
+	conf.Graph.CacheDuration = cacheDuration
+	config.Set(conf)
+}
+
+func mockCacheOptions(queryTime int64, accessibleNamespaces ...string) graph.Options {
+	o := graph.Options{TelemetryVendor: graph.VendorIstio}
+	o.TelemetryOptions.GraphType = graph.GraphTypeWorkload
+	o.TelemetryOptions.QueryTime = queryTime
+	o.TelemetryOptions.Duration = 10 * time.Minute
+	o.TelemetryOptions.Appenders = graph.RequestedAppenders{All: true}
+	o.TelemetryOptions.Params = url.Values{"queryTime": []string{"ignored"}}
+	o.Namespaces = graph.NamespaceInfoMap{"bookinfo": graph.NamespaceInfo{Name: "bookinfo", Duration: 10 * time.Minute}}
+	o.UserKey = "user"
+	o.AccessibleNamespaces = map[string]time.Time{}
+	for _, ns := range accessibleNamespaces {
+		o.AccessibleNamespaces[ns] = time.Time{}
+	}
+	return o
+}
+
+// mockCacheTrafficMap returns bookinfo/productpage -> other/details, where details has config-based metadata
+func mockCacheTrafficMap() graph.TrafficMap {
+	trafficMap := graph.NewTrafficMap()
+	productpage := graph.NewNode("east", "bookinfo", "productpage", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeWorkload)
+	details := graph.NewNode("east", "other", "details", "other", "details-v1", "details", "v1", graph.GraphTypeWorkload)
+	trafficMap[productpage.ID] = &productpage
+	trafficMap[details.ID] = &details
+
+	e := productpage.AddEdge(&details)
+	e.Metadata[graph.ProtocolKey] = "http"
+	graph.AddToMetadata("http", 10.0, "200", "-", "", productpage.Metadata, details.Metadata, e.Metadata)
+	details.Metadata[graph.HasCB] = true
+	details.Metadata[graph.IsOutside] = true
+	return trafficMap
+}
+
+func TestGraphCacheKey(t *testing.T) {
+	assert := assert.New(t)
+
+	o1 := mockCacheOptions(1000, "bookinfo")
+	o2 := mockCacheOptions(1009, "bookinfo")
+	o2.TelemetryOptions.Params = url.Values{"boxBy": []string{"app"}, "maxNodes": []string{"5"}}
+
+	// same window, normalized params are ignored
+	assert.Equal(cacheKey(o1, 10*time.Second), cacheKey(o2, 10*time.Second))
+
+	o2.TelemetryOptions.QueryTime = 1010
+	assert.NotEqual(cacheKey(o1, 10*time.Second), cacheKey(o2, 10*time.Second))
+
+	o2 = mockCacheOptions(1000, "bookinfo")
+	o2.TelemetryOptions.Params.Set("responseTime", "99")
+	assert.NotEqual(cacheKey(o1, 10*time.Second), cacheKey(o2, 10*time.Second))
+
+	o2 = mockCacheOptions(1000, "bookinfo")
+	o2.TelemetryOptions.Appenders = graph.RequestedAppenders{All: false, AppenderNames: []string{"deadNode"}}
+	assert.NotEqual(cacheKey(o1, 10*time.Second), cacheKey(o2, 10*time.Second))
+
+	// boxing and collapsing are applied after the cache
+	o1.ConfigOptions.BoxBy = "label:team,label:tier"
+	o1.ConfigOptions.MaxNodes = 5
+	o2 = mockCacheOptions(1000, "bookinfo")
+	assert.Equal(cacheKey(o1, 10*time.Second), cacheKey(o2, 10*time.Second))
+
+	// the user's credentials and accessible namespaces matter
+	o2 = mockCacheOptions(1000, "bookinfo", "other")
+	assert.NotEqual(cacheKey(o1, 10*time.Second), cacheKey(o2, 10*time.Second))
+
+	o2 = mockCacheOptions(1000, "bookinfo")
+	o2.UserKey = "other"
+	assert.NotEqual(cacheKey(o1, 10*time.Second), cacheKey(o2, 10*time.Second))
+}
+
+func TestGraphCacheGetOrBuild(t *testing.T) {
+	assert := assert.New(t)
+	setupGraphCache(10)
+
+	builds := 0
+	build := func() graph.TrafficMap {
+		builds++
+		return mockCacheTrafficMap()
+	}
+	cache := newTrafficMapCache()
+
+	trafficMap, o := cache.getOrBuild(mockCacheOptions(1000, "bookinfo", "other"), build)
+	assert.Equal(1, builds)
+	assert.Equal(int64(1000), o.ConfigOptions.QueryTime)
+	details := trafficMap["wl_east_other_details-v1"]
+	assert.Equal(true, details.Metadata[graph.HasCB])
+
+	// the same user shares the build, and gets a copy of the cached TrafficMap
+	delete(details.Metadata, graph.HasCB)
+	trafficMap, o = cache.getOrBuild(mockCacheOptions(1005, "bookinfo", "other"), build)
+	assert.Equal(1, builds)
+	assert.Equal(int64(1000), o.ConfigOptions.QueryTime)
+	assert.Equal(int64(1000), o.TelemetryOptions.QueryTime)
+	details = trafficMap["wl_east_other_details-v1"]
+	assert.Equal(true, details.Metadata[graph.HasCB])
+	productpage := trafficMap["wl_east_bookinfo_productpage-v1"]
+	assert.Equal(details, productpage.Edges[0].Dest)
+	assert.Equal(productpage, productpage.Edges[0].Source)
+
+	// a user with other access, or other credentials, requires a new build
+	cache.getOrBuild(mockCacheOptions(1001, "bookinfo"), build)
+	assert.Equal(2, builds)
+	o = mockCacheOptions(1001, "bookinfo", "other")
+	o.UserKey = "other"
+	cache.getOrBuild(o, build)
+	assert.Equal(3, builds)
+
+	// a new window requires a new build
+	_, o = cache.getOrBuild(mockCacheOptions(1010, "bookinfo", "other"), build)
+	assert.Equal(4, builds)
+	assert.Equal(int64(1010), o.ConfigOptions.QueryTime)
+}
+
+func TestGraphCacheBuildPanic(t *testing.T) {
+	assert := assert.New(t)
+	setupGraphCache(10)
+
+	cache := newTrafficMapCache()
+	defer func() {
+		r := recover()
+		assert.Equal(graph.Response{Message: "bad graph", Code: 400}, r)
+		assert.Empty(cache.entries)
+	}()
+
+	cache.getOrBuild(mockCacheOptions(1000, "bookinfo"), func() graph.TrafficMap {
+		graph.BadRequest("bad graph")
+		return nil
+	})
+}
+
+func TestGraphCacheDisabled(t *testing.T) {
+	assert := assert.New(t)
+	setupGraphCache(0)
+
+	builds := 0
+	cache := newTrafficMapCache()
+	for i := 0; i < 2; i++ {
+		cache.getOrBuild(mockCacheOptions(1000, "bookinfo"), func() graph.TrafficMap {
+			builds++
+			return mockCacheTrafficMap()
+		})
+	}
+	assert.Equal(2, builds)
+	assert.Empty(cache.entries)
+}
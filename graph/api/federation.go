@@ -0,0 +1,166 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/telemetry"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus"
+)
+
+// federatedClient is the Prometheus client of a remote cluster
+type federatedClient struct {
+	cluster string
+	prom    *prometheus.Client
+}
+
+// federatedClientsCache holds the Prometheus clients of the remote clusters. They are built again only when
+// the federation config or the Istio remote secrets change.
+var federatedClientsCache struct {
+	sync.Mutex
+	key     string
+	clients []federatedClient
+}
+
+// buildNamespacesTrafficMap builds the namespaces TrafficMap from the home Prometheus or, in federation mode,
+// from the Prometheus of every cluster.
+func buildNamespacesTrafficMap(business *business.Layer, vendor graph.TelemetryVendor, prom *prometheus.Client, o graph.TelemetryOptions, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	cfg := config.Get().Graph.Federation
+	if !cfg.Enabled {
+		return vendor.BuildNamespacesTrafficMap(o, prom, globalInfo)
+	}
+	timeout := time.Duration(cfg.RemoteTimeout) * time.Second
+	return buildFederatedTrafficMap(vendor, prom, getFederatedClients(business), o, globalInfo, timeout)
+}
+
+// buildFederatedTrafficMap builds a namespaces TrafficMap per cluster and merges them. The nodes keep their
+// cluster, and the edges between clusters are marked. The remote clusters are queried concurrently, and a
+// remote cluster failing to provide its TrafficMap within the timeout is skipped. The home cluster must succeed.
+func buildFederatedTrafficMap(vendor graph.TelemetryVendor, prom *prometheus.Client, remoteClients []federatedClient, o graph.TelemetryOptions, globalInfo *graph.AppenderGlobalInfo, timeout time.Duration) graph.TrafficMap {
+	remoteTrafficMaps := make([]graph.TrafficMap, len(remoteClients))
+	wg := sync.WaitGroup{}
+	for i, remoteClient := range remoteClients {
+		// the appender global info caches the Prometheus client, each cluster needs its own
+		remoteGlobalInfo := graph.NewAppenderGlobalInfo()
+		remoteGlobalInfo.Business = globalInfo.Business
+		remoteGlobalInfo.HomeCluster = globalInfo.HomeCluster
+		remoteGlobalInfo.PromClient = remoteClient.prom
+
+		wg.Add(1)
+		go func(i int, remoteClient federatedClient) {
+			defer wg.Done()
+			remoteTrafficMaps[i] = buildRemoteTrafficMap(vendor, remoteClient, o, remoteGlobalInfo, timeout)
+		}(i, remoteClient)
+	}
+
+	trafficMap := vendor.BuildNamespacesTrafficMap(o, prom, globalInfo)
+
+	wg.Wait()
+	for _, remoteTrafficMap := range remoteTrafficMaps {
+		telemetry.MergeTrafficMaps(trafficMap, "", remoteTrafficMap)
+	}
+
+	telemetry.MarkCrossClusterEdges(trafficMap)
+
+	return trafficMap
+}
+
+// buildRemoteTrafficMap returns the remote cluster's namespaces TrafficMap, or an empty TrafficMap on failure
+// or when it is not provided within the timeout
+func buildRemoteTrafficMap(vendor graph.TelemetryVendor, remoteClient federatedClient, o graph.TelemetryOptions, globalInfo *graph.AppenderGlobalInfo, timeout time.Duration) graph.TrafficMap {
+	// buffered, a build finishing after the timeout must not block
+	result := make(chan graph.TrafficMap, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Warningf("Unable to federate the graph of cluster [%s]: %v", remoteClient.cluster, r)
+				result <- graph.NewTrafficMap()
+			}
+		}()
+		result <- vendor.BuildNamespacesTrafficMap(o, remoteClient.prom, globalInfo)
+	}()
+
+	select {
+	case trafficMap := <-result:
+		return trafficMap
+	case <-time.After(timeout):
+		log.Warningf("Unable to federate the graph of cluster [%s]: timed out after %v", remoteClient.cluster, timeout)
+		return graph.NewTrafficMap()
+	}
+}
+
+// getFederatedClients returns the Prometheus clients of the configured remote clusters and, if discovery is
+// enabled, of the clusters discovered from the Istio remote secrets. A configured cluster takes precedence
+// over a discovered cluster of the same name. The clients are cached until the config or the secrets change.
+func getFederatedClients(business *business.Layer) []federatedClient {
+	cfg := config.Get().Graph.Federation
+
+	key := fmt.Sprintf("%+v", cfg)
+	if cfg.Discover {
+		secretsVersion, err := business.Mesh.GetRemoteSecretsVersion()
+		if err != nil {
+			log.Warningf("Unable to discover the Prometheus of the remote clusters: %v", err)
+			clients, _ := newFederatedClients(business, cfg, false)
+			return clients
+		}
+		key += secretsVersion
+	}
+
+	federatedClientsCache.Lock()
+	defer federatedClientsCache.Unlock()
+
+	if federatedClientsCache.clients != nil && federatedClientsCache.key == key {
+		return federatedClientsCache.clients
+	}
+
+	clients, ok := newFederatedClients(business, cfg, cfg.Discover)
+	if ok {
+		federatedClientsCache.key = key
+		federatedClientsCache.clients = clients
+	}
+	return clients
+}
+
+// newFederatedClients creates the Prometheus clients of the remote clusters. It returns false when the
+// discovery failed, the clients then only include the configured clusters.
+func newFederatedClients(business *business.Layer, cfg config.GraphFederationConfig, discover bool) ([]federatedClient, bool) {
+	clients := []federatedClient{}
+	configured := make(map[string]bool, len(cfg.Clusters))
+	for _, cluster := range cfg.Clusters {
+		prom, err := prometheus.NewClientForConfig(cluster.Prometheus)
+		if err != nil {
+			log.Warningf("Unable to create the Prometheus client of federated cluster [%s]: %v", cluster.Name, err)
+			continue
+		}
+		configured[cluster.Name] = true
+		clients = append(clients, federatedClient{cluster: cluster.Name, prom: prom})
+	}
+
+	if !discover {
+		return clients, true
+	}
+
+	remotePrometheus, err := business.Mesh.GetRemotePrometheus(cfg.PrometheusService)
+	if err != nil {
+		log.Warningf("Unable to discover the Prometheus of the remote clusters: %v", err)
+		return clients, false
+	}
+	for _, remote := range remotePrometheus {
+		if configured[remote.Cluster] {
+			continue
+		}
+		prom, err := prometheus.NewClientForRestConfig(remote.URL, remote.RestConfig)
+		if err != nil {
+			log.Warningf("Unable to create the Prometheus client of discovered cluster [%s]: %v", remote.Cluster, err)
+			continue
+		}
+		clients = append(clients, federatedClient{cluster: remote.Cluster, prom: prom})
+	}
+
+	return clients, true
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+	"github.com/kiali/kiali/prometheus"
+)
+
+// mockFederationVendor returns the TrafficMap mocked for the Prometheus client, after its delay, panicking for
+// an unknown client
+type mockFederationVendor struct {
+	delays      map[*prometheus.Client]time.Duration
+	trafficMaps map[*prometheus.Client]graph.TrafficMap
+}
+
+func (v mockFederationVendor) Name() string {
+	return "mock"
+}
+
+func (v mockFederationVendor) Appenders() []string {
+	return []string{}
+}
+
+func (v mockFederationVendor) BuildNamespacesTrafficMap(o graph.TelemetryOptions, client *prometheus.Client, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	time.Sleep(v.delays[client])
+	trafficMap, ok := v.trafficMaps[client]
+	if !ok {
+		graph.Error("Prometheus unavailable")
+	}
+	if globalInfo.PromClient != nil && globalInfo.PromClient != client {
+		graph.Error("Unexpected Prometheus client")
+	}
+	return trafficMap
+}
+
+func (v mockFederationVendor) BuildNodeTrafficMap(o graph.TelemetryOptions, client *prometheus.Client, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	return graph.NewTrafficMap()
+}
+
+func TestBuildFederatedTrafficMap(t *testing.T) {
+	assert := assert.New(t)
+
+	newNode := func(trafficMap graph.TrafficMap, cluster, workload string) *graph.Node {
+		n := graph.NewNode(cluster, "bookinfo", "", "bookinfo", workload, workload, "v1", graph.GraphTypeWorkload)
+		trafficMap[n.ID] = &n
+		return &n
+	}
+	addEdge := func(source, dest *graph.Node, val float64) {
+		e := source.AddEdge(dest)
+		e.Metadata[graph.ProtocolKey] = graph.HTTP.Name
+		graph.AddToMetadata(graph.HTTP.Name, val, "200", "-", "", source.Metadata, dest.Metadata, e.Metadata)
+	}
+
+	// east: productpage -> east reviews, and the cross-cluster productpage -> west reviews
+	eastProm, westProm, failingProm := &prometheus.Client{}, &prometheus.Client{}, &prometheus.Client{}
+	east := graph.NewTrafficMap()
+	eastProductpage := newNode(east, "east", "productpage")
+	addEdge(eastProductpage, newNode(east, "east", "reviews"), 10.0)
+	addEdge(eastProductpage, newNode(east, "west", "reviews"), 5.0)
+
+	// west: the cross-cluster edge, reported as well, and west reviews -> ratings
+	west := graph.NewTrafficMap()
+	westProductpage := newNode(west, "east", "productpage")
+	westReviews := newNode(west, "west", "reviews")
+	addEdge(westProductpage, westReviews, 5.0)
+	addEdge(westReviews, newNode(west, "west", "ratings"), 5.0)
+
+	// south: too slow, skipped
+	southProm := &prometheus.Client{}
+	south := graph.NewTrafficMap()
+	newNode(south, "south", "details")
+
+	vendor := mockFederationVendor{
+		delays:      map[*prometheus.Client]time.Duration{southProm: 10 * time.Second},
+		trafficMaps: map[*prometheus.Client]graph.TrafficMap{eastProm: east, westProm: west, southProm: south},
+	}
+	remoteClients := []federatedClient{{cluster: "west", prom: westProm}, {cluster: "north", prom: failingProm}, {cluster: "south", prom: southProm}}
+
+	globalInfo := graph.NewAppenderGlobalInfo()
+	trafficMap := buildFederatedTrafficMap(vendor, eastProm, remoteClients, graph.TelemetryOptions{}, globalInfo, 200*time.Millisecond)
+
+	assert.Equal(4, len(trafficMap))
+	clusters := map[string]int{}
+	crossCluster := 0
+	edges := 0
+	for _, n := range trafficMap {
+		clusters[n.Cluster]++
+		for _, e := range n.Edges {
+			edges++
+			if e.Metadata[graph.IsCrossCluster] == true {
+				crossCluster++
+				assert.Equal("east", n.Cluster)
+				assert.Equal("west", e.Dest.Cluster)
+			}
+		}
+	}
+	assert.Equal(map[string]int{"east": 2, "west": 2}, clusters)
+	assert.Equal(3, edges)
+	assert.Equal(1, crossCluster)
+	// the cross-cluster edge is not duplicated
+	assert.Equal(15.0, trafficMap[eastProductpage.ID].Metadata[graph.MetadataKey("httpOut")])
+}
+
+func TestGetFederatedClientsIsCached(t *testing.T) {
+	assert := assert.New(t)
+
+	conf := config.NewConfig()
+	conf.Graph.Federation.Enabled = true
+	conf.Graph.Federation.Discover = true
+	conf.Graph.Federation.Clusters = []config.GraphFederationCluster{{Name: "west", Prometheus: config.PrometheusConfig{URL: "http://prometheus.west:9090"}}}
+	config.Set(conf)
+
+	secret := core_v1.Secret{ObjectMeta: meta_v1.ObjectMeta{Name: "istio-remote-secret-east", ResourceVersion: "1"}}
+	k8s := kubetest.NewK8SClientMock()
+	k8s.On("IsOpenShift").Return(false)
+	k8s.On("GetSecrets", conf.IstioNamespace, "istio/multiCluster=true").Return([]core_v1.Secret{secret}, nil).Times(3)
+	businessLayer := business.NewWithBackends(k8s, nil, nil)
+
+	// the first request builds the clients, reading the secrets twice: for their version and to discover the clusters
+	clients := getFederatedClients(businessLayer)
+	assert.Len(clients, 1)
+	assert.Equal("west", clients[0].cluster)
+
+	// the next request only reads the version of the secrets, unchanged
+	assert.Equal(clients, getFederatedClients(businessLayer))
+	k8s.AssertNumberOfCalls(t, "GetSecrets", 3)
+
+	// an updated secret rebuilds the clients
+	secret.ResourceVersion = "2"
+	k8s.On("GetSecrets", conf.IstioNamespace, "istio/multiCluster=true").Return([]core_v1.Secret{secret}, nil)
+	rebuilt := getFederatedClients(businessLayer)
+	assert.Len(rebuilt, 1)
+	assert.NotSame(clients[0].prom, rebuilt[0].prom)
+	k8s.AssertNumberOfCalls(t, "GetSecrets", 5)
+}
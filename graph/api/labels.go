@@ -0,0 +1,114 @@
+package api
+
+import (
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+)
+
+// addBoxByLabels sets the Labels metadata of the workload, app and service nodes, holding the node's values
+// for the label keys requested for boxing. A workload or service node has the labels of its definition, an app
+// node has the labels shared by the app's workloads. The nodes of a namespace whose definitions can't be fetched
+// (e.g. an inaccessible namespace) are not labeled, and so are not boxed.
+func addBoxByLabels(business *business.Layer, trafficMap graph.TrafficMap, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	workloadLists := make(map[string]*models.WorkloadList)
+	serviceLists := make(map[string]*models.ServiceDefinitionList)
+
+	getWorkloads := func(namespace string) []models.WorkloadListItem {
+		workloadList, ok := workloadLists[namespace]
+		if !ok {
+			list, err := business.Workload.GetWorkloadList(namespace, false)
+			if err != nil {
+				log.Debugf("Unable to fetch workload labels for boxing, namespace [%s]: %v", namespace, err)
+			} else {
+				workloadList = &list
+			}
+			workloadLists[namespace] = workloadList
+		}
+		if workloadList == nil {
+			return nil
+		}
+		return workloadList.Workloads
+	}
+
+	getServices := func(namespace string) []models.ServiceDetails {
+		serviceList, ok := serviceLists[namespace]
+		if !ok {
+			list, err := business.Svc.GetServiceDefinitionList(namespace)
+			if err != nil {
+				log.Debugf("Unable to fetch service labels for boxing, namespace [%s]: %v", namespace, err)
+			}
+			serviceList = list
+			serviceLists[namespace] = serviceList
+		}
+		if serviceList == nil {
+			return nil
+		}
+		return serviceList.ServiceDefinitions
+	}
+
+	cfg := config.Get()
+	appLabel := cfg.IstioLabels.AppLabelName
+	versionLabel := cfg.IstioLabels.VersionLabelName
+
+	for _, n := range trafficMap {
+		if !graph.IsOK(n.Namespace) {
+			continue
+		}
+
+		var labels map[string]string
+		switch n.NodeType {
+		case graph.NodeTypeWorkload:
+			for _, w := range getWorkloads(n.Namespace) {
+				if w.Name == n.Workload {
+					labels = selectLabels(w.Labels, keys)
+					break
+				}
+			}
+		case graph.NodeTypeApp:
+			for _, w := range getWorkloads(n.Namespace) {
+				if w.Labels[appLabel] != n.App || (graph.IsOKVersion(n.Version) && w.Labels[versionLabel] != n.Version) {
+					continue
+				}
+				workloadLabels := selectLabels(w.Labels, keys)
+				if labels == nil {
+					labels = workloadLabels
+					continue
+				}
+				for k, v := range labels {
+					if workloadLabels[k] != v {
+						delete(labels, k)
+					}
+				}
+			}
+		case graph.NodeTypeService:
+			for _, s := range getServices(n.Namespace) {
+				if s.Service.Name == n.Service {
+					labels = selectLabels(s.Service.Labels, keys)
+					break
+				}
+			}
+		}
+
+		if len(labels) > 0 {
+			n.Metadata[graph.Labels] = labels
+		}
+	}
+}
+
+// selectLabels returns the labels for the requested keys
+func selectLabels(labels map[string]string, keys []string) map[string]string {
+	result := make(map[string]string)
+	for _, k := range keys {
+		if v, ok := labels[k]; ok {
+			result[k] = v
+		}
+	}
+	return result
+}
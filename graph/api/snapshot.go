@@ -0,0 +1,142 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/graph/snapshot"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus"
+)
+
+// SaveGraphSnapshot generates a namespaces graph using the provided options and saves it as a snapshot,
+// applying the configured retention limits. It returns the saved snapshot's info. A snapshot can be replayed
+// by any user with access to its namespaces, so it is generated as if only those namespaces were accessible:
+// the nodes outside of them are saved as inaccessible, without their config-based details.
+func SaveGraphSnapshot(business *business.Layer, o graph.SnapshotOptions) (code int, info interface{}) {
+	vendor := getTelemetryVendor(o.TelemetryVendor)
+	prom, err := prometheus.NewClient()
+	graph.CheckError(err)
+
+	return saveGraphSnapshot(business, vendor, prom, getSnapshotStore(), o)
+}
+
+// saveGraphSnapshot provides a test hook that accepts a mock vendor, clients and store
+func saveGraphSnapshot(business *business.Layer, vendor graph.TelemetryVendor, prom *prometheus.Client, store snapshot.Store, o graph.SnapshotOptions) (code int, info interface{}) {
+	accessibleNamespaces := make(map[string]time.Time, len(o.Namespaces))
+	for namespace := range o.Namespaces {
+		accessibleNamespaces[namespace] = o.AccessibleNamespaces[namespace]
+	}
+	o.AccessibleNamespaces = accessibleNamespaces
+
+	_, vendorConfig := graphNamespaces(business, vendor, prom, o.Options)
+
+	s, err := snapshot.NewSnapshot(o.Name, vendorConfig.(cytoscape.Config), o.Options)
+	graph.CheckError(err)
+	graph.CheckError(store.Save(s))
+
+	// a failure to remove old snapshots should not fail the save
+	cfg := config.Get().Graph.Snapshots
+	if err := snapshot.ApplyRetention(store, s.Info.Namespaces, cfg.MaxCount, time.Duration(cfg.MaxAge)*time.Second); err != nil {
+		log.Warningf("Unable to apply graph snapshot retention: %v", err)
+	}
+
+	return http.StatusOK, s.Info
+}
+
+// ListGraphSnapshots returns the info of the saved snapshots whose namespaces are all accessible to the
+// client, most recent first
+func ListGraphSnapshots(business *business.Layer) (code int, infos interface{}) {
+	return listGraphSnapshots(getSnapshotStore(), getAccessibleNamespaces(business))
+}
+
+// listGraphSnapshots provides a test hook that accepts a mock store
+func listGraphSnapshots(store snapshot.Store, accessibleNamespaces map[string]bool) (code int, infos interface{}) {
+	all, err := store.List()
+	graph.CheckError(err)
+
+	result := []snapshot.Info{}
+	for _, info := range all {
+		if isAccessible(info.Namespaces, accessibleNamespaces) {
+			result = append(result, info)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Created.After(result[j].Created)
+	})
+
+	return http.StatusOK, result
+}
+
+// GetGraphSnapshot returns a saved snapshot, for replay. All of the snapshot's namespaces must be accessible
+// to the client.
+func GetGraphSnapshot(business *business.Layer, id string) (code int, s interface{}) {
+	return getGraphSnapshot(getSnapshotStore(), getAccessibleNamespaces(business), id)
+}
+
+// getGraphSnapshot provides a test hook that accepts a mock store
+func getGraphSnapshot(store snapshot.Store, accessibleNamespaces map[string]bool, id string) (code int, s interface{}) {
+	if err := snapshot.ValidateID(id); err != nil {
+		graph.BadRequest(err.Error())
+	}
+
+	saved, err := store.Get(id)
+	if err == snapshot.ErrNotFound {
+		graph.NotFound(fmt.Sprintf("Graph snapshot [%s] not found", id))
+	}
+	graph.CheckError(err)
+
+	if !isAccessible(saved.Info.Namespaces, accessibleNamespaces) {
+		graph.Forbidden(fmt.Sprintf("Graph snapshot [%s] includes namespaces that are not accessible", id))
+	}
+
+	return http.StatusOK, saved
+}
+
+// getSnapshotStore returns the configured snapshot store. The configmap store uses the Kiali service account,
+// users are not expected to manage the ConfigMaps of the Kiali deployment namespace.
+func getSnapshotStore() snapshot.Store {
+	var client kubernetes.ClientInterface
+	if config.Get().Graph.Snapshots.Store == snapshot.StoreConfigMap {
+		clientFactory, err := kubernetes.GetClientFactory()
+		graph.CheckError(err)
+		kialiToken, err := kubernetes.GetKialiToken()
+		graph.CheckError(err)
+		client, err = clientFactory.GetClient(&api.AuthInfo{Token: kialiToken})
+		graph.CheckError(err)
+	}
+
+	store, err := snapshot.NewStore(client)
+	graph.CheckError(err)
+	return store
+}
+
+// getAccessibleNamespaces returns the names of the namespaces accessible to the client
+func getAccessibleNamespaces(business *business.Layer) map[string]bool {
+	namespaces, err := business.Namespace.GetNamespaces()
+	graph.CheckError(err)
+
+	accessibleNamespaces := make(map[string]bool, len(namespaces))
+	for _, namespace := range namespaces {
+		accessibleNamespaces[namespace.Name] = true
+	}
+	return accessibleNamespaces
+}
+
+func isAccessible(namespaces []string, accessibleNamespaces map[string]bool) bool {
+	for _, namespace := range namespaces {
+		if !accessibleNamespaces[namespace] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/graph/snapshot"
+	"github.com/kiali/kiali/prometheus"
+)
+
+// mockSnapshotVendor records the accessible namespaces of the generated graph
+type mockSnapshotVendor struct {
+	accessibleNamespaces map[string]time.Time
+}
+
+func (v *mockSnapshotVendor) Name() string {
+	return "mock"
+}
+
+func (v *mockSnapshotVendor) Appenders() []string {
+	return []string{}
+}
+
+func (v *mockSnapshotVendor) BuildNamespacesTrafficMap(o graph.TelemetryOptions, client *prometheus.Client, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	v.accessibleNamespaces = o.AccessibleNamespaces
+	return graph.NewTrafficMap()
+}
+
+func (v *mockSnapshotVendor) BuildNodeTrafficMap(o graph.TelemetryOptions, client *prometheus.Client, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	return graph.NewTrafficMap()
+}
+
+func TestGraphSnapshots(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "graph-snapshots")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	store := snapshot.NewFileStore(dir)
+
+	save := func(name string, created time.Time, namespaces ...string) *snapshot.Snapshot {
+		o := mockCacheOptions(1000)
+		o.Namespaces = graph.NamespaceInfoMap{}
+		for _, ns := range namespaces {
+			o.Namespaces[ns] = graph.NamespaceInfo{Name: ns}
+		}
+		s, err := snapshot.NewSnapshot(name, cytoscape.Config{}, o)
+		assert.NoError(err)
+		s.Info.Created = created
+		assert.NoError(store.Save(s))
+		return s
+	}
+	now := time.Now()
+	older := save("older", now.Add(-time.Hour), "bookinfo")
+	newer := save("newer", now, "bookinfo", "tutorial")
+	restricted := save("restricted", now, "bookinfo", "secret")
+
+	accessible := map[string]bool{"bookinfo": true, "tutorial": true}
+
+	// only snapshots of accessible namespaces, most recent first
+	code, infos := listGraphSnapshots(store, accessible)
+	assert.Equal(http.StatusOK, code)
+	assert.Equal(2, len(infos.([]snapshot.Info)))
+	assert.Equal(newer.Info.ID, infos.([]snapshot.Info)[0].ID)
+	assert.Equal(older.Info.ID, infos.([]snapshot.Info)[1].ID)
+
+	code, saved := getGraphSnapshot(store, accessible, older.Info.ID)
+	assert.Equal(http.StatusOK, code)
+	assert.Equal("older", saved.(*snapshot.Snapshot).Info.Name)
+
+	assertPanic := func(code int, id string) {
+		defer func() {
+			r := recover()
+			assert.Equal(code, r.(graph.Response).Code, id)
+		}()
+		getGraphSnapshot(store, accessible, id)
+	}
+	assertPanic(http.StatusForbidden, restricted.Info.ID)
+	assertPanic(http.StatusNotFound, "1000-missing")
+	assertPanic(http.StatusBadRequest, "../snapshot")
+}
+
+func TestSaveGraphSnapshot(t *testing.T) {
+	assert := assert.New(t)
+
+	conf := config.NewConfig()
+	conf.Graph.CacheEnabled = false
+	config.Set(conf)
+
+	dir, err := ioutil.TempDir("", "graph-snapshots")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	store := snapshot.NewFileStore(dir)
+
+	o := graph.SnapshotOptions{Name: "incident", Options: mockCacheOptions(1000, "bookinfo", "other")}
+	o.ConfigVendor = graph.VendorCytoscape
+	vendor := &mockSnapshotVendor{}
+
+	// the graph is generated as if only the snapshot namespaces were accessible
+	code, info := saveGraphSnapshot(nil, vendor, nil, store, o)
+	assert.Equal(http.StatusOK, code)
+	assert.Equal([]string{"bookinfo"}, info.(snapshot.Info).Namespaces)
+	assert.Equal(map[string]time.Time{"bookinfo": {}}, vendor.accessibleNamespaces)
+}
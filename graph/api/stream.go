@@ -0,0 +1,331 @@
+package api
+
+// Stream.go provides streaming graph support. Subscribers with identical graph options and credentials share
+// a single graph stream, which regenerates the graph on the requested interval and pushes the changes to all
+// subscribers. A new subscriber first receives the most recent full graph, and then deltas. Streams are not
+// shared between users, the graph is generated with the credentials of the subscribers.
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/log"
+)
+
+// The supported stream event types
+const (
+	StreamEventDelta string = "delta" // a GraphDelta
+	StreamEventError string = "error" // an error message
+	StreamEventGraph string = "graph" // a full cytoscape.Config
+)
+
+// subscriberBuffer is the number of events that can be pending for a subscriber. A subscriber
+// that falls further behind is dropped, it can not be sent consistent deltas.
+const subscriberBuffer = 10
+
+// GraphStreamEvent is a single event pushed to a stream subscriber
+type GraphStreamEvent struct {
+	Type string
+	Data interface{}
+}
+
+// GraphDelta holds the changes between two consecutive graphs of a stream. Nodes and edges
+// are identified by their cytoscape IDs.
+type GraphDelta struct {
+	Timestamp    int64                    `json:"timestamp"`
+	Duration     int64                    `json:"duration"`
+	GraphType    string                   `json:"graphType"`
+	AddedNodes   []*cytoscape.NodeWrapper `json:"addedNodes"`
+	UpdatedNodes []*cytoscape.NodeWrapper `json:"updatedNodes"`
+	RemovedNodes []string                 `json:"removedNodes"`
+	AddedEdges   []*cytoscape.EdgeWrapper `json:"addedEdges"`
+	UpdatedEdges []*cytoscape.EdgeWrapper `json:"updatedEdges"`
+	RemovedEdges []string                 `json:"removedEdges"`
+}
+
+// GraphStreamSubscription is returned to a stream subscriber. Events is closed if the subscriber
+// is dropped for falling behind.
+type GraphStreamSubscription struct {
+	Events <-chan GraphStreamEvent
+	events chan GraphStreamEvent
+	stream *graphStream
+}
+
+// graphGenerator generates the graph for a stream refresh
+type graphGenerator func(business *business.Layer, o graph.Options) cytoscape.Config
+
+// GraphStreamHub manages the active graph streams
+type GraphStreamHub struct {
+	generate graphGenerator
+	mutex    sync.Mutex
+	streams  map[string]*graphStream // key=streamKey
+}
+
+type graphStream struct {
+	business    *business.Layer // of the most recent subscriber, all subscribers share the same credentials
+	current     *cytoscape.Config
+	hub         *GraphStreamHub
+	key         string
+	mutex       sync.Mutex
+	options     graph.StreamOptions
+	subscribers map[*GraphStreamSubscription]bool
+}
+
+var graphStreamHub = NewGraphStreamHub(generateCytoscapeGraph)
+
+// NewGraphStreamHub returns a hub generating stream graphs with the provided generator
+func NewGraphStreamHub(generate graphGenerator) *GraphStreamHub {
+	return &GraphStreamHub{
+		generate: generate,
+		streams:  make(map[string]*graphStream),
+	}
+}
+
+// SubscribeGraphStream subscribes to the namespaces graph stream for the provided options, starting the stream as needed
+func SubscribeGraphStream(business *business.Layer, o graph.StreamOptions) *GraphStreamSubscription {
+	return graphStreamHub.Subscribe(business, o)
+}
+
+// UnsubscribeGraphStream ends the subscription
+func UnsubscribeGraphStream(subscription *GraphStreamSubscription) {
+	graphStreamHub.Unsubscribe(subscription)
+}
+
+// Subscribe subscribes to the graph stream for the provided options, starting the stream as needed
+func (h *GraphStreamHub) Subscribe(business *business.Layer, o graph.StreamOptions) *GraphStreamSubscription {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	key := streamKey(o)
+	stream, found := h.streams[key]
+	if !found {
+		stream = &graphStream{
+			hub:         h,
+			key:         key,
+			options:     o,
+			subscribers: make(map[*GraphStreamSubscription]bool),
+		}
+		h.streams[key] = stream
+	}
+
+	events := make(chan GraphStreamEvent, subscriberBuffer)
+	subscription := &GraphStreamSubscription{Events: events, events: events, stream: stream}
+
+	stream.mutex.Lock()
+	stream.business = business
+	stream.subscribers[subscription] = true
+	if stream.current != nil {
+		events <- GraphStreamEvent{Type: StreamEventGraph, Data: *stream.current}
+	}
+	stream.mutex.Unlock()
+
+	if !found {
+		log.Debugf("Starting graph stream [%s]", key)
+		go stream.run()
+	}
+
+	return subscription
+}
+
+// Unsubscribe ends the subscription. The stream itself stops on its next refresh if it has no
+// remaining subscribers, this allows a disconnected client to re-subscribe without a new stream.
+func (h *GraphStreamHub) Unsubscribe(subscription *GraphStreamSubscription) {
+	stream := subscription.stream
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	stream.drop(subscription)
+}
+
+// streamCount returns the number of active streams
+func (h *GraphStreamHub) streamCount() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return len(h.streams)
+}
+
+// streamKey returns a key identifying the stream. The options are fully determined by the query params
+// and the subscriber's credentials, and the namespaces accessible to them, which may affect the generated graph.
+func streamKey(o graph.StreamOptions) string {
+	params := url.Values{}
+	for k, v := range o.TelemetryOptions.Params {
+		params[k] = v
+	}
+	params.Del("queryTime")
+
+	accessibleNamespaces := make([]string, 0, len(o.AccessibleNamespaces))
+	for namespace := range o.AccessibleNamespaces {
+		accessibleNamespaces = append(accessibleNamespaces, namespace)
+	}
+	sort.Strings(accessibleNamespaces)
+
+	return fmt.Sprintf("%s %s %v %s", o.RefreshInterval, params.Encode(), accessibleNamespaces, o.UserKey)
+}
+
+// run refreshes the stream on the requested interval until there are no subscribers
+func (s *graphStream) run() {
+	ticker := time.NewTicker(s.options.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		s.refresh()
+		<-ticker.C
+
+		if s.stopIfIdle() {
+			log.Debugf("Stopped graph stream [%s]", s.key)
+			return
+		}
+	}
+}
+
+// stopIfIdle removes the stream from the hub, and returns true, if it has no subscribers
+func (s *graphStream) stopIfIdle() bool {
+	s.hub.mutex.Lock()
+	defer s.hub.mutex.Unlock()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.subscribers) > 0 {
+		return false
+	}
+	delete(s.hub.streams, s.key)
+	return true
+}
+
+// refresh generates the graph, for the most recent time window, and pushes the changes to the subscribers
+func (s *graphStream) refresh() {
+	s.mutex.Lock()
+	business := s.business
+	s.mutex.Unlock()
+
+	o := s.options.Options
+	o.ConfigOptions.QueryTime = time.Now().Unix()
+	o.TelemetryOptions.QueryTime = o.ConfigOptions.QueryTime
+
+	config, err := s.generate(business, o)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err != nil {
+		log.Errorf("Failed graph stream [%s] refresh: %v", s.key, err)
+		s.broadcast(GraphStreamEvent{Type: StreamEventError, Data: err.Error()})
+		return
+	}
+
+	if s.current == nil {
+		s.broadcast(GraphStreamEvent{Type: StreamEventGraph, Data: config})
+	} else {
+		s.broadcast(GraphStreamEvent{Type: StreamEventDelta, Data: newGraphDelta(s.current, &config)})
+	}
+	s.current = &config
+}
+
+// generate invokes the hub's generator, converting a graph panic into an error
+func (s *graphStream) generate(business *business.Layer, o graph.Options) (config cytoscape.Config, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			switch e := r.(type) {
+			case graph.Response:
+				err = fmt.Errorf("%s", e.Message)
+			case error:
+				err = e
+			case func() string:
+				err = fmt.Errorf("%s", e())
+			default:
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+
+	return s.hub.generate(business, o), nil
+}
+
+// broadcast sends the event to each subscriber, dropping any that have fallen behind. Call with the stream locked.
+func (s *graphStream) broadcast(event GraphStreamEvent) {
+	for subscription := range s.subscribers {
+		select {
+		case subscription.events <- event:
+		default:
+			log.Debugf("Dropping slow graph stream [%s] subscriber", s.key)
+			s.drop(subscription)
+		}
+	}
+}
+
+// drop removes the subscriber and closes its event channel. Call with the stream locked.
+func (s *graphStream) drop(subscription *GraphStreamSubscription) {
+	if _, ok := s.subscribers[subscription]; ok {
+		delete(s.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+// newGraphDelta returns the changes from the previous to the current graph
+func newGraphDelta(previous, current *cytoscape.Config) GraphDelta {
+	delta := GraphDelta{
+		Timestamp:    current.Timestamp,
+		Duration:     current.Duration,
+		GraphType:    current.GraphType,
+		AddedNodes:   []*cytoscape.NodeWrapper{},
+		UpdatedNodes: []*cytoscape.NodeWrapper{},
+		RemovedNodes: []string{},
+		AddedEdges:   []*cytoscape.EdgeWrapper{},
+		UpdatedEdges: []*cytoscape.EdgeWrapper{},
+		RemovedEdges: []string{},
+	}
+
+	previousNodes := make(map[string]*cytoscape.NodeData, len(previous.Elements.Nodes))
+	for _, nw := range previous.Elements.Nodes {
+		previousNodes[nw.Data.ID] = nw.Data
+	}
+	for _, nw := range current.Elements.Nodes {
+		if nd, ok := previousNodes[nw.Data.ID]; !ok {
+			delta.AddedNodes = append(delta.AddedNodes, nw)
+		} else if !reflect.DeepEqual(nd, nw.Data) {
+			delta.UpdatedNodes = append(delta.UpdatedNodes, nw)
+		}
+		delete(previousNodes, nw.Data.ID)
+	}
+	for id := range previousNodes {
+		delta.RemovedNodes = append(delta.RemovedNodes, id)
+	}
+	sort.Strings(delta.RemovedNodes)
+
+	previousEdges := make(map[string]*cytoscape.EdgeData, len(previous.Elements.Edges))
+	for _, ew := range previous.Elements.Edges {
+		previousEdges[ew.Data.ID] = ew.Data
+	}
+	for _, ew := range current.Elements.Edges {
+		if ed, ok := previousEdges[ew.Data.ID]; !ok {
+			delta.AddedEdges = append(delta.AddedEdges, ew)
+		} else if !reflect.DeepEqual(ed, ew.Data) {
+			delta.UpdatedEdges = append(delta.UpdatedEdges, ew)
+		}
+		delete(previousEdges, ew.Data.ID)
+	}
+	for id := range previousEdges {
+		delta.RemovedEdges = append(delta.RemovedEdges, id)
+	}
+	sort.Strings(delta.RemovedEdges)
+
+	return delta
+}
+
+// generateCytoscapeGraph is the default graphGenerator, it generates a namespaces graph
+func generateCytoscapeGraph(business *business.Layer, o graph.Options) cytoscape.Config {
+	code, config := GraphNamespaces(business, o)
+	if code != http.StatusOK {
+		graph.Error(fmt.Sprintf("Failed to generate graph: %v", config))
+	}
+	return config.(cytoscape.Config)
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+)
+
+// mockGenerator returns a graph with the currently configured node IDs, and counts invocations
+type mockGenerator struct {
+	mutex   sync.Mutex
+	calls   int
+	nodeIDs []string
+}
+
+func (g *mockGenerator) setNodeIDs(nodeIDs ...string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.nodeIDs = nodeIDs
+}
+
+func (g *mockGenerator) callCount() int {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.calls
+}
+
+func (g *mockGenerator) generate(business *business.Layer, o graph.Options) cytoscape.Config {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.calls++
+
+	config := cytoscape.Config{Timestamp: o.TelemetryOptions.QueryTime, GraphType: o.TelemetryOptions.GraphType}
+	for _, id := range g.nodeIDs {
+		config.Elements.Nodes = append(config.Elements.Nodes, &cytoscape.NodeWrapper{Data: &cytoscape.NodeData{ID: id}})
+	}
+	return config
+}
+
+func mockStreamOptions(namespaces string) graph.StreamOptions {
+	o := graph.StreamOptions{RefreshInterval: 20 * time.Millisecond}
+	o.TelemetryOptions.GraphType = graph.GraphTypeWorkload
+	o.TelemetryOptions.Params = url.Values{"namespaces": []string{namespaces}, "queryTime": []string{"1"}}
+	o.AccessibleNamespaces = map[string]time.Time{"bookinfo": {}, "tutorial": {}}
+	return o
+}
+
+func nextEvent(t *testing.T, subscription *GraphStreamSubscription) GraphStreamEvent {
+	select {
+	case event, ok := <-subscription.Events:
+		assert.True(t, ok)
+		return event
+	case <-time.After(5 * time.Second):
+		assert.FailNow(t, "timed out waiting for stream event")
+	}
+	return GraphStreamEvent{}
+}
+
+func TestGraphStreamShared(t *testing.T) {
+	assert := assert.New(t)
+
+	generator := &mockGenerator{}
+	generator.setNodeIDs("a", "b")
+	hub := NewGraphStreamHub(generator.generate)
+
+	s1 := hub.Subscribe(nil, mockStreamOptions("bookinfo"))
+	event := nextEvent(t, s1)
+	assert.Equal(StreamEventGraph, event.Type)
+	assert.Len(event.Data.(cytoscape.Config).Elements.Nodes, 2)
+
+	// identical options share the stream, and get the most recent graph immediately
+	o := mockStreamOptions("bookinfo")
+	o.TelemetryOptions.Params.Set("queryTime", "2")
+	s2 := hub.Subscribe(nil, o)
+	event = nextEvent(t, s2)
+	assert.Equal(StreamEventGraph, event.Type)
+	assert.Equal(1, hub.streamCount())
+
+	// different options get a different stream
+	s3 := hub.Subscribe(nil, mockStreamOptions("tutorial"))
+	assert.Equal(StreamEventGraph, nextEvent(t, s3).Type)
+	assert.Equal(2, hub.streamCount())
+
+	// a different user gets a different stream
+	o = mockStreamOptions("bookinfo")
+	o.UserKey = "other"
+	s4 := hub.Subscribe(nil, o)
+	assert.Equal(StreamEventGraph, nextEvent(t, s4).Type)
+	assert.Equal(3, hub.streamCount())
+	hub.Unsubscribe(s3)
+	hub.Unsubscribe(s4)
+
+	generator.setNodeIDs("b", "c")
+	for _, s := range []*GraphStreamSubscription{s1, s2} {
+		for {
+			event = nextEvent(t, s)
+			assert.Equal(StreamEventDelta, event.Type)
+			if delta := event.Data.(GraphDelta); len(delta.AddedNodes) > 0 {
+				assert.Equal("c", delta.AddedNodes[0].Data.ID)
+				assert.Equal([]string{"a"}, delta.RemovedNodes)
+				assert.Empty(delta.UpdatedNodes)
+				break
+			}
+		}
+	}
+
+	hub.Unsubscribe(s1)
+	hub.Unsubscribe(s2)
+	assert.Eventually(func() bool { return hub.streamCount() == 0 }, 5*time.Second, 10*time.Millisecond)
+
+	calls := generator.callCount()
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(calls, generator.callCount())
+}
+
+func TestGraphStreamError(t *testing.T) {
+	assert := assert.New(t)
+
+	hub := NewGraphStreamHub(func(business *business.Layer, o graph.Options) cytoscape.Config {
+		graph.BadRequest("bad graph")
+		return cytoscape.Config{}
+	})
+
+	s := hub.Subscribe(nil, mockStreamOptions("bookinfo"))
+	event := nextEvent(t, s)
+	assert.Equal(StreamEventError, event.Type)
+	assert.Equal("bad graph", event.Data)
+	hub.Unsubscribe(s)
+}
+
+func TestGraphStreamSlowSubscriber(t *testing.T) {
+	assert := assert.New(t)
+
+	generator := &mockGenerator{}
+	hub := NewGraphStreamHub(generator.generate)
+
+	s := hub.Subscribe(nil, mockStreamOptions("bookinfo"))
+
+	// never read, the subscriber is dropped once its buffer is full
+	assert.Eventually(func() bool { return generator.callCount() > subscriberBuffer }, 5*time.Second, 10*time.Millisecond)
+	for range s.Events {
+	}
+	hub.Unsubscribe(s)
+	assert.Eventually(func() bool { return hub.streamCount() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,141 @@
+package graph
+
+import (
+	"sort"
+)
+
+const (
+	CollapsedAggregate      = "collapsed"  // the Aggregate of the nodes generated by CollapseTrafficMap
+	CollapsedAggregateValue = "lowTraffic" // the AggregateValue of the nodes generated by CollapseTrafficMap
+)
+
+// CollapseTrafficMap reduces the TrafficMap to at most maxNodes nodes, when possible, by collapsing
+// low-traffic leaf nodes (nodes without outgoing edges) into a synthetic aggregate node per namespace.
+// Leaf nodes are collapsed in increasing order of incoming traffic (request rates first, then tcp rates).
+// The incoming edges of the collapsed nodes are merged into edges to the aggregate node, per source node
+// and protocol. The IDs of the collapsed nodes are reported in the aggregate node's CollapsedNodes metadata.
+// A maxNodes <= 0 means no limit.
+func CollapseTrafficMap(trafficMap TrafficMap, maxNodes int) {
+	if maxNodes <= 0 || len(trafficMap) <= maxNodes {
+		return
+	}
+
+	collapsed := selectCollapsedNodes(trafficMap, maxNodes)
+	if len(collapsed) == 0 {
+		return
+	}
+
+	// create the aggregate nodes and move the collapsed node traffic to them
+	aggregateNodes := make(map[string]*Node, len(collapsed)) // collapsed node ID => aggregate node
+	for id := range collapsed {
+		n := trafficMap[id]
+		aggregateNode := getCollapsedAggregateNode(trafficMap, n.Cluster, n.Namespace)
+		aggregateNode.Metadata[CollapsedNodes] = append(aggregateNode.Metadata[CollapsedNodes].([]string), n.ID)
+		AggregateNodeTraffic(n, aggregateNode)
+		aggregateNodes[n.ID] = aggregateNode
+	}
+
+	// redirect the incoming edges of the collapsed nodes to the aggregate nodes
+	for _, n := range trafficMap {
+		if _, ok := collapsed[n.ID]; ok {
+			continue
+		}
+		edges := make([]*Edge, 0, len(n.Edges))
+		for _, e := range n.Edges {
+			aggregateNode, ok := aggregateNodes[e.Dest.ID]
+			if !ok {
+				edges = append(edges, e)
+				continue
+			}
+			protocol := e.Metadata[ProtocolKey]
+			var aggregateEdge *Edge
+			for _, ae := range edges {
+				if ae.Dest.ID == aggregateNode.ID && ae.Metadata[ProtocolKey] == protocol {
+					aggregateEdge = ae
+					break
+				}
+			}
+			if aggregateEdge == nil {
+				newEdge := NewEdge(n, aggregateNode)
+				newEdge.Metadata[ProtocolKey] = protocol
+				aggregateEdge = &newEdge
+				edges = append(edges, aggregateEdge)
+			}
+			AggregateEdgeTraffic(e, aggregateEdge)
+		}
+		n.Edges = edges
+	}
+
+	for id := range collapsed {
+		delete(trafficMap, id)
+	}
+	for _, aggregateNode := range aggregateNodes {
+		sort.Strings(aggregateNode.Metadata[CollapsedNodes].([]string))
+	}
+}
+
+// selectCollapsedNodes returns the leaf nodes to collapse, the fewest needed to reduce the TrafficMap to maxNodes,
+// accounting for the aggregate node added for each namespace. It may not be possible to reach maxNodes.
+func selectCollapsedNodes(trafficMap TrafficMap, maxNodes int) map[string]bool {
+	type leaf struct {
+		node     *Node
+		requests float64
+		tcp      float64
+	}
+
+	incoming := make(map[string]*leaf)
+	for _, n := range trafficMap {
+		if len(n.Edges) == 0 && n.NodeType != NodeTypeAggregate {
+			incoming[n.ID] = &leaf{node: n}
+		}
+	}
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			if l, ok := incoming[e.Dest.ID]; ok {
+				l.requests += metadataRate(e.Metadata, grpc) + metadataRate(e.Metadata, http)
+				l.tcp += metadataRate(e.Metadata, tcp)
+			}
+		}
+	}
+
+	leaves := make([]*leaf, 0, len(incoming))
+	for _, l := range incoming {
+		leaves = append(leaves, l)
+	}
+	sort.Slice(leaves, func(i, j int) bool {
+		if leaves[i].requests != leaves[j].requests {
+			return leaves[i].requests < leaves[j].requests
+		}
+		if leaves[i].tcp != leaves[j].tcp {
+			return leaves[i].tcp < leaves[j].tcp
+		}
+		return leaves[i].node.ID < leaves[j].node.ID
+	})
+
+	collapsed := make(map[string]bool)
+	aggregated := make(map[string]bool)
+	numNodes := len(trafficMap)
+	for _, l := range leaves {
+		if numNodes <= maxNodes {
+			break
+		}
+		collapsed[l.node.ID] = true
+		numNodes--
+		if id := AggregateID(l.node.Cluster, l.node.Namespace, CollapsedAggregate, CollapsedAggregateValue, ""); !aggregated[id] {
+			aggregated[id] = true
+			numNodes++
+		}
+	}
+	return collapsed
+}
+
+func getCollapsedAggregateNode(trafficMap TrafficMap, cluster, namespace string) *Node {
+	id := AggregateID(cluster, namespace, CollapsedAggregate, CollapsedAggregateValue, "")
+	if n, ok := trafficMap[id]; ok {
+		return n
+	}
+	n := NewAggregateNodeExplicit(id, cluster, namespace, CollapsedAggregate, CollapsedAggregateValue, "", "")
+	n.Metadata[CollapsedNodes] = []string{}
+	trafficMap[id] = &n
+	return &n
+}
@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollapseTrafficMap(t *testing.T) {
+	assert := assert.New(t)
+
+	// under the limit, nothing to collapse
+	trafficMap := mockFindTrafficMap()
+	CollapseTrafficMap(trafficMap, 6)
+	assert.Equal(6, len(trafficMap))
+	CollapseTrafficMap(trafficMap, 0)
+	assert.Equal(6, len(trafficMap))
+
+	// the leaves, details (no requests) and ratings, are collapsed into one bookinfo aggregate node
+	CollapseTrafficMap(trafficMap, 5)
+	assert.Equal(5, len(trafficMap))
+
+	aggregateID := AggregateID("east", "bookinfo", CollapsedAggregate, CollapsedAggregateValue, "")
+	aggregate, ok := trafficMap[aggregateID]
+	assert.True(ok)
+	assert.Equal(NodeTypeAggregate, aggregate.NodeType)
+	assert.Equal([]string{
+		workloadID("details"),
+		workloadID("ratings"),
+	}, collapsedIDs(aggregate))
+	assert.Equal(50.0, aggregate.Metadata[grpcIn])
+	assert.Equal(1000.0, aggregate.Metadata[tcpIn])
+
+	productpage := trafficMap[workloadID("productpage")]
+	reviews := trafficMap[workloadID("reviews")]
+	for _, source := range []*Node{productpage, reviews} {
+		edgesToAggregate := 0
+		for _, e := range source.Edges {
+			_, ok := trafficMap[e.Dest.ID]
+			assert.True(ok)
+			if e.Dest == aggregate {
+				edgesToAggregate++
+			}
+		}
+		assert.Equal(1, edgesToAggregate)
+	}
+	assert.Equal(2, len(productpage.Edges))
+	assert.Equal(1000.0, productpage.Edges[1].Metadata[tcp])
+	assert.Equal(50.0, reviews.Edges[0].Metadata[grpc])
+
+	// the limit can not always be reached, only leaves are collapsed
+	trafficMap = mockFindTrafficMap()
+	CollapseTrafficMap(trafficMap, 2)
+	assert.Equal(5, len(trafficMap))
+}
+
+func collapsedIDs(n *Node) []string {
+	return n.Metadata[CollapsedNodes].([]string)
+}
+
+func workloadID(workload string) string {
+	id, _ := Id("east", "bookinfo", "", "bookinfo", workload, workload, "v1", GraphTypeWorkload)
+	return id
+}
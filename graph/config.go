@@ -8,3 +8,13 @@ type ConfigVendor interface {
 	// definitions for error handling. Refer to the Cytoscape implementation as an example.
 	NewConfig(trafficMap TrafficMap, o ConfigOptions) interface{}
 }
+
+// RawConfig can be implemented by a Config that is not rendered as JSON (e.g. XML or plain text). In that
+// case the handlers write the marshaled bytes as-is, using the provided content type.
+type RawConfig interface {
+	// ContentType returns the media type of the marshaled config (e.g. application/xml)
+	ContentType() string
+
+	// Marshal returns the rendered config
+	Marshal() ([]byte, error)
+}
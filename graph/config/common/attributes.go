@@ -0,0 +1,218 @@
+// Package common provides code that can be shared by config vendors that render a graph for tools other
+// than the Kiali UI (e.g. GraphML, DOT, JSON Graph Format).  These vendors start from the Cytoscape model,
+// which is the canonical decoration of the TrafficMap, and flatten its node and edge data into simple
+// name/value attributes.
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+)
+
+// The supported attribute types. They are chosen to map directly to GraphML attr.type values.
+const (
+	TypeBoolean string = "boolean"
+	TypeDouble  string = "double"
+	TypeString  string = "string"
+)
+
+// Attribute is a single, flattened, node or edge attribute
+type Attribute struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+// Attributes is an ordered list of attributes
+type Attributes []Attribute
+
+func (a *Attributes) addString(name, value string) {
+	if value != "" {
+		*a = append(*a, Attribute{Name: name, Type: TypeString, Value: value})
+	}
+}
+
+func (a *Attributes) addBool(name string, value bool) {
+	if value {
+		*a = append(*a, Attribute{Name: name, Type: TypeBoolean, Value: "true"})
+	}
+}
+
+func (a *Attributes) addDouble(name, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		a.addString(name, value)
+		return
+	}
+	*a = append(*a, Attribute{Name: name, Type: TypeDouble, Value: value})
+}
+
+func (a *Attributes) addRates(rates map[string]string) {
+	names := make([]string, 0, len(rates))
+	for name := range rates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a.addDouble(name, rates[name])
+	}
+}
+
+// addDiff adds the diff status and a <name>Delta attribute for each reported delta
+func (a *Attributes) addDiff(diff *graph.DiffInfo) {
+	if diff == nil {
+		return
+	}
+	a.addString("diffStatus", diff.Status)
+	names := make([]string, 0, len(diff.Deltas))
+	for name := range diff.Deltas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		a.addDouble(name+"Delta", strconv.FormatFloat(diff.Deltas[name], 'f', -1, 64))
+	}
+}
+
+// NodeAttributes returns the flattened node data. Only attributes with a meaningful value are returned.
+func NodeAttributes(nd *cytoscape.NodeData) Attributes {
+	a := Attributes{}
+
+	a.addString("nodeType", nd.NodeType)
+	a.addString("cluster", nd.Cluster)
+	a.addString("namespace", nd.Namespace)
+	a.addString("workload", nd.Workload)
+	a.addString("app", nd.App)
+	a.addString("version", nd.Version)
+	a.addString("service", nd.Service)
+	a.addString("aggregate", nd.Aggregate)
+	a.addString("isBox", nd.IsBox)
+
+	if len(nd.DestServices) > 0 {
+		destServices := make([]string, len(nd.DestServices))
+		for i, ds := range nd.DestServices {
+			destServices[i] = fmt.Sprintf("%s.%s", ds.Name, ds.Namespace)
+		}
+		sort.Strings(destServices)
+		a.addString("destServices", strings.Join(destServices, ","))
+	}
+
+	for _, pt := range nd.Traffic {
+		a.addRates(pt.Rates)
+	}
+
+	labels := make([]string, 0, len(nd.Labels))
+	for label := range nd.Labels {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		a.addString(graph.BoxByLabelPrefix+label, nd.Labels[label])
+	}
+
+	a.addDiff(nd.Diff)
+
+	a.addBool("hasCB", nd.HasCB)
+	a.addBool("hasFaultInjection", nd.HasFaultInjection)
+	a.addBool("hasHealthConfig", len(nd.HasHealthConfig) > 0)
+	a.addBool("hasMirroring", nd.HasMirroring)
+	a.addBool("hasMissingSC", nd.HasMissingSC)
+	a.addBool("hasRequestRouting", nd.HasRequestRouting)
+	a.addBool("hasRequestTimeout", nd.HasRequestTimeout)
+	a.addBool("hasTCPTrafficShifting", nd.HasTCPTrafficShifting)
+	a.addBool("hasTrafficShifting", nd.HasTrafficShifting)
+	a.addBool("hasVS", nd.HasVS != nil)
+	a.addBool("hasWorkloadEntry", len(nd.HasWorkloadEntry) > 0)
+	a.addBool("isDead", nd.IsDead)
+	a.addBool("isGateway", nd.IsGateway != nil)
+	a.addBool("isIdle", nd.IsIdle)
+	a.addBool("isInaccessible", nd.IsInaccessible)
+	a.addBool("isOutside", nd.IsOutside)
+	a.addBool("isRoot", nd.IsRoot)
+	if nd.IsServiceEntry != nil {
+		a.addString("isServiceEntry", nd.IsServiceEntry.Location)
+	}
+	a.addBool("isWaypoint", nd.IsWaypoint)
+
+	return a
+}
+
+// EdgeAttributes returns the flattened edge data. Only attributes with a meaningful value are returned.
+// Response detail is provided as a single, JSON-encoded, string attribute.
+func EdgeAttributes(ed *cytoscape.EdgeData) Attributes {
+	a := Attributes{}
+
+	a.addString("protocol", ed.Traffic.Protocol)
+	a.addRates(ed.Traffic.Rates)
+	if len(ed.Traffic.Responses) > 0 {
+		responses, err := json.Marshal(ed.Traffic.Responses)
+		graph.CheckError(err)
+		a.addString("responses", string(responses))
+	}
+	a.addBool("isCrossCluster", ed.IsCrossCluster)
+	a.addDouble("isMTLS", ed.IsMTLS)
+	a.addDouble("responseTime", ed.ResponseTime)
+	a.addDouble("throughput", ed.Throughput)
+	a.addString("destPrincipal", ed.DestPrincipal)
+	a.addString("sourcePrincipal", ed.SourcePrincipal)
+	a.addDiff(ed.Diff)
+
+	return a
+}
+
+// NodeLabel returns a short, human-readable, label for the node
+func NodeLabel(nd *cytoscape.NodeData) string {
+	switch nd.NodeType {
+	case graph.NodeTypeAggregate:
+		return nd.Aggregate
+	case graph.NodeTypeApp:
+		if nd.Version != "" {
+			return fmt.Sprintf("%s %s", nd.App, nd.Version)
+		}
+		return nd.App
+	case graph.NodeTypeBox:
+		switch {
+		case nd.IsBox == graph.BoxByApp:
+			return nd.App
+		case nd.IsBox == graph.BoxByCluster:
+			return nd.Cluster
+		case strings.HasPrefix(nd.IsBox, graph.BoxByLabelPrefix):
+			label := strings.TrimPrefix(nd.IsBox, graph.BoxByLabelPrefix)
+			return fmt.Sprintf("%s=%s", label, nd.Labels[label])
+		default:
+			return nd.Namespace
+		}
+	case graph.NodeTypeService:
+		return nd.Service
+	case graph.NodeTypeUnknown:
+		return graph.Unknown
+	default:
+		return nd.Workload
+	}
+}
+
+// EdgeLabel returns a short, human-readable, label for the edge (i.e. the total rate and unit)
+func EdgeLabel(ed *cytoscape.EdgeData) string {
+	for _, p := range graph.Protocols {
+		if p.Name != ed.Traffic.Protocol {
+			continue
+		}
+		for _, r := range p.EdgeRates {
+			if !r.IsTotal {
+				continue
+			}
+			if rate, ok := ed.Traffic.Rates[string(r.Name)]; ok {
+				return fmt.Sprintf("%s %s", rate, p.UnitShort)
+			}
+		}
+	}
+	return ed.Traffic.Protocol
+}
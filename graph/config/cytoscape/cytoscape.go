@@ -38,10 +38,19 @@ type ResponseFlags map[string]string
 // }, ...
 type ResponseHosts map[string]string
 
+// ResponseRoutes is a map of maps. Each response code is broken down by route:percentageOfTraffic, e.g.:
+// "200" : {
+//    "reviews-v1-route" : "60.0",
+//    "reviews-v2-route" : "40.0"
+// }, ...
+// Routes are provided only when requested (see includeRoutes).
+type ResponseRoutes map[string]string
+
 // ResponseDetail holds information broken down by response code.
 type ResponseDetail struct {
-	Flags ResponseFlags `json:"flags,omitempty"`
-	Hosts ResponseHosts `json:"hosts,omitempty"`
+	Flags  ResponseFlags  `json:"flags,omitempty"`
+	Hosts  ResponseHosts  `json:"hosts,omitempty"`
+	Routes ResponseRoutes `json:"routes,omitempty"`
 }
 
 // Responses maps responseCodes to detailed information for that code
@@ -54,16 +63,22 @@ type ProtocolTraffic struct {
 	Responses Responses         `json:"responses,omitempty"` // see comment above
 }
 
-// GWInfo contains the resolved gateway configuration if the node represents an Istio gateway
+// GWInfo contains the resolved gateway configuration if the node represents a gateway
 type GWInfo struct {
 	// IngressInfo contains the resolved gateway configuration if the node represents an Istio ingress gateway
-	IngressInfo GWInfoIngress `json:"ingressInfo,omitempty"`
+	IngressInfo *GWInfoDetail `json:"ingressInfo,omitempty"`
+	// EgressInfo contains the resolved gateway configuration if the node represents an Istio egress gateway
+	EgressInfo *GWInfoDetail `json:"egressInfo,omitempty"`
+	// GatewayAPIInfo contains the resolved gateway configuration if the node represents a Kubernetes Gateway API gateway
+	GatewayAPIInfo *GWInfoDetail `json:"gatewayAPIInfo,omitempty"`
 }
 
-// GWInfoIngress contains the resolved gateway configuration if the node represents an Istio ingress gateway
-type GWInfoIngress struct {
-	// Hostnames is the list of hosts being served by the associated Istio gateways.
+// GWInfoDetail contains the resolved configuration of the gateway resources applied to the node
+type GWInfoDetail struct {
+	// Hostnames is the list of hosts being served by the associated gateways.
 	Hostnames []string `json:"hostnames,omitempty"`
+	// Routes is the list of routes (VirtualServices or HTTPRoutes) bound to the associated gateways.
+	Routes []string `json:"routes,omitempty"`
 }
 
 // VSInfo contains the resolved VS configuration if the node has a VS attached.
@@ -81,35 +96,43 @@ type NodeData struct {
 	Parent string `json:"parent,omitempty"` // Compound Node parent ID
 
 	// App Fields (not required by Cytoscape)
-	NodeType              string              `json:"nodeType"`
-	Cluster               string              `json:"cluster"`
-	Namespace             string              `json:"namespace"`
-	Workload              string              `json:"workload,omitempty"`
-	App                   string              `json:"app,omitempty"`
-	Version               string              `json:"version,omitempty"`
-	Service               string              `json:"service,omitempty"`               // requested service for NodeTypeService
-	Aggregate             string              `json:"aggregate,omitempty"`             // set like "<aggregate>=<aggregateVal>"
-	DestServices          []graph.ServiceName `json:"destServices,omitempty"`          // requested services for [dest] node
-	Traffic               []ProtocolTraffic   `json:"traffic,omitempty"`               // traffic rates for all detected protocols
-	HasCB                 bool                `json:"hasCB,omitempty"`                 // true (has circuit breaker) | false
-	HasFaultInjection     bool                `json:"hasFaultInjection,omitempty"`     // true (vs has fault injection) | false
-	HasHealthConfig       HealthConfig        `json:"hasHealthConfig,omitempty"`       // set to the health config override
-	HasMirroring          bool                `json:"hasMirroring,omitempty"`          // true (has mirroring) | false
-	HasMissingSC          bool                `json:"hasMissingSC,omitempty"`          // true (has missing sidecar) | false
-	HasRequestRouting     bool                `json:"hasRequestRouting,omitempty"`     // true (vs has request routing) | false
-	HasRequestTimeout     bool                `json:"hasRequestTimeout,omitempty"`     // true (vs has request timeout) | false
-	HasTCPTrafficShifting bool                `json:"hasTCPTrafficShifting,omitempty"` // true (vs has tcp traffic shifting) | false
-	HasTrafficShifting    bool                `json:"hasTrafficShifting,omitempty"`    // true (vs has traffic shifting) | false
-	HasVS                 *VSInfo             `json:"hasVS,omitempty"`                 // it can be empty if there is a VS without hostnames
-	HasWorkloadEntry      []graph.WEInfo      `json:"hasWorkloadEntry,omitempty"`      // static workload entry information | empty if there are no workload entries
-	IsBox                 string              `json:"isBox,omitempty"`                 // set for NodeTypeBox, current values: [ 'app', 'cluster', 'namespace' ]
-	IsDead                bool                `json:"isDead,omitempty"`                // true (has no pods) | false
-	IsGateway             *GWInfo             `json:"isGateway,omitempty"`             // Istio ingress/egress gateway information
-	IsIdle                bool                `json:"isIdle,omitempty"`                // true | false
-	IsInaccessible        bool                `json:"isInaccessible,omitempty"`        // true if the node exists in an inaccessible namespace
-	IsOutside             bool                `json:"isOutside,omitempty"`             // true | false
-	IsRoot                bool                `json:"isRoot,omitempty"`                // true | false
-	IsServiceEntry        *graph.SEInfo       `json:"isServiceEntry,omitempty"`        // set static service entry information
+	NodeType              string               `json:"nodeType"`
+	Cluster               string               `json:"cluster"`
+	Namespace             string               `json:"namespace"`
+	Workload              string               `json:"workload,omitempty"`
+	App                   string               `json:"app,omitempty"`
+	Version               string               `json:"version,omitempty"`
+	Service               string               `json:"service,omitempty"`               // requested service for NodeTypeService
+	Aggregate             string               `json:"aggregate,omitempty"`             // set like "<aggregate>=<aggregateVal>"
+	Anomaly               *graph.AnomalyInfo   `json:"anomaly,omitempty"`               // z-scores of the most anomalous incoming edges
+	CollapsedNodes        []string             `json:"collapsedNodes,omitempty"`        // IDs of the nodes collapsed into a [collapsed] aggregate node
+	DestServices          []graph.ServiceName  `json:"destServices,omitempty"`          // requested services for [dest] node
+	Diff                  *graph.DiffInfo      `json:"diff,omitempty"`                  // set only for diff graphs
+	Traffic               []ProtocolTraffic    `json:"traffic,omitempty"`               // traffic rates for all detected protocols
+	HasCB                 bool                 `json:"hasCB,omitempty"`                 // true (has circuit breaker) | false
+	HasFaultInjection     bool                 `json:"hasFaultInjection,omitempty"`     // true (vs has fault injection) | false
+	HasHealthConfig       HealthConfig         `json:"hasHealthConfig,omitempty"`       // set to the health config override
+	HasMirroring          bool                 `json:"hasMirroring,omitempty"`          // true (has mirroring) | false
+	HasMissingSC          bool                 `json:"hasMissingSC,omitempty"`          // true (has missing sidecar) | false
+	HasRequestRouting     bool                 `json:"hasRequestRouting,omitempty"`     // true (vs has request routing) | false
+	HasRequestTimeout     bool                 `json:"hasRequestTimeout,omitempty"`     // true (vs has request timeout) | false
+	HasTCPTrafficShifting bool                 `json:"hasTCPTrafficShifting,omitempty"` // true (vs has tcp traffic shifting) | false
+	HasTrafficShifting    bool                 `json:"hasTrafficShifting,omitempty"`    // true (vs has traffic shifting) | false
+	HasVS                 *VSInfo              `json:"hasVS,omitempty"`                 // it can be empty if there is a VS without hostnames
+	HasWorkloadEntry      []graph.WEInfo       `json:"hasWorkloadEntry,omitempty"`      // static workload entry information | empty if there are no workload entries
+	IsAnomalous           bool                 `json:"isAnomalous,omitempty"`           // true (has an anomalous incoming edge) | false
+	IsBox                 string               `json:"isBox,omitempty"`                 // set for NodeTypeBox, current values: [ 'app', 'cluster', 'label:<key>', 'namespace' ]
+	IsDead                bool                 `json:"isDead,omitempty"`                // true (has no pods) | false
+	IsGateway             *GWInfo              `json:"isGateway,omitempty"`             // Istio ingress/egress gateway information
+	IsIdle                bool                 `json:"isIdle,omitempty"`                // true | false
+	IsInaccessible        bool                 `json:"isInaccessible,omitempty"`        // true if the node exists in an inaccessible namespace
+	IsOutside             bool                 `json:"isOutside,omitempty"`             // true | false
+	IsRoot                bool                 `json:"isRoot,omitempty"`                // true | false
+	IsServiceEntry        *graph.SEInfo        `json:"isServiceEntry,omitempty"`        // set static service entry information
+	IsWaypoint            bool                 `json:"isWaypoint,omitempty"`            // true (is an Istio ambient waypoint proxy) | false
+	Labels                map[string]string    `json:"labels,omitempty"`                // node values for the boxBy labels
+	RootCauseScore        float64              `json:"rootCauseScore,omitempty"`        // rate of errors originating at the node, higher is a more probable root cause
+	Sparkline             *graph.SparklineInfo `json:"sparkline,omitempty"`             // request rate series of the incoming (or, for a root node, outgoing) edges
 }
 
 type EdgeData struct {
@@ -119,12 +142,18 @@ type EdgeData struct {
 	Target string `json:"target"` // child node ID
 
 	// App Fields (not required by Cytoscape)
-	DestPrincipal   string          `json:"destPrincipal,omitempty"`   // principal used for the edge destination
-	IsMTLS          string          `json:"isMTLS,omitempty"`          // set to the percentage of traffic using a mutual TLS connection
-	ResponseTime    string          `json:"responseTime,omitempty"`    // in millis
-	SourcePrincipal string          `json:"sourcePrincipal,omitempty"` // principal used for the edge source
-	Throughput      string          `json:"throughput,omitempty"`      // in bytes/sec (request or response, depends on client request)
-	Traffic         ProtocolTraffic `json:"traffic,omitempty"`         // traffic rates for the edge protocol
+	Anomaly         *graph.AnomalyInfo    `json:"anomaly,omitempty"`         // z-scores relative to the baseline traffic
+	DestPrincipal   string                `json:"destPrincipal,omitempty"`   // principal used for the edge destination
+	Diff            *graph.DiffInfo       `json:"diff,omitempty"`            // set only for diff graphs
+	IsAnomalous     bool                  `json:"isAnomalous,omitempty"`     // true (a z-score reaches the anomaly threshold) | false
+	IsCrossCluster  bool                  `json:"isCrossCluster,omitempty"`  // true (source and dest nodes are in different clusters) | false
+	IsMTLS          string                `json:"isMTLS,omitempty"`          // set to the percentage of traffic using a mutual TLS connection
+	Percentiles     *graph.PercentileInfo `json:"percentiles,omitempty"`     // response time and request/response size percentiles, size histograms
+	ResponseTime    string                `json:"responseTime,omitempty"`    // in millis
+	SourcePrincipal string                `json:"sourcePrincipal,omitempty"` // principal used for the edge source
+	Sparkline       *graph.SparklineInfo  `json:"sparkline,omitempty"`       // request rate series over the time window
+	Throughput      string                `json:"throughput,omitempty"`      // in bytes/sec (request or response, depends on client request)
+	Traffic         ProtocolTraffic       `json:"traffic,omitempty"`         // traffic rates for the edge protocol
 }
 
 type NodeWrapper struct {
@@ -141,10 +170,18 @@ type Elements struct {
 }
 
 type Config struct {
-	Timestamp int64    `json:"timestamp"`
-	Duration  int64    `json:"duration"`
-	GraphType string   `json:"graphType"`
-	Elements  Elements `json:"elements"`
+	Timestamp int64         `json:"timestamp"`
+	Duration  int64         `json:"duration"`
+	GraphType string        `json:"graphType"`
+	Elements  Elements      `json:"elements"`
+	Collapsed *CollapseInfo `json:"collapsed,omitempty"` // set only if nodes were collapsed to honor maxNodes
+}
+
+// CollapseInfo reports the low-traffic nodes collapsed into aggregate nodes to honor the maxNodes option
+type CollapseInfo struct {
+	MaxNodes   int            `json:"maxNodes"`
+	Nodes      int            `json:"nodes"`      // the total number of collapsed nodes
+	Namespaces map[string]int `json:"namespaces"` // namespace => number of collapsed nodes
 }
 
 func nodeHash(id string) string {
@@ -162,14 +199,19 @@ func NewConfig(trafficMap graph.TrafficMap, o graph.ConfigOptions) (result Confi
 
 	buildConfig(trafficMap, &nodes, &edges, o)
 
-	// Add compound nodes as needed, inner boxes first
-	if strings.Contains(o.BoxBy, graph.BoxByApp) || o.GraphType == graph.GraphTypeApp || o.GraphType == graph.GraphTypeVersionedApp {
+	// Add compound nodes as needed, inner boxes first. Label boxes nest in the requested order, within
+	// a namespace.
+	if o.IsBoxBy(graph.BoxByApp) || o.GraphType == graph.GraphTypeApp || o.GraphType == graph.GraphTypeVersionedApp {
 		boxByApp(&nodes)
 	}
-	if strings.Contains(o.BoxBy, graph.BoxByNamespace) {
+	labels := o.BoxByLabels()
+	for i := len(labels) - 1; i >= 0; i-- {
+		boxByLabel(&nodes, labels[:i+1])
+	}
+	if o.IsBoxBy(graph.BoxByNamespace) {
 		boxByNamespace(&nodes)
 	}
-	if strings.Contains(o.BoxBy, graph.BoxByCluster) {
+	if o.IsBoxBy(graph.BoxByCluster) {
 		boxByCluster(&nodes)
 	}
 
@@ -185,10 +227,14 @@ func NewConfig(trafficMap graph.TrafficMap, o graph.ConfigOptions) (result Confi
 				case graph.BoxByNamespace:
 					return 1
 				case graph.BoxByApp:
-					return 2
-				default:
-					return 3
+					return 2 + len(labels)
+				}
+				for i, label := range labels {
+					if boxBy == graph.BoxByLabelPrefix+label {
+						return 2 + i
+					}
 				}
+				return 3 + len(labels)
 			}
 			return rank(nodes[i].Data.IsBox) < rank(nodes[j].Data.IsBox)
 		case nodes[i].Data.Cluster != nodes[j].Data.Cluster:
@@ -223,10 +269,27 @@ func NewConfig(trafficMap graph.TrafficMap, o graph.ConfigOptions) (result Confi
 		Timestamp: o.QueryTime,
 		GraphType: o.GraphType,
 		Elements:  elements,
+		Collapsed: newCollapseInfo(nodes, o.MaxNodes),
 	}
 	return result
 }
 
+// newCollapseInfo returns the collapse report, or nil if no nodes were collapsed
+func newCollapseInfo(nodes []*NodeWrapper, maxNodes int) *CollapseInfo {
+	var info *CollapseInfo
+	for _, nw := range nodes {
+		if len(nw.Data.CollapsedNodes) == 0 {
+			continue
+		}
+		if info == nil {
+			info = &CollapseInfo{MaxNodes: maxNodes, Namespaces: map[string]int{}}
+		}
+		info.Nodes += len(nw.Data.CollapsedNodes)
+		info.Namespaces[nw.Data.Namespace] += len(nw.Data.CollapsedNodes)
+	}
+	return info
+}
+
 func buildConfig(trafficMap graph.TrafficMap, nodes *[]*NodeWrapper, edges *[]*EdgeWrapper, o graph.ConfigOptions) {
 	for id, n := range trafficMap {
 		nodeID := nodeHash(id)
@@ -269,16 +332,20 @@ func buildConfig(trafficMap graph.TrafficMap, nodes *[]*NodeWrapper, edges *[]*E
 			nd.IsInaccessible = val.(bool)
 		}
 
-		// node may represent an Istio Ingress Gateway
+		// node may represent an Istio Ingress or Egress Gateway, or a Kubernetes Gateway API gateway
 		if gateways, ok := n.Metadata[graph.IsIngressGateway]; ok {
-			var configuredHostnames []string
-			for _, hosts := range gateways.(graph.GatewaysMetadata) {
-				configuredHostnames = append(configuredHostnames, hosts...)
-			}
+			addGWInfo(nd).IngressInfo = newGWInfoDetail(gateways.(graph.GatewaysMetadata))
+		}
+		if gateways, ok := n.Metadata[graph.IsEgressGateway]; ok {
+			addGWInfo(nd).EgressInfo = newGWInfoDetail(gateways.(graph.GatewaysMetadata))
+		}
+		if gateways, ok := n.Metadata[graph.IsGatewayAPI]; ok {
+			addGWInfo(nd).GatewayAPIInfo = newGWInfoDetail(gateways.(graph.GatewaysMetadata))
+		}
 
-			nd.IsGateway = &GWInfo{
-				IngressInfo: GWInfoIngress{Hostnames: configuredHostnames},
-			}
+		// node may be an Istio ambient waypoint proxy
+		if val, ok := n.Metadata[graph.IsWaypoint]; ok {
+			nd.IsWaypoint = val.(bool)
 		}
 
 		// node may have a circuit breaker
@@ -352,11 +419,44 @@ func buildConfig(trafficMap graph.TrafficMap, nodes *[]*NodeWrapper, edges *[]*E
 			}
 		}
 
+		// node may be tagged with diff info
+		if val, ok := n.Metadata[graph.Diff]; ok {
+			nd.Diff = val.(*graph.DiffInfo)
+		}
+
+		// node may have labels for label boxing
+		if val, ok := n.Metadata[graph.Labels]; ok {
+			nd.Labels = val.(map[string]string)
+		}
+
+		// node may be a probable root cause of errors
+		if val, ok := n.Metadata[graph.RootCauseScore]; ok {
+			nd.RootCauseScore = val.(float64)
+		}
+
+		// node may be anomalous
+		if val, ok := n.Metadata[graph.Anomaly]; ok {
+			nd.Anomaly = val.(*graph.AnomalyInfo)
+		}
+		if val, ok := n.Metadata[graph.IsAnomalous]; ok {
+			nd.IsAnomalous = val.(bool)
+		}
+
+		// node may have a request rate series
+		if val, ok := n.Metadata[graph.Sparkline]; ok {
+			nd.Sparkline = val.(*graph.SparklineInfo)
+		}
+
 		// node may be an aggregate
 		if n.NodeType == graph.NodeTypeAggregate {
 			nd.Aggregate = fmt.Sprintf("%s=%s", n.Metadata[graph.Aggregate].(string), n.Metadata[graph.AggregateValue].(string))
 		}
 
+		// node may aggregate the nodes collapsed to honor maxNodes
+		if val, ok := n.Metadata[graph.CollapsedNodes]; ok {
+			nd.CollapsedNodes = val.([]string)
+		}
+
 		nw := NodeWrapper{
 			Data: nd,
 		}
@@ -385,6 +485,9 @@ func buildConfig(trafficMap graph.TrafficMap, nodes *[]*NodeWrapper, edges *[]*E
 			if e.Metadata[graph.SourcePrincipal] != nil {
 				ed.SourcePrincipal = e.Metadata[graph.SourcePrincipal].(string)
 			}
+			if e.Metadata[graph.Diff] != nil {
+				ed.Diff = e.Metadata[graph.Diff].(*graph.DiffInfo)
+			}
 			addEdgeTelemetry(e, &ed)
 
 			ew := EdgeWrapper{
@@ -395,6 +498,41 @@ func buildConfig(trafficMap graph.TrafficMap, nodes *[]*NodeWrapper, edges *[]*E
 	}
 }
 
+// addGWInfo returns the node's gateway info, adding it if needed
+func addGWInfo(nd *NodeData) *GWInfo {
+	if nd.IsGateway == nil {
+		nd.IsGateway = &GWInfo{}
+	}
+	return nd.IsGateway
+}
+
+// newGWInfoDetail merges the hostnames and routes of the gateway resources, sorted and without duplicates
+func newGWInfoDetail(gateways graph.GatewaysMetadata) *GWInfoDetail {
+	hostnames := map[string]bool{}
+	routes := map[string]bool{}
+	for _, gw := range gateways {
+		for _, hostname := range gw.Hostnames {
+			hostnames[hostname] = true
+		}
+		for _, route := range gw.Routes {
+			routes[route] = true
+		}
+	}
+	return &GWInfoDetail{Hostnames: sortedKeys(hostnames), Routes: sortedKeys(routes)}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func addNodeTelemetry(n *graph.Node, nd *NodeData) {
 	for _, p := range graph.Protocols {
 		protocolTraffic := ProtocolTraffic{Protocol: p.Name}
@@ -416,13 +554,28 @@ func addNodeTelemetry(n *graph.Node, nd *NodeData) {
 }
 
 func addEdgeTelemetry(e *graph.Edge, ed *EdgeData) {
+	if val, ok := e.Metadata[graph.Anomaly]; ok {
+		ed.Anomaly = val.(*graph.AnomalyInfo)
+	}
+	if val, ok := e.Metadata[graph.IsAnomalous]; ok {
+		ed.IsAnomalous = val.(bool)
+	}
+	if val, ok := e.Metadata[graph.IsCrossCluster]; ok {
+		ed.IsCrossCluster = val.(bool)
+	}
 	if val, ok := e.Metadata[graph.IsMTLS]; ok {
 		ed.IsMTLS = fmt.Sprintf("%.0f", val.(float64))
 	}
+	if val, ok := e.Metadata[graph.Percentiles]; ok {
+		ed.Percentiles = val.(*graph.PercentileInfo)
+	}
 	if val, ok := e.Metadata[graph.ResponseTime]; ok {
 		responseTime := val.(float64)
 		ed.ResponseTime = fmt.Sprintf("%.0f", responseTime)
 	}
+	if val, ok := e.Metadata[graph.Sparkline]; ok {
+		ed.Sparkline = val.(*graph.SparklineInfo)
+	}
 	if val, ok := e.Metadata[graph.Throughput]; ok {
 		throughput := val.(float64)
 		ed.Throughput = fmt.Sprintf("%.0f", throughput)
@@ -472,7 +625,10 @@ func addEdgeTelemetry(e *graph.Edge, ed *EdgeData) {
 						if !r.IsOut {
 							continue
 						}
-						rateVal = total / getRate(e.Source.Metadata, r.Name) * 100.0
+						// the source may have no outbound traffic for a removed diff edge
+						if sourceRate := getRate(e.Source.Metadata, r.Name); sourceRate > 0.0 {
+							rateVal = total / sourceRate * 100.0
+						}
 						break
 					}
 					if rateVal > 0.0 {
@@ -490,14 +646,20 @@ func addEdgeTelemetry(e *graph.Edge, ed *EdgeData) {
 						responseHosts[host] = fmt.Sprintf("%.*f", 1, value/total*100.0)
 					}
 					responseDetail := &ResponseDetail{Flags: responseFlags, Hosts: responseHosts}
+					if len(detail.Routes) > 0 {
+						responseDetail.Routes = make(ResponseRoutes)
+						for route, value := range detail.Routes {
+							responseDetail.Routes[route] = fmt.Sprintf("%.*f", 1, value/total*100.0)
+						}
+					}
 					if protocolTraffic.Responses == nil {
 						protocolTraffic.Responses = Responses{code: responseDetail}
 					} else {
 						protocolTraffic.Responses[code] = responseDetail
 					}
 				}
-				ed.Traffic = protocolTraffic
 			}
+			ed.Traffic = protocolTraffic
 			break
 		}
 	}
@@ -524,6 +686,26 @@ func boxByApp(nodes *[]*NodeWrapper) {
 	generateBoxCompoundNodes(box, nodes, graph.BoxByApp)
 }
 
+// boxByLabel adds compound nodes to box nodes in the same namespace with the same value for the last of the
+// label keys. The keys are those of the nested label boxes, outer box first, such that the boxes of an outer
+// label box are distinct from those of other outer label boxes.
+func boxByLabel(nodes *[]*NodeWrapper, keys []string) {
+	box := make(map[string][]*NodeData)
+	label := keys[len(keys)-1]
+
+	for _, nw := range *nodes {
+		if _, ok := nw.Data.Labels[label]; ok && nw.Data.Parent == "" {
+			k := fmt.Sprintf("box_%s_%s", nw.Data.Cluster, nw.Data.Namespace)
+			for _, key := range keys {
+				k = fmt.Sprintf("%s_%s=%s", k, key, nw.Data.Labels[key])
+			}
+			box[k] = append(box[k], nw.Data)
+		}
+	}
+
+	generateBoxCompoundNodes(box, nodes, graph.BoxByLabelPrefix+label)
+}
+
 // boxByNamespace adds compound nodes to box nodes in the same namespace
 func boxByNamespace(nodes *[]*NodeWrapper) {
 	box := make(map[string][]*NodeData)
@@ -559,12 +741,14 @@ func generateBoxCompoundNodes(box map[string][]*NodeData, nodes *[]*NodeWrapper,
 			nodeID := nodeHash(k)
 			namespace := ""
 			app := ""
-			switch boxBy {
-			case graph.BoxByNamespace:
+			switch {
+			case boxBy == graph.BoxByNamespace:
 				namespace = members[0].Namespace
-			case graph.BoxByApp:
+			case boxBy == graph.BoxByApp:
 				namespace = members[0].Namespace
 				app = members[0].App
+			case strings.HasPrefix(boxBy, graph.BoxByLabelPrefix):
+				namespace = members[0].Namespace
 			}
 			nd := NodeData{
 				ID:        nodeID,
@@ -574,6 +758,7 @@ func generateBoxCompoundNodes(box map[string][]*NodeData, nodes *[]*NodeWrapper,
 				App:       app,
 				Version:   "",
 				IsBox:     boxBy,
+				Labels:    commonLabels(members),
 			}
 
 			nw := NodeWrapper{
@@ -602,6 +787,29 @@ func generateBoxCompoundNodes(box map[string][]*NodeData, nodes *[]*NodeWrapper,
 	}
 }
 
+// commonLabels returns the labels shared by the member nodes, allowing the box node to be boxed by label
+func commonLabels(members []*NodeData) map[string]string {
+	var labels map[string]string
+	for i, n := range members {
+		if i == 0 {
+			labels = make(map[string]string, len(n.Labels))
+			for k, v := range n.Labels {
+				labels[k] = v
+			}
+			continue
+		}
+		for k, v := range labels {
+			if n.Labels[k] != v {
+				delete(labels, k)
+			}
+		}
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	return labels
+}
+
 func rateToString(minPrecision int, rateVal float64) string {
 	precision := minPrecision
 	if requiredPrecision := calcPrecision(rateVal, 5); requiredPrecision > minPrecision {
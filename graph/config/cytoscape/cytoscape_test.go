@@ -110,3 +110,155 @@ func TestHasWorkloadEntryEmpty(t *testing.T) {
 	cytoNode := cytoConfig.Elements.Nodes[0]
 	assert.Empty(cytoNode.Data.HasWorkloadEntry)
 }
+
+func TestBoxByLabel(t *testing.T) {
+	assert := assert.New(t)
+
+	traffic := graph.NewTrafficMap()
+	addNode := func(namespace, workload string, labels map[string]string) {
+		n := graph.NewNode("east", namespace, "", namespace, workload, "", "", graph.GraphTypeWorkload)
+		if labels != nil {
+			n.Metadata[graph.Labels] = labels
+		}
+		traffic[n.ID] = &n
+	}
+	addNode("bookinfo", "productpage-v1", map[string]string{"team": "web", "tier": "front", "app": "productpage"})
+	addNode("bookinfo", "reviews-v1", map[string]string{"team": "books", "tier": "back"})
+	addNode("bookinfo", "ratings-v1", map[string]string{"team": "books", "tier": "back"})
+	addNode("bookinfo", "details-v1", map[string]string{"team": "books", "tier": "front"})
+	addNode("bookinfo", "mongodb-v1", nil)
+	addNode("other", "ratings-v1", map[string]string{"team": "books", "tier": "back"})
+
+	o := graph.ConfigOptions{BoxBy: "label:team,label:tier,namespace"}
+	o.GraphType = graph.GraphTypeWorkload
+	cytoConfig := NewConfig(traffic, o)
+
+	nodes := make(map[string]*NodeData)
+	boxes := make(map[string]*NodeData)
+	for _, nw := range cytoConfig.Elements.Nodes {
+		if nw.Data.IsBox == "" {
+			nodes[nw.Data.Namespace+"/"+nw.Data.Workload] = nw.Data
+		} else {
+			boxes[nw.Data.ID] = nw.Data
+		}
+	}
+	assert.Equal(6, len(nodes))
+	// 2 namespace boxes, 3 team boxes (2 in bookinfo), 4 tier boxes (3 in bookinfo)
+	assert.Equal(9, len(boxes))
+
+	// label:app is not app boxing
+	for _, b := range boxes {
+		assert.NotEqual(graph.BoxByApp, b.IsBox)
+	}
+
+	parentOf := func(nd *NodeData) *NodeData {
+		return boxes[nd.Parent]
+	}
+
+	reviews := nodes["bookinfo/reviews-v1"]
+	tierBox := parentOf(reviews)
+	assert.Equal("label:tier", tierBox.IsBox)
+	assert.Equal("bookinfo", tierBox.Namespace)
+	assert.Equal(map[string]string{"team": "books", "tier": "back"}, tierBox.Labels)
+	assert.Equal(tierBox, parentOf(nodes["bookinfo/ratings-v1"]))
+
+	teamBox := parentOf(tierBox)
+	assert.Equal("label:team", teamBox.IsBox)
+	assert.Equal(map[string]string{"team": "books"}, teamBox.Labels)
+	assert.Equal(teamBox, parentOf(parentOf(nodes["bookinfo/details-v1"])))
+	assert.NotEqual(tierBox, parentOf(nodes["bookinfo/details-v1"]))
+
+	namespaceBox := parentOf(teamBox)
+	assert.Equal(graph.BoxByNamespace, namespaceBox.IsBox)
+	assert.Equal(namespaceBox, parentOf(parentOf(parentOf(nodes["bookinfo/productpage-v1"]))))
+
+	// unlabeled nodes are boxed only by namespace
+	assert.Equal(namespaceBox, parentOf(nodes["bookinfo/mongodb-v1"]))
+
+	// label boxes are per namespace
+	assert.NotEqual(tierBox, parentOf(nodes["other/ratings-v1"]))
+
+	// parent nodes come before their children, outer boxes first
+	seen := make(map[string]bool)
+	for _, nw := range cytoConfig.Elements.Nodes {
+		if nw.Data.Parent != "" {
+			assert.True(seen[nw.Data.Parent], "parent must come before its child")
+		}
+		seen[nw.Data.ID] = true
+	}
+}
+
+func TestResponseRoutes(t *testing.T) {
+	assert := assert.New(t)
+
+	traffic := graph.NewTrafficMap()
+	productpage := graph.NewNode("east", "bookinfo", "", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeWorkload)
+	reviews := graph.NewNode("east", "bookinfo", "", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeWorkload)
+	traffic[productpage.ID] = &productpage
+	traffic[reviews.ID] = &reviews
+	e := productpage.AddEdge(&reviews)
+	e.Metadata[graph.ProtocolKey] = graph.HTTP.Name
+
+	add := func(val float64, code, route string) {
+		graph.AddToMetadata(graph.HTTP.Name, val, code, "-", "reviews:9080", productpage.Metadata, reviews.Metadata, e.Metadata)
+		graph.AddRouteToMetadata(graph.HTTP.Name, val, code, route, e.Metadata)
+	}
+	add(6.0, "200", "reviews-v1-route")
+	add(2.0, "200", "reviews-default")
+	add(2.0, "503", "reviews-v1-route")
+
+	o := graph.ConfigOptions{}
+	o.GraphType = graph.GraphTypeWorkload
+	cytoConfig := NewConfig(traffic, o)
+
+	assert.Equal(1, len(cytoConfig.Elements.Edges))
+	responses := cytoConfig.Elements.Edges[0].Data.Traffic.Responses
+	assert.Equal(ResponseRoutes{"reviews-v1-route": "60.0", "reviews-default": "20.0"}, responses["200"].Routes)
+	assert.Equal(ResponseRoutes{"reviews-v1-route": "20.0"}, responses["503"].Routes)
+	assert.Equal(ResponseHosts{"reviews:9080": "20.0"}, responses["503"].Hosts)
+
+	// routes are aggregated with the edge traffic
+	aggregate := productpage.AddEdge(&reviews)
+	aggregate.Metadata[graph.ProtocolKey] = graph.HTTP.Name
+	graph.AggregateEdgeTraffic(e, aggregate)
+	graph.AggregateEdgeTraffic(e, aggregate)
+	assert.Equal(graph.ResponseRoutes{"reviews-v1-route": 4.0}, aggregate.Metadata["httpResponses"].(graph.Responses)["503"].Routes)
+
+	// no breakdown for tcp
+	graph.AddRouteToMetadata(graph.TCP.Name, 1.0, "-", "reviews-v1-route", e.Metadata)
+	_, ok := e.Metadata["tcpResponses"]
+	assert.False(ok)
+}
+
+func TestCollapsedNodes(t *testing.T) {
+	assert := assert.New(t)
+
+	traffic := graph.NewTrafficMap()
+	productpage := graph.NewNode("east", "bookinfo", "", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeWorkload)
+	traffic[productpage.ID] = &productpage
+	for _, workload := range []string{"details-v1", "reviews-v1", "ratings-v1"} {
+		n := graph.NewNode("east", "bookinfo", "", "bookinfo", workload, "", "", graph.GraphTypeWorkload)
+		traffic[n.ID] = &n
+		e := productpage.AddEdge(&n)
+		e.Metadata[graph.ProtocolKey] = graph.HTTP.Name
+		graph.AddToMetadata(graph.HTTP.Name, 1.0, "200", "-", "", productpage.Metadata, n.Metadata, e.Metadata)
+	}
+
+	o := graph.ConfigOptions{MaxNodes: 2}
+	o.GraphType = graph.GraphTypeWorkload
+	assert.Nil(NewConfig(traffic, o).Collapsed)
+
+	graph.CollapseTrafficMap(traffic, o.MaxNodes)
+	cytoConfig := NewConfig(traffic, o)
+
+	assert.Equal(&CollapseInfo{MaxNodes: 2, Nodes: 3, Namespaces: map[string]int{"bookinfo": 3}}, cytoConfig.Collapsed)
+	assert.Equal(2, len(cytoConfig.Elements.Nodes))
+	assert.Equal(1, len(cytoConfig.Elements.Edges))
+	for _, nw := range cytoConfig.Elements.Nodes {
+		if nw.Data.NodeType == graph.NodeTypeAggregate {
+			assert.Equal("collapsed=lowTraffic", nw.Data.Aggregate)
+			assert.Equal(3, len(nw.Data.CollapsedNodes))
+		}
+	}
+	assert.Equal("3.00", cytoConfig.Elements.Edges[0].Data.Traffic.Rates["http"])
+}
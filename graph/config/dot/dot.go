@@ -0,0 +1,113 @@
+// Package dot provides conversion from our graph to the Graphviz DOT language.
+//
+// DOT language: https://graphviz.org/doc/info/lang.html
+//
+// Algorithm: Generate the Cytoscape config, which fully decorates the nodes and edges, and then
+//            write each node and edge as a DOT statement, with the flattened node and edge data
+//            as DOT attributes. Box (compound) nodes are represented as DOT cluster subgraphs.
+//
+// The package provides the DOT implementation of graph/ConfigVendor.
+package dot
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/common"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+)
+
+const (
+	contentType = "text/vnd.graphviz"
+	indent      = "  "
+)
+
+// Config holds the DOT representation of the graph
+type Config struct {
+	Dot string
+}
+
+// ContentType implements graph.RawConfig
+func (c Config) ContentType() string {
+	return contentType
+}
+
+// Marshal implements graph.RawConfig
+func (c Config) Marshal() ([]byte, error) {
+	return []byte(c.Dot), nil
+}
+
+// NewConfig is required by the graph/ConfigVendor interface
+func NewConfig(trafficMap graph.TrafficMap, o graph.ConfigOptions) (result Config) {
+	cyConfig := cytoscape.NewConfig(trafficMap, o)
+
+	// cytoscape nodes are sorted such that parents (boxes) precede their children
+	children := make(map[string][]*cytoscape.NodeData)
+	roots := []*cytoscape.NodeData{}
+	for _, nw := range cyConfig.Elements.Nodes {
+		if nw.Data.Parent == "" {
+			roots = append(roots, nw.Data)
+		} else {
+			children[nw.Data.Parent] = append(children[nw.Data.Parent], nw.Data)
+		}
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString("digraph \"kiali\" {\n")
+	fmt.Fprintf(&sb, "%sgraph [graphType=%s timestamp=%d duration=%d]\n", indent, quote(cyConfig.GraphType), cyConfig.Timestamp, cyConfig.Duration)
+
+	for _, nd := range roots {
+		writeNode(&sb, nd, children, indent)
+	}
+
+	for _, ew := range cyConfig.Elements.Edges {
+		ed := ew.Data
+		attributes := append(common.Attributes{{Name: "label", Type: common.TypeString, Value: common.EdgeLabel(ed)}}, common.EdgeAttributes(ed)...)
+		fmt.Fprintf(&sb, "%s%s -> %s [%s]\n", indent, quote(ed.Source), quote(ed.Target), attributeList(attributes))
+	}
+
+	sb.WriteString("}\n")
+
+	return Config{Dot: sb.String()}
+}
+
+// writeNode writes a node statement or, for a box, a cluster subgraph holding its members
+func writeNode(sb *strings.Builder, nd *cytoscape.NodeData, children map[string][]*cytoscape.NodeData, prefix string) {
+	attributes := append(common.Attributes{{Name: "label", Type: common.TypeString, Value: common.NodeLabel(nd)}}, common.NodeAttributes(nd)...)
+
+	if nd.NodeType != graph.NodeTypeBox {
+		fmt.Fprintf(sb, "%s%s [%s]\n", prefix, quote(nd.ID), attributeList(attributes))
+		return
+	}
+
+	// the "cluster" prefix is required for Graphviz to render the subgraph as a box
+	fmt.Fprintf(sb, "%ssubgraph %s {\n", prefix, quote("cluster_"+nd.ID))
+	fmt.Fprintf(sb, "%sgraph [%s]\n", prefix+indent, attributeList(attributes))
+	for _, child := range children[nd.ID] {
+		writeNode(sb, child, children, prefix+indent)
+	}
+	fmt.Fprintf(sb, "%s}\n", prefix)
+}
+
+func attributeList(attributes common.Attributes) string {
+	list := make([]string, len(attributes))
+	for i, a := range attributes {
+		name := a.Name
+		if !dotID.MatchString(name) {
+			// e.g. label:<key> attributes
+			name = quote(name)
+		}
+		list[i] = fmt.Sprintf("%s=%s", name, quote(a.Value))
+	}
+	return strings.Join(list, " ")
+}
+
+// dotID matches a DOT ID that does not require quoting
+var dotID = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z_0-9]*$`)
+
+// quote returns a DOT quoted string, escaping any backslash or double-quote
+func quote(s string) string {
+	return fmt.Sprintf("\"%s\"", strings.ReplaceAll(strings.ReplaceAll(s, "\\", "\\\\"), "\"", "\\\""))
+}
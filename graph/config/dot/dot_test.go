@@ -0,0 +1,43 @@
+package dot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/graph"
+)
+
+func TestNewConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := graph.NewTrafficMap()
+	productpage := graph.NewNode("east", "bookinfo", "productpage", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeWorkload)
+	reviews := graph.NewNode("east", "bookinfo", "reviews", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeWorkload)
+	trafficMap[productpage.ID] = &productpage
+	trafficMap[reviews.ID] = &reviews
+	e := productpage.AddEdge(&reviews)
+	e.Metadata[graph.ProtocolKey] = graph.HTTP.Name
+	graph.AddToMetadata(graph.HTTP.Name, 10.0, "200", "-", "reviews.bookinfo.svc.cluster.local", productpage.Metadata, reviews.Metadata, e.Metadata)
+
+	config := NewConfig(trafficMap, graph.ConfigOptions{BoxBy: graph.BoxByNamespace, CommonOptions: graph.CommonOptions{GraphType: graph.GraphTypeWorkload}})
+	lines := strings.Split(config.Dot, "\n")
+
+	assert.Equal(`digraph "kiali" {`, lines[0])
+	assert.Contains(config.Dot, `subgraph "cluster_`)
+	assert.Contains(config.Dot, `[label="productpage-v1" nodeType="workload"`)
+	assert.Contains(config.Dot, `[label="reviews-v1" nodeType="workload"`)
+	assert.Contains(config.Dot, `[label="10.00 rps" protocol="http" http="10.00"`)
+	assert.Contains(config.Dot, `responses="{\"200\":{\"flags\":{\"-\":\"100.0\"}`)
+	assert.Equal("}", lines[len(lines)-2])
+	assert.Equal("text/vnd.graphviz", config.ContentType())
+}
+
+func TestQuote(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(`"foo"`, quote("foo"))
+	assert.Equal(`"\"foo\""`, quote(`"foo"`))
+	assert.Equal(`"a\\b"`, quote(`a\b`))
+}
@@ -0,0 +1,169 @@
+// Package graphml provides conversion from our graph to the GraphML XML model, suitable for tools
+// like yEd or Gephi.
+//
+// GraphML spec: http://graphml.graphdrawing.org/specification.html
+//
+// Algorithm: Generate the Cytoscape config, which fully decorates the nodes and edges, and then
+//            flatten the node and edge data into GraphML <data> elements. Box (compound) nodes
+//            are represented as GraphML nested graphs.
+//
+// The package provides the GraphML implementation of graph/ConfigVendor.
+package graphml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/common"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+)
+
+const (
+	contentType = "application/xml"
+	graphmlNS   = "http://graphml.graphdrawing.org/xmlns"
+)
+
+type Key struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type Data struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type Node struct {
+	ID    string `xml:"id,attr"`
+	Data  []Data `xml:"data"`
+	Graph *Graph `xml:"graph,omitempty"`
+}
+
+type Edge struct {
+	ID     string `xml:"id,attr"`
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+	Data   []Data `xml:"data"`
+}
+
+type Graph struct {
+	ID          string  `xml:"id,attr"`
+	EdgeDefault string  `xml:"edgedefault,attr"`
+	Data        []Data  `xml:"data"`
+	Nodes       []*Node `xml:"node"`
+	Edges       []*Edge `xml:"edge"`
+}
+
+type Config struct {
+	XMLName xml.Name `xml:"graphml"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	Keys    []Key    `xml:"key"`
+	Graph   Graph    `xml:"graph"`
+}
+
+// ContentType implements graph.RawConfig
+func (c Config) ContentType() string {
+	return contentType
+}
+
+// Marshal implements graph.RawConfig
+func (c Config) Marshal() ([]byte, error) {
+	content, err := xml.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), content...), nil
+}
+
+// keys collects the attribute declarations, GraphML requires a <key> for every <data>
+type keys map[string]Key
+
+func (k keys) data(domain string, a common.Attribute) Data {
+	id := fmt.Sprintf("%s_%s", domain, a.Name)
+	if _, ok := k[id]; !ok {
+		k[id] = Key{ID: id, For: domain, AttrName: a.Name, AttrType: a.Type}
+	}
+	return Data{Key: id, Value: a.Value}
+}
+
+func (k keys) dataList(domain string, attributes common.Attributes) []Data {
+	dataList := make([]Data, len(attributes))
+	for i, a := range attributes {
+		dataList[i] = k.data(domain, a)
+	}
+	return dataList
+}
+
+// NewConfig is required by the graph/ConfigVendor interface
+func NewConfig(trafficMap graph.TrafficMap, o graph.ConfigOptions) (result Config) {
+	cyConfig := cytoscape.NewConfig(trafficMap, o)
+	keys := make(keys)
+
+	root := Graph{
+		ID:          "G",
+		EdgeDefault: "directed",
+		Data: keys.dataList("graph", common.Attributes{
+			{Name: "timestamp", Type: common.TypeString, Value: fmt.Sprintf("%d", cyConfig.Timestamp)},
+			{Name: "duration", Type: common.TypeString, Value: fmt.Sprintf("%d", cyConfig.Duration)},
+			{Name: "graphType", Type: common.TypeString, Value: cyConfig.GraphType},
+		}),
+		Nodes: []*Node{},
+		Edges: []*Edge{},
+	}
+
+	// cytoscape nodes are sorted such that parents (boxes) precede their children
+	nodes := make(map[string]*Node, len(cyConfig.Elements.Nodes))
+	for _, nw := range cyConfig.Elements.Nodes {
+		nd := nw.Data
+		attributes := append(common.Attributes{{Name: "label", Type: common.TypeString, Value: common.NodeLabel(nd)}}, common.NodeAttributes(nd)...)
+		node := &Node{
+			ID:   nd.ID,
+			Data: keys.dataList("node", attributes),
+		}
+		nodes[nd.ID] = node
+
+		if nd.Parent == "" {
+			root.Nodes = append(root.Nodes, node)
+			continue
+		}
+		parent := nodes[nd.Parent]
+		if parent.Graph == nil {
+			parent.Graph = &Graph{
+				ID:          fmt.Sprintf("%s:", parent.ID),
+				EdgeDefault: "directed",
+				Nodes:       []*Node{},
+				Edges:       []*Edge{},
+			}
+		}
+		parent.Graph.Nodes = append(parent.Graph.Nodes, node)
+	}
+
+	for _, ew := range cyConfig.Elements.Edges {
+		ed := ew.Data
+		attributes := append(common.Attributes{{Name: "label", Type: common.TypeString, Value: common.EdgeLabel(ed)}}, common.EdgeAttributes(ed)...)
+		root.Edges = append(root.Edges, &Edge{
+			ID:     ed.ID,
+			Source: ed.Source,
+			Target: ed.Target,
+			Data:   keys.dataList("edge", attributes),
+		})
+	}
+
+	result = Config{
+		XMLNS: graphmlNS,
+		Keys:  make([]Key, 0, len(keys)),
+		Graph: root,
+	}
+	for _, k := range keys {
+		result.Keys = append(result.Keys, k)
+	}
+	sort.Slice(result.Keys, func(i, j int) bool {
+		return result.Keys[i].ID < result.Keys[j].ID
+	})
+
+	return result
+}
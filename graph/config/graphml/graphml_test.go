@@ -0,0 +1,71 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/graph"
+)
+
+func graphmlTestTraffic() graph.TrafficMap {
+	trafficMap := graph.NewTrafficMap()
+
+	productpage := graph.NewNode("east", "bookinfo", "productpage", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeVersionedApp)
+	reviews := graph.NewNode("east", "bookinfo", "reviews", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeVersionedApp)
+	trafficMap[productpage.ID] = &productpage
+	trafficMap[reviews.ID] = &reviews
+
+	e := productpage.AddEdge(&reviews)
+	e.Metadata[graph.ProtocolKey] = graph.HTTP.Name
+	e.Metadata[graph.IsMTLS] = 100.0
+	e.Metadata[graph.ResponseTime] = 25.0
+	graph.AddToMetadata(graph.HTTP.Name, 9.0, "200", "-", "reviews.bookinfo.svc.cluster.local", productpage.Metadata, reviews.Metadata, e.Metadata)
+	graph.AddToMetadata(graph.HTTP.Name, 1.0, "503", "UH", "reviews.bookinfo.svc.cluster.local", productpage.Metadata, reviews.Metadata, e.Metadata)
+
+	return trafficMap
+}
+
+func TestNewConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	config := NewConfig(graphmlTestTraffic(), graph.ConfigOptions{BoxBy: graph.BoxByNamespace, CommonOptions: graph.CommonOptions{GraphType: graph.GraphTypeVersionedApp}})
+
+	// box nodes are represented as nested graphs
+	assert.Len(config.Graph.Nodes, 1)
+	box := config.Graph.Nodes[0]
+	assert.NotNil(box.Graph)
+	assert.Len(box.Graph.Nodes, 2)
+	assert.Len(config.Graph.Edges, 1)
+
+	// every data element must have a key declaration
+	keys := make(map[string]Key)
+	for _, k := range config.Keys {
+		keys[k.ID] = k
+	}
+	edge := config.Graph.Edges[0]
+	edgeData := make(map[string]string)
+	for _, d := range edge.Data {
+		k, ok := keys[d.Key]
+		assert.True(ok)
+		assert.Equal("edge", k.For)
+		edgeData[k.AttrName] = d.Value
+	}
+	assert.Equal("http", edgeData["protocol"])
+	assert.Equal("10.00", edgeData["http"])
+	assert.Equal("1.00", edgeData["http5xx"])
+	assert.Equal("10.0", edgeData["httpPercentErr"])
+	assert.Equal("100", edgeData["isMTLS"])
+	assert.Equal("25", edgeData["responseTime"])
+	assert.Contains(edgeData["responses"], "503")
+	assert.Equal("double", keys["edge_http"].AttrType)
+
+	content, err := config.Marshal()
+	assert.NoError(err)
+	assert.Equal("application/xml", config.ContentType())
+
+	unmarshaled := Config{}
+	assert.NoError(xml.Unmarshal(content, &unmarshaled))
+	assert.Equal(len(config.Keys), len(unmarshaled.Keys))
+}
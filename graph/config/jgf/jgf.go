@@ -0,0 +1,92 @@
+// Package jgf provides conversion from our graph to the JSON Graph Format (v2).
+//
+// JSON Graph Format: https://jsongraphformat.info/
+//
+// Algorithm: Generate the Cytoscape config, which fully decorates the nodes and edges, and then
+//            provide the node and edge data as JGF metadata.  The parent of a boxed node is
+//            available in its metadata.
+//
+// The package provides the JSON Graph Format implementation of graph/ConfigVendor.
+package jgf
+
+import (
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/common"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+)
+
+type Node struct {
+	Label    string              `json:"label"`
+	Metadata *cytoscape.NodeData `json:"metadata"`
+}
+
+type Edge struct {
+	ID       string              `json:"id"`
+	Source   string              `json:"source"`
+	Target   string              `json:"target"`
+	Relation string              `json:"relation,omitempty"` // the edge protocol
+	Directed bool                `json:"directed"`
+	Label    string              `json:"label"`
+	Metadata *cytoscape.EdgeData `json:"metadata"`
+}
+
+type GraphMetadata struct {
+	Timestamp int64  `json:"timestamp"`
+	Duration  int64  `json:"duration"`
+	GraphType string `json:"graphType"`
+}
+
+type Graph struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"`
+	Directed bool             `json:"directed"`
+	Metadata GraphMetadata    `json:"metadata"`
+	Nodes    map[string]*Node `json:"nodes"` // key=node ID
+	Edges    []*Edge          `json:"edges"`
+}
+
+type Config struct {
+	Graph Graph `json:"graph"`
+}
+
+// NewConfig is required by the graph/ConfigVendor interface
+func NewConfig(trafficMap graph.TrafficMap, o graph.ConfigOptions) (result Config) {
+	cyConfig := cytoscape.NewConfig(trafficMap, o)
+
+	nodes := make(map[string]*Node, len(cyConfig.Elements.Nodes))
+	for _, nw := range cyConfig.Elements.Nodes {
+		nodes[nw.Data.ID] = &Node{
+			Label:    common.NodeLabel(nw.Data),
+			Metadata: nw.Data,
+		}
+	}
+
+	edges := make([]*Edge, len(cyConfig.Elements.Edges))
+	for i, ew := range cyConfig.Elements.Edges {
+		edges[i] = &Edge{
+			ID:       ew.Data.ID,
+			Source:   ew.Data.Source,
+			Target:   ew.Data.Target,
+			Relation: ew.Data.Traffic.Protocol,
+			Directed: true,
+			Label:    common.EdgeLabel(ew.Data),
+			Metadata: ew.Data,
+		}
+	}
+
+	result = Config{
+		Graph: Graph{
+			ID:       "kiali",
+			Type:     "kiali",
+			Directed: true,
+			Metadata: GraphMetadata{
+				Timestamp: cyConfig.Timestamp,
+				Duration:  cyConfig.Duration,
+				GraphType: cyConfig.GraphType,
+			},
+			Nodes: nodes,
+			Edges: edges,
+		},
+	}
+	return result
+}
@@ -0,0 +1,42 @@
+package jgf
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/graph"
+)
+
+func TestNewConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := graph.NewTrafficMap()
+	productpage := graph.NewNode("east", "bookinfo", "productpage", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeApp)
+	reviews := graph.NewNode("east", "bookinfo", "reviews", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeApp)
+	trafficMap[productpage.ID] = &productpage
+	trafficMap[reviews.ID] = &reviews
+	e := productpage.AddEdge(&reviews)
+	e.Metadata[graph.ProtocolKey] = graph.TCP.Name
+	graph.AddToMetadata(graph.TCP.Name, 1024.0, "", "-", "reviews.bookinfo.svc.cluster.local", productpage.Metadata, reviews.Metadata, e.Metadata)
+
+	config := NewConfig(trafficMap, graph.ConfigOptions{CommonOptions: graph.CommonOptions{GraphType: graph.GraphTypeApp, QueryTime: 1523364075}})
+
+	assert.True(config.Graph.Directed)
+	assert.Equal(int64(1523364075), config.Graph.Metadata.Timestamp)
+	assert.Len(config.Graph.Nodes, 2)
+	assert.Len(config.Graph.Edges, 1)
+
+	edge := config.Graph.Edges[0]
+	assert.Equal("tcp", edge.Relation)
+	assert.Equal("1024.00 bps", edge.Label)
+	assert.Equal("productpage", config.Graph.Nodes[edge.Source].Label)
+	assert.Equal("reviews", config.Graph.Nodes[edge.Target].Label)
+
+	content, err := json.Marshal(config)
+	assert.NoError(err)
+	unmarshaled := map[string]map[string]interface{}{}
+	assert.NoError(json.Unmarshal(content, &unmarshaled))
+	assert.Contains(unmarshaled["graph"], "nodes")
+}
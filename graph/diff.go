@@ -0,0 +1,172 @@
+package graph
+
+// Diff.go provides support for comparing two TrafficMaps, typically generated for two different
+// time windows. The result is a single TrafficMap holding the union of nodes and edges, with each
+// node and edge tagged via Diff metadata.
+
+import (
+	"math"
+)
+
+// The supported diff status values
+const (
+	DiffAdded     string = "added"     // only in the current map
+	DiffChanged   string = "changed"   // in both maps, with differences
+	DiffRemoved   string = "removed"   // only in the base map
+	DiffUnchanged string = "unchanged" // in both maps, without differences
+)
+
+// DiffInfo describes how a node or edge changed between the base and current TrafficMaps. Deltas
+// are keyed by rate name (or edge metadata name, e.g. responseTime) and are current-base.
+type DiffInfo struct {
+	Status string             `json:"status"`
+	Deltas map[string]float64 `json:"deltas,omitempty"`
+}
+
+// DiffTrafficMaps returns a TrafficMap holding the union of the base and current maps. Nodes and edges
+// found only in current are tagged as added, those found only in base as removed. Those found in both are
+// tagged as changed or unchanged, with non-zero deltas reported for node and edge rates, error rates,
+// mTLS percentage, response time and throughput. Nodes and edges of the current map are re-used.
+func DiffTrafficMaps(base, current TrafficMap) TrafficMap {
+	diffMap := NewTrafficMap()
+
+	for id, n := range current {
+		diffMap[id] = n
+		if bn, ok := base[id]; ok {
+			n.Metadata[Diff] = newDiffInfo(nodeDeltas(bn, n), false)
+		} else {
+			n.Metadata[Diff] = &DiffInfo{Status: DiffAdded}
+		}
+	}
+
+	for id, bn := range base {
+		if _, ok := diffMap[id]; ok {
+			continue
+		}
+		rn := *bn
+		rn.Edges = []*Edge{}
+		rn.Metadata = copyMetadata(bn.Metadata)
+		rn.Metadata[Diff] = &DiffInfo{Status: DiffRemoved}
+		diffMap[id] = &rn
+	}
+
+	for id, bn := range base {
+		source := diffMap[id]
+		for _, be := range bn.Edges {
+			if e := findEdge(source, be); e != nil {
+				principalChanged := e.Metadata[DestPrincipal] != be.Metadata[DestPrincipal] || e.Metadata[SourcePrincipal] != be.Metadata[SourcePrincipal]
+				e.Metadata[Diff] = newDiffInfo(edgeDeltas(be, e), principalChanged)
+				continue
+			}
+			re := source.AddEdge(diffMap[be.Dest.ID])
+			re.Metadata = copyMetadata(be.Metadata)
+			re.Metadata[Diff] = &DiffInfo{Status: DiffRemoved}
+		}
+	}
+
+	for _, n := range diffMap {
+		for _, e := range n.Edges {
+			if _, ok := e.Metadata[Diff]; !ok {
+				e.Metadata[Diff] = &DiffInfo{Status: DiffAdded}
+			}
+		}
+	}
+
+	return diffMap
+}
+
+// findEdge returns the source edge matching the base edge's dest and protocol, or nil if not found. Edges
+// previously added for removed traffic are never returned, they are unique by dest and protocol.
+func findEdge(source *Node, baseEdge *Edge) *Edge {
+	for _, e := range source.Edges {
+		if e.Dest.ID == baseEdge.Dest.ID && e.Metadata[ProtocolKey] == baseEdge.Metadata[ProtocolKey] {
+			return e
+		}
+	}
+	return nil
+}
+
+func newDiffInfo(deltas map[string]float64, changed bool) *DiffInfo {
+	if len(deltas) == 0 && !changed {
+		return &DiffInfo{Status: DiffUnchanged}
+	}
+	return &DiffInfo{Status: DiffChanged, Deltas: deltas}
+}
+
+func nodeDeltas(base, current *Node) map[string]float64 {
+	deltas := make(map[string]float64)
+	for _, p := range Protocols {
+		for _, r := range p.NodeRates {
+			addDelta(deltas, string(r.Name), getValue(base.Metadata, r.Name), getValue(current.Metadata, r.Name))
+		}
+	}
+	return deltas
+}
+
+func edgeDeltas(base, current *Edge) map[string]float64 {
+	deltas := make(map[string]float64)
+	for _, p := range Protocols {
+		if p.Name != current.Metadata[ProtocolKey] {
+			continue
+		}
+		var percentErr Rate
+		for _, r := range p.EdgeRates {
+			switch {
+			case r.IsPercentErr:
+				percentErr = r
+			case r.IsPercentReq:
+				// depends on the source node's outbound traffic, not meaningful as an edge delta
+				continue
+			default:
+				addDelta(deltas, string(r.Name), getValue(base.Metadata, r.Name), getValue(current.Metadata, r.Name))
+			}
+		}
+		if percentErr.Name != "" {
+			addDelta(deltas, string(percentErr.Name), percentErrOf(p, base.Metadata), percentErrOf(p, current.Metadata))
+		}
+	}
+	for _, k := range []MetadataKey{IsMTLS, ResponseTime, Throughput} {
+		addDelta(deltas, string(k), getValue(base.Metadata, k), getValue(current.Metadata, k))
+	}
+	return deltas
+}
+
+// percentErrOf returns the percentage of edge traffic that is in error, for the given protocol
+func percentErrOf(p Protocol, md Metadata) float64 {
+	total := 0.0
+	err := 0.0
+	for _, r := range p.EdgeRates {
+		switch {
+		case r.IsTotal:
+			total = getValue(md, r.Name)
+		case r.IsErr:
+			err += getValue(md, r.Name)
+		}
+	}
+	if total == 0.0 {
+		return 0.0
+	}
+	return err / total * 100.0
+}
+
+// addDelta adds current-base, rounded to 3 decimal places, if non-zero
+func addDelta(deltas map[string]float64, name string, base, current float64) {
+	if delta := math.Round((current-base)*1000) / 1000; delta != 0.0 {
+		deltas[name] = delta
+	}
+}
+
+func getValue(md Metadata, k MetadataKey) float64 {
+	if val, ok := md[k].(float64); ok {
+		return val
+	}
+	return 0.0
+}
+
+func copyMetadata(md Metadata) Metadata {
+	result := NewMetadata()
+	for k, v := range md {
+		result[k] = v
+	}
+	return result
+}
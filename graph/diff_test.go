@@ -0,0 +1,123 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildDiffTrafficMap(reviewsRate, reviews5xx, ratingsRate, responseTime float64, withRatings bool) TrafficMap {
+	trafficMap := NewTrafficMap()
+
+	productpage := NewNode("east", "bookinfo", "productpage", "bookinfo", "productpage-v1", "productpage", "v1", GraphTypeVersionedApp)
+	reviews := NewNode("east", "bookinfo", "reviews", "bookinfo", "reviews-v1", "reviews", "v1", GraphTypeVersionedApp)
+	trafficMap[productpage.ID] = &productpage
+	trafficMap[reviews.ID] = &reviews
+
+	e := productpage.AddEdge(&reviews)
+	e.Metadata[ProtocolKey] = "http"
+	e.Metadata[ResponseTime] = responseTime
+	e.Metadata[IsMTLS] = 100.0
+	AddToMetadata("http", reviewsRate-reviews5xx, "200", "-", "", productpage.Metadata, reviews.Metadata, e.Metadata)
+	AddToMetadata("http", reviews5xx, "500", "-", "", productpage.Metadata, reviews.Metadata, e.Metadata)
+
+	if withRatings {
+		ratings := NewNode("east", "bookinfo", "ratings", "bookinfo", "ratings-v1", "ratings", "v1", GraphTypeVersionedApp)
+		trafficMap[ratings.ID] = &ratings
+		e := reviews.AddEdge(&ratings)
+		e.Metadata[ProtocolKey] = "tcp"
+		AddToMetadata("tcp", ratingsRate, "", "-", "", reviews.Metadata, ratings.Metadata, e.Metadata)
+	}
+
+	return trafficMap
+}
+
+func TestDiffTrafficMapsUnchanged(t *testing.T) {
+	assert := assert.New(t)
+
+	base := buildDiffTrafficMap(10.0, 1.0, 0.0, 20.0, false)
+	current := buildDiffTrafficMap(10.0, 1.0, 0.0, 20.0, false)
+
+	diffMap := DiffTrafficMaps(base, current)
+	assert.Equal(2, len(diffMap))
+	for _, n := range diffMap {
+		assert.Equal(DiffUnchanged, n.Metadata[Diff].(*DiffInfo).Status)
+		for _, e := range n.Edges {
+			assert.Equal(DiffUnchanged, e.Metadata[Diff].(*DiffInfo).Status)
+		}
+	}
+}
+
+func TestDiffTrafficMapsChanged(t *testing.T) {
+	assert := assert.New(t)
+
+	base := buildDiffTrafficMap(10.0, 1.0, 0.0, 20.0, false)
+	current := buildDiffTrafficMap(20.0, 5.0, 300.0, 35.5, true)
+
+	diffMap := DiffTrafficMaps(base, current)
+	assert.Equal(3, len(diffMap))
+
+	productpage := diffMap["vapp_east_bookinfo_productpage-v1"]
+	reviews := diffMap["vapp_east_bookinfo_reviews-v1"]
+	ratings := diffMap["vapp_east_bookinfo_ratings-v1"]
+
+	diff := productpage.Metadata[Diff].(*DiffInfo)
+	assert.Equal(DiffChanged, diff.Status)
+	assert.Equal(10.0, diff.Deltas["httpOut"])
+
+	diff = reviews.Metadata[Diff].(*DiffInfo)
+	assert.Equal(DiffChanged, diff.Status)
+	assert.Equal(10.0, diff.Deltas["httpIn"])
+	assert.Equal(4.0, diff.Deltas["httpIn5xx"])
+	assert.Equal(300.0, diff.Deltas["tcpOut"])
+
+	assert.Equal(DiffAdded, ratings.Metadata[Diff].(*DiffInfo).Status)
+
+	assert.Equal(1, len(productpage.Edges))
+	diff = productpage.Edges[0].Metadata[Diff].(*DiffInfo)
+	assert.Equal(DiffChanged, diff.Status)
+	assert.Equal(10.0, diff.Deltas["http"])
+	assert.Equal(4.0, diff.Deltas["http5xx"])
+	assert.Equal(15.0, diff.Deltas["httpPercentErr"])
+	assert.Equal(15.5, diff.Deltas["responseTime"])
+	_, found := diff.Deltas["isMTLS"]
+	assert.False(found)
+	_, found = diff.Deltas["httpPercentReq"]
+	assert.False(found)
+
+	assert.Equal(1, len(reviews.Edges))
+	assert.Equal(DiffAdded, reviews.Edges[0].Metadata[Diff].(*DiffInfo).Status)
+}
+
+func TestDiffTrafficMapsRemoved(t *testing.T) {
+	assert := assert.New(t)
+
+	base := buildDiffTrafficMap(10.0, 0.0, 300.0, 20.0, true)
+	current := buildDiffTrafficMap(10.0, 0.0, 0.0, 20.0, false)
+
+	diffMap := DiffTrafficMaps(base, current)
+	assert.Equal(3, len(diffMap))
+
+	reviews := diffMap["vapp_east_bookinfo_reviews-v1"]
+	ratings := diffMap["vapp_east_bookinfo_ratings-v1"]
+
+	assert.Equal(DiffRemoved, ratings.Metadata[Diff].(*DiffInfo).Status)
+	assert.Equal(300.0, ratings.Metadata["tcpIn"])
+	assert.Equal(0, len(ratings.Edges))
+
+	// the node is still present, but it no longer sends tcp traffic
+	diff := reviews.Metadata[Diff].(*DiffInfo)
+	assert.Equal(DiffChanged, diff.Status)
+	assert.Equal(-300.0, diff.Deltas["tcpOut"])
+
+	assert.Equal(1, len(reviews.Edges))
+	e := reviews.Edges[0]
+	assert.Equal(DiffRemoved, e.Metadata[Diff].(*DiffInfo).Status)
+	assert.Equal(reviews, e.Source)
+	assert.Equal(ratings, e.Dest)
+	assert.Equal(300.0, e.Metadata["tcp"])
+
+	// base metadata must not be altered
+	_, found := base["vapp_east_bookinfo_ratings-v1"].Metadata[Diff]
+	assert.False(found)
+}
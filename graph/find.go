@@ -0,0 +1,435 @@
+package graph
+
+// Find.go implements the graph find/hide expression language, as used by the UI's Find and Hide options,
+// so that a graph request can prune hidden nodes and edges server-side. An expression is one or more
+// terms joined by AND (&&, and) or OR (||, or), AND taking precedence. A term is either:
+//   - a comparison: <operand> <op> <value>, e.g. "http > 10", "ns = bookinfo", "node = service"
+//   - a flag, optionally negated: [! | not] <operand>, e.g. "cb", "!sidecar"
+// The operands of an AND-ed group must all target nodes, or all target edges.
+//
+// Numeric operators:  = != > >= < <=
+// String operators:   = != *= (contains) ^= (starts with) $= (ends with), and their negations !*= !^= !$=
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	findNumber = iota
+	findString
+	findFlag
+)
+
+var (
+	findAnd        = regexp.MustCompile(`(?i)\s*&&\s*|\s+and\s+`)
+	findOr         = regexp.MustCompile(`(?i)\s*\|\|\s*|\s+or\s+`)
+	findComparison = regexp.MustCompile(`^(%?[a-zA-Z]+)\s*(!\*=|!\^=|!\$=|\*=|\^=|\$=|!=|>=|<=|=|>|<)\s*(\S+)$`)
+	findFlagTerm   = regexp.MustCompile(`^(?i)(!|not\s+)?\s*([a-z]+)$`)
+)
+
+// findOperand describes an operand of the expression language. A final operand's value is only available
+// in the final TrafficMap, i.e. it is set by an appender or requires the traffic of all namespaces.
+type findOperand struct {
+	isEdge  bool
+	isFinal bool
+	kind    int
+	node    func(n *Node) interface{}
+	edge    func(e *Edge) interface{}
+}
+
+func nodeOperand(kind int, isFinal bool, value func(n *Node) interface{}) *findOperand {
+	return &findOperand{isFinal: isFinal, kind: kind, node: value}
+}
+
+func edgeOperand(kind int, isFinal bool, value func(e *Edge) interface{}) *findOperand {
+	return &findOperand{isEdge: true, isFinal: isFinal, kind: kind, edge: value}
+}
+
+func nodeMetadata(key MetadataKey) func(n *Node) interface{} {
+	return func(n *Node) interface{} { return n.Metadata[key] }
+}
+
+func nodeRate(key MetadataKey) func(n *Node) interface{} {
+	return func(n *Node) interface{} { return metadataRate(n.Metadata, key) }
+}
+
+func edgeMetadata(key MetadataKey) func(e *Edge) interface{} {
+	return func(e *Edge) interface{} { return e.Metadata[key] }
+}
+
+func edgeRate(key MetadataKey) func(e *Edge) interface{} {
+	return func(e *Edge) interface{} { return metadataRate(e.Metadata, key) }
+}
+
+// edgeErrRate returns the edge's error rate, or percentage of error requests, for the protocol
+func edgeErrRate(protocol Protocol, percent bool) func(e *Edge) interface{} {
+	return func(e *Edge) interface{} {
+		if e.Metadata[ProtocolKey] != protocol.Name {
+			return 0.0
+		}
+		errRate := EdgeErrRate(e)
+		if !percent {
+			return errRate
+		}
+		if total := metadataRate(e.Metadata, MetadataKey(protocol.Name)); total > 0.0 {
+			return errRate / total * 100.0
+		}
+		return 0.0
+	}
+}
+
+// edgePercentReq returns the percentage of the source node's outgoing protocol traffic sent on the edge
+func edgePercentReq(protocol Protocol, out MetadataKey) func(e *Edge) interface{} {
+	return func(e *Edge) interface{} {
+		if sourceTotal := metadataRate(e.Source.Metadata, out); sourceTotal > 0.0 {
+			return metadataRate(e.Metadata, MetadataKey(protocol.Name)) / sourceTotal * 100.0
+		}
+		return 0.0
+	}
+}
+
+// findOperands maps the supported operands to their definitions, see findAliases for alternative names
+var findOperands = map[string]*findOperand{
+	// node operands
+	"app":       nodeOperand(findString, false, func(n *Node) interface{} { return n.App }),
+	"cluster":   nodeOperand(findString, false, func(n *Node) interface{} { return n.Cluster }),
+	"name":      nodeOperand(findString, false, func(n *Node) interface{} { return []string{n.App, n.Service, n.Workload} }),
+	"namespace": nodeOperand(findString, false, func(n *Node) interface{} { return n.Namespace }),
+	"node":      nodeOperand(findString, false, func(n *Node) interface{} { return n.NodeType }),
+	"service":   nodeOperand(findString, false, func(n *Node) interface{} { return n.Service }),
+	"version":   nodeOperand(findString, false, func(n *Node) interface{} { return n.Version }),
+	"workload":  nodeOperand(findString, false, func(n *Node) interface{} { return n.Workload }),
+	"grpcin":    nodeOperand(findNumber, true, nodeRate(grpcIn)),
+	"grpcout":   nodeOperand(findNumber, true, nodeRate(grpcOut)),
+	"httpin":    nodeOperand(findNumber, true, nodeRate(httpIn)),
+	"httpout":   nodeOperand(findNumber, true, nodeRate(httpOut)),
+	"tcpin":     nodeOperand(findNumber, true, nodeRate(tcpIn)),
+	"tcpout":    nodeOperand(findNumber, true, nodeRate(tcpOut)),
+	"cb":        nodeOperand(findFlag, true, nodeMetadata(HasCB)),
+	"dead":      nodeOperand(findFlag, true, nodeMetadata(IsDead)),
+	"idle":      nodeOperand(findFlag, true, nodeMetadata(IsIdle)),
+	"outside":   nodeOperand(findFlag, true, nodeMetadata(IsOutside)),
+	"se":        nodeOperand(findFlag, true, nodeMetadata(IsServiceEntry)),
+	"sidecar":   nodeOperand(findFlag, true, func(n *Node) interface{} { return n.Metadata[HasMissingSC] != true }),
+	"root":      nodeOperand(findFlag, true, nodeMetadata(IsRoot)),
+	"vs":        nodeOperand(findFlag, true, nodeMetadata(HasVS)),
+
+	// edge operands
+	"grpc":         edgeOperand(findNumber, false, edgeRate(grpc)),
+	"grpcerr":      edgeOperand(findNumber, false, edgeErrRate(GRPC, false)),
+	"%grpcerr":     edgeOperand(findNumber, false, edgeErrRate(GRPC, true)),
+	"%grpctraffic": edgeOperand(findNumber, true, edgePercentReq(GRPC, grpcOut)),
+	"http":         edgeOperand(findNumber, false, edgeRate(http)),
+	"httperr":      edgeOperand(findNumber, false, edgeErrRate(HTTP, false)),
+	"%httperr":     edgeOperand(findNumber, false, edgeErrRate(HTTP, true)),
+	"%httptraffic": edgeOperand(findNumber, true, edgePercentReq(HTTP, httpOut)),
+	"protocol":     edgeOperand(findString, false, edgeMetadata(ProtocolKey)),
+	"rpt":          edgeOperand(findNumber, false, func(e *Edge) interface{} { return metadataRate(e.Metadata, grpc) + metadataRate(e.Metadata, http) }),
+	"rt":           edgeOperand(findNumber, true, edgeMetadata(ResponseTime)),
+	"tcp":          edgeOperand(findNumber, false, edgeRate(tcp)),
+	"throughput":   edgeOperand(findNumber, true, edgeMetadata(Throughput)),
+	"mtls":         edgeOperand(findFlag, true, edgeMetadata(IsMTLS)),
+	"traffic": edgeOperand(findFlag, false, func(e *Edge) interface{} {
+		return metadataRate(e.Metadata, grpc)+metadataRate(e.Metadata, http)+metadataRate(e.Metadata, tcp) > 0.0
+	}),
+}
+
+// findAliases maps alternative operand names to the names in findOperands
+var findAliases = map[string]string{
+	"%grpcerror":     "%grpcerr",
+	"%httperror":     "%httperr",
+	"circuitbreaker": "cb",
+	"ns":             "namespace",
+	"outsider":       "outside",
+	"responsetime":   "rt",
+	"rps":            "rpt",
+	"sc":             "sidecar",
+	"serviceentry":   "se",
+	"svc":            "service",
+	"tr":             "root",
+	"trafficsource":  "root",
+	"virtualservice": "vs",
+	"wl":             "workload",
+}
+
+// findNodeTypes maps the supported node operand values to node types
+var findNodeTypes = map[string]string{
+	"aggregate": NodeTypeAggregate,
+	"app":       NodeTypeApp,
+	"service":   NodeTypeService,
+	"svc":       NodeTypeService,
+	"unknown":   NodeTypeUnknown,
+	"wl":        NodeTypeWorkload,
+	"workload":  NodeTypeWorkload,
+}
+
+// findTerm is a single comparison or flag
+type findTerm struct {
+	negate  bool // flag only
+	num     float64
+	op      string
+	operand *findOperand
+	str     string
+}
+
+// findClause is a group of AND-ed terms, all targeting either nodes or edges
+type findClause struct {
+	isEdge bool
+	terms  []findTerm
+}
+
+// FindExpression is a parsed find/hide expression, a disjunction of findClauses
+type FindExpression struct {
+	Expression string
+	clauses    []findClause
+}
+
+// ParseFindExpression parses a find/hide expression, returning an error if the expression is invalid
+func ParseFindExpression(expression string) (*FindExpression, error) {
+	f := FindExpression{Expression: expression}
+
+	for _, or := range findOr.Split(strings.TrimSpace(expression), -1) {
+		clause := findClause{}
+		for i, and := range findAnd.Split(or, -1) {
+			term, err := parseFindTerm(strings.TrimSpace(and))
+			if err != nil {
+				return nil, err
+			}
+			if i == 0 {
+				clause.isEdge = term.operand.isEdge
+			} else if clause.isEdge != term.operand.isEdge {
+				return nil, fmt.Errorf("AND-ed terms [%s] must all target nodes or all target edges", or)
+			}
+			clause.terms = append(clause.terms, term)
+		}
+		f.clauses = append(f.clauses, clause)
+	}
+
+	return &f, nil
+}
+
+func parseFindTerm(term string) (findTerm, error) {
+	if term == "" {
+		return findTerm{}, fmt.Errorf("missing term")
+	}
+
+	if match := findComparison.FindStringSubmatch(term); match != nil {
+		operand, err := lookupFindOperand(match[1])
+		if err != nil {
+			return findTerm{}, err
+		}
+		t := findTerm{op: match[2], operand: operand, str: strings.ToLower(strings.Trim(match[3], `"'`))}
+
+		switch operand.kind {
+		case findFlag:
+			return findTerm{}, fmt.Errorf("operand [%s] does not take a value, use [%s] or [!%s]", match[1], match[1], match[1])
+		case findNumber:
+			switch t.op {
+			case "=", "!=", ">", ">=", "<", "<=":
+			default:
+				return findTerm{}, fmt.Errorf("operator [%s] is not valid for numeric operand [%s]", t.op, match[1])
+			}
+			if t.num, err = strconv.ParseFloat(t.str, 64); err != nil {
+				return findTerm{}, fmt.Errorf("value [%s] is not valid for numeric operand [%s]", match[3], match[1])
+			}
+		case findString:
+			switch t.op {
+			case ">", ">=", "<", "<=":
+				return findTerm{}, fmt.Errorf("operator [%s] is not valid for operand [%s]", t.op, match[1])
+			}
+			if operand == findOperands["node"] {
+				nodeType, ok := findNodeTypes[t.str]
+				if !ok {
+					return findTerm{}, fmt.Errorf("value [%s] is not a valid node type", match[3])
+				}
+				t.str = nodeType
+			}
+		}
+		return t, nil
+	}
+
+	if match := findFlagTerm.FindStringSubmatch(term); match != nil {
+		operand, err := lookupFindOperand(match[2])
+		if err != nil {
+			return findTerm{}, err
+		}
+		if operand.kind != findFlag {
+			return findTerm{}, fmt.Errorf("operand [%s] requires an operator and value", match[2])
+		}
+		return findTerm{negate: match[1] != "", operand: operand}, nil
+	}
+
+	return findTerm{}, fmt.Errorf("invalid term [%s]", term)
+}
+
+func lookupFindOperand(name string) (*findOperand, error) {
+	name = strings.ToLower(name)
+	if alias, ok := findAliases[name]; ok {
+		name = alias
+	}
+	if operand, ok := findOperands[name]; ok {
+		return operand, nil
+	}
+	if name == "healthy" {
+		return nil, fmt.Errorf("operand [%s] is supported only by the UI", name)
+	}
+	return nil, fmt.Errorf("unsupported operand [%s]", name)
+}
+
+// IsTelemetryOnly returns true if the expression references only values set when the telemetry vendor first
+// builds a namespace's TrafficMap (e.g. node identity, edge protocol and rates). Such an expression can be
+// applied before the appenders run, otherwise it must be applied to the final TrafficMap.
+func (f *FindExpression) IsTelemetryOnly() bool {
+	for _, c := range f.clauses {
+		for _, t := range c.terms {
+			if t.operand.isFinal {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MatchNode returns true if the node matches any of the node clauses
+func (f *FindExpression) MatchNode(n *Node) bool {
+	for _, c := range f.clauses {
+		if !c.isEdge && c.match(func(o *findOperand) interface{} { return o.node(n) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchEdge returns true if the edge matches any of the edge clauses
+func (f *FindExpression) MatchEdge(e *Edge) bool {
+	for _, c := range f.clauses {
+		if c.isEdge && c.match(func(o *findOperand) interface{} { return o.edge(e) }) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c findClause) match(value func(o *findOperand) interface{}) bool {
+	for _, t := range c.terms {
+		if !t.match(value(t.operand)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t findTerm) match(value interface{}) bool {
+	switch t.operand.kind {
+	case findFlag:
+		isSet := value != nil && value != false
+		if val, ok := value.(float64); ok {
+			isSet = val > 0.0
+		}
+		return isSet != t.negate
+	case findNumber:
+		val, ok := value.(float64)
+		if !ok {
+			// an unset value (e.g. no response time) matches no comparison
+			return false
+		}
+		switch t.op {
+		case "=":
+			return val == t.num
+		case "!=":
+			return val != t.num
+		case ">":
+			return val > t.num
+		case ">=":
+			return val >= t.num
+		case "<":
+			return val < t.num
+		default:
+			return val <= t.num
+		}
+	default:
+		// a negated operator matches only if none of the values match the positive operator
+		op, negate := t.op, false
+		if strings.HasPrefix(op, "!") {
+			op, negate = strings.TrimPrefix(op, "!"), true
+		}
+		var values []string
+		switch v := value.(type) {
+		case string:
+			values = []string{v}
+		case []string:
+			values = v
+		}
+		for _, v := range values {
+			if v != "" && matchFindString(op, strings.ToLower(v), t.str) {
+				return !negate
+			}
+		}
+		return negate
+	}
+}
+
+func matchFindString(op, val, s string) bool {
+	switch op {
+	case "*=":
+		return strings.Contains(val, s)
+	case "^=":
+		return strings.HasPrefix(val, s)
+	case "$=":
+		return strings.HasSuffix(val, s)
+	default:
+		return val == s
+	}
+}
+
+// metadataRate returns the metadata rate, or 0 if unset
+func metadataRate(md Metadata, key MetadataKey) float64 {
+	if val, ok := md[key].(float64); ok {
+		return val
+	}
+	return 0.0
+}
+
+// HideTrafficMap removes from the TrafficMap the nodes and edges matching the hide expression. The edges of
+// a hidden node are also hidden, and a node left without edges, as a result of hiding, is hidden as well.
+func HideTrafficMap(trafficMap TrafficMap, hide *FindExpression) {
+	if hide == nil {
+		return
+	}
+
+	hadEdges := make(map[string]bool)
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			hadEdges[n.ID] = true
+			hadEdges[e.Dest.ID] = true
+		}
+	}
+
+	for id, n := range trafficMap {
+		if hide.MatchNode(n) {
+			delete(trafficMap, id)
+		}
+	}
+
+	hasEdges := make(map[string]bool)
+	for _, n := range trafficMap {
+		edges := make([]*Edge, 0, len(n.Edges))
+		for _, e := range n.Edges {
+			if _, ok := trafficMap[e.Dest.ID]; ok && !hide.MatchEdge(e) {
+				edges = append(edges, e)
+				hasEdges[n.ID] = true
+				hasEdges[e.Dest.ID] = true
+			}
+		}
+		n.Edges = edges
+	}
+
+	for id := range trafficMap {
+		if hadEdges[id] && !hasEdges[id] {
+			delete(trafficMap, id)
+		}
+	}
+}
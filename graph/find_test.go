@@ -0,0 +1,149 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockFindTrafficMap returns ingress -> productpage -> reviews -> ratings, productpage -> details (tcp),
+// and other/loadgen -> productpage
+func mockFindTrafficMap() TrafficMap {
+	trafficMap := NewTrafficMap()
+	newNode := func(namespace, workload string) *Node {
+		n := NewNode("east", namespace, "", namespace, workload, workload, "v1", GraphTypeWorkload)
+		trafficMap[n.ID] = &n
+		return &n
+	}
+	addEdge := func(source, dest *Node, protocol string, rate, errRate float64) {
+		e := source.AddEdge(dest)
+		e.Metadata[ProtocolKey] = protocol
+		if protocol == tcp {
+			AddToMetadata(protocol, rate, "", "-", "", source.Metadata, dest.Metadata, e.Metadata)
+			return
+		}
+		AddToMetadata(protocol, rate-errRate, "200", "-", "", source.Metadata, dest.Metadata, e.Metadata)
+		AddToMetadata(protocol, errRate, "500", "-", "", source.Metadata, dest.Metadata, e.Metadata)
+	}
+
+	ingress := newNode("bookinfo", "ingress")
+	productpage := newNode("bookinfo", "productpage")
+	reviews := newNode("bookinfo", "reviews")
+	ratings := newNode("bookinfo", "ratings")
+	details := newNode("bookinfo", "details")
+	loadgen := newNode("other", "loadgen")
+
+	addEdge(ingress, productpage, "http", 200.0, 20.0)
+	addEdge(productpage, reviews, "http", 150.0, 1.0)
+	addEdge(reviews, ratings, "grpc", 50.0, 0.0)
+	addEdge(productpage, details, "tcp", 1000.0, 0.0)
+	addEdge(loadgen, productpage, "http", 10.0, 0.0)
+	reviews.Metadata[HasCB] = true
+
+	return trafficMap
+}
+
+func TestParseFindExpression(t *testing.T) {
+	assert := assert.New(t)
+
+	valid := []string{
+		"rpt > 100 && httperr > 5",
+		"ns = foo",
+		"node = service",
+		"NODE = svc or name *= rev",
+		"!sidecar || not cb",
+		"%httperror >= 10.5 and protocol != http",
+		"ns = foo && cb || http < 1",
+	}
+	for _, expression := range valid {
+		_, err := ParseFindExpression(expression)
+		assert.NoError(err, expression)
+	}
+
+	invalid := []string{
+		"",
+		"ns = foo &&",
+		"ns = foo && http > 5",
+		"healthy",
+		"unknownOp = 1",
+		"http *= 1",
+		"http > fast",
+		"ns > foo",
+		"node = pod",
+		"cb = true",
+		"ns",
+	}
+	for _, expression := range invalid {
+		_, err := ParseFindExpression(expression)
+		assert.Error(err, expression)
+	}
+}
+
+func TestFindExpressionIsTelemetryOnly(t *testing.T) {
+	assert := assert.New(t)
+
+	f, _ := ParseFindExpression("ns = foo || rpt > 100 && httperr > 5")
+	assert.True(f.IsTelemetryOnly())
+
+	f, _ = ParseFindExpression("ns = foo || rt > 1000")
+	assert.False(f.IsTelemetryOnly())
+
+	f, _ = ParseFindExpression("httpin > 10")
+	assert.False(f.IsTelemetryOnly())
+}
+
+func TestHideTrafficMapNodes(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := mockFindTrafficMap()
+	hide, _ := ParseFindExpression("ns != bookinfo || name = ratings")
+	HideTrafficMap(trafficMap, hide)
+
+	assert.Equal(4, len(trafficMap))
+	assert.NotContains(trafficMap, "wl_east_other_loadgen")
+	assert.NotContains(trafficMap, "wl_east_bookinfo_ratings")
+	// reviews lost only its outgoing edge
+	assert.Equal(0, len(trafficMap["wl_east_bookinfo_reviews"].Edges))
+	assert.Equal(2, len(trafficMap["wl_east_bookinfo_productpage"].Edges))
+
+	trafficMap = mockFindTrafficMap()
+	hide, _ = ParseFindExpression("cb")
+	HideTrafficMap(trafficMap, hide)
+
+	// ratings is left without edges
+	assert.Equal(4, len(trafficMap))
+	assert.NotContains(trafficMap, "wl_east_bookinfo_reviews")
+	assert.NotContains(trafficMap, "wl_east_bookinfo_ratings")
+}
+
+func TestHideTrafficMapEdges(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := mockFindTrafficMap()
+	hide, _ := ParseFindExpression("rpt > 100 && %httperr > 5")
+	HideTrafficMap(trafficMap, hide)
+
+	// only ingress -> productpage is hidden, leaving ingress without edges
+	assert.Equal(5, len(trafficMap))
+	assert.NotContains(trafficMap, "wl_east_bookinfo_ingress")
+	assert.Equal(2, len(trafficMap["wl_east_bookinfo_productpage"].Edges))
+
+	trafficMap = mockFindTrafficMap()
+	hide, _ = ParseFindExpression("protocol = tcp || grpc > 0")
+	HideTrafficMap(trafficMap, hide)
+
+	assert.Equal(4, len(trafficMap))
+	assert.NotContains(trafficMap, "wl_east_bookinfo_details")
+	assert.NotContains(trafficMap, "wl_east_bookinfo_ratings")
+	assert.Equal(1, len(trafficMap["wl_east_bookinfo_productpage"].Edges))
+
+	// nothing matches
+	trafficMap = mockFindTrafficMap()
+	hide, _ = ParseFindExpression("rt > 1000 || throughput > 0")
+	HideTrafficMap(trafficMap, hide)
+	assert.Equal(6, len(trafficMap))
+
+	// no expression
+	HideTrafficMap(trafficMap, nil)
+	assert.Equal(6, len(trafficMap))
+}
@@ -0,0 +1,174 @@
+package graph
+
+// Matrix.go reduces a TrafficMap to a namespace dependency matrix, i.e. the traffic between each pair of
+// namespaces, to answer "which namespaces call which?" without the node-level detail of the graph.
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// The supported matrix formats
+const (
+	MatrixFormatCSV  string = "csv"
+	MatrixFormatJSON string = "json"
+)
+
+// MatrixCell is the traffic from a source namespace to a dest namespace, summed over the edges between them.
+// RequestRate and ErrorRate are in requests per second (grpc and http requests). Protocols is the rate of each
+// protocol, in the protocol's unit. MTLSPercentage is the percentage of traffic using mutual TLS, averaged over
+// the edges weighted by their rate.
+type MatrixCell struct {
+	Dest           string             `json:"dest"`
+	ErrorRate      float64            `json:"errorRate"`
+	MTLSPercentage float64            `json:"mtlsPercentage"`
+	PercentErr     float64            `json:"percentErr"` // percentage of requests in error
+	Protocols      map[string]float64 `json:"protocols"`
+	RequestRate    float64            `json:"requestRate"`
+	Source         string             `json:"source"`
+}
+
+// NamespaceMatrix holds a cell for each pair of namespaces with traffic, sorted by source then dest namespace.
+// Namespaces holds, sorted, the source and dest namespaces of the cells.
+type NamespaceMatrix struct {
+	Cells      []MatrixCell `json:"cells"`
+	Duration   int64        `json:"duration"` // in seconds
+	Namespaces []string     `json:"namespaces"`
+	Timestamp  int64        `json:"timestamp"` // unix time in seconds
+}
+
+// NamespaceMatrixCSV renders a NamespaceMatrix as CSV, one row per cell
+type NamespaceMatrixCSV struct {
+	NamespaceMatrix
+}
+
+// ContentType implements RawConfig
+func (m NamespaceMatrixCSV) ContentType() string {
+	return "text/csv"
+}
+
+// Marshal implements RawConfig
+func (m NamespaceMatrixCSV) Marshal() ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+
+	header := []string{"source", "dest", "requestRate", "errorRate", "percentErr", "mtlsPercentage"}
+	for _, p := range Protocols {
+		header = append(header, p.Name)
+	}
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	format := func(val float64) string {
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	for _, c := range m.Cells {
+		row := []string{c.Source, c.Dest, format(c.RequestRate), format(c.ErrorRate), format(c.PercentErr), format(c.MTLSPercentage)}
+		for _, p := range Protocols {
+			row = append(row, format(c.Protocols[p.Name]))
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return b.Bytes(), w.Error()
+}
+
+// BuildNamespaceMatrix reduces the TrafficMap to the traffic between each pair of namespaces. The mTLS
+// percentage is available only if the TrafficMap edges were decorated with it (e.g. by a security policy
+// appender).
+func BuildNamespaceMatrix(trafficMap TrafficMap, o Options) NamespaceMatrix {
+	type cellTraffic struct {
+		cell       *MatrixCell
+		mtlsWeight float64 // sum of edge rate * edge mTLS percentage
+		weight     float64 // sum of edge rate
+	}
+
+	cells := make(map[[2]string]*cellTraffic)
+	namespaces := make(map[string]bool)
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			protocol, ok := e.Metadata[ProtocolKey].(string)
+			if !ok {
+				continue
+			}
+
+			key := [2]string{n.Namespace, e.Dest.Namespace}
+			ct, ok := cells[key]
+			if !ok {
+				ct = &cellTraffic{cell: &MatrixCell{Dest: key[1], Protocols: make(map[string]float64), Source: key[0]}}
+				cells[key] = ct
+				namespaces[key[0]] = true
+				namespaces[key[1]] = true
+			}
+
+			for _, p := range Protocols {
+				if p.Name != protocol {
+					continue
+				}
+				for _, r := range p.EdgeRates {
+					val := getValue(e.Metadata, r.Name)
+					switch {
+					case r.IsTotal:
+						ct.cell.Protocols[p.Name] += val
+						if p.Unit == requestsPerSecond {
+							ct.cell.RequestRate += val
+						}
+						ct.weight += val
+						ct.mtlsWeight += val * getValue(e.Metadata, IsMTLS)
+					case r.IsErr && p.Unit == requestsPerSecond:
+						ct.cell.ErrorRate += val
+					}
+				}
+			}
+		}
+	}
+
+	matrix := NamespaceMatrix{
+		Cells:      make([]MatrixCell, 0, len(cells)),
+		Duration:   int64(o.TelemetryOptions.Duration.Seconds()),
+		Namespaces: make([]string, 0, len(namespaces)),
+		Timestamp:  o.TelemetryOptions.QueryTime,
+	}
+	for _, ct := range cells {
+		c := ct.cell
+		if c.RequestRate > 0.0 {
+			c.PercentErr = c.ErrorRate / c.RequestRate * 100.0
+		}
+		if ct.weight > 0.0 {
+			c.MTLSPercentage = ct.mtlsWeight / ct.weight
+		}
+		c.ErrorRate = roundRate(c.ErrorRate)
+		c.MTLSPercentage = roundRate(c.MTLSPercentage)
+		c.PercentErr = roundRate(c.PercentErr)
+		c.RequestRate = roundRate(c.RequestRate)
+		for p, val := range c.Protocols {
+			c.Protocols[p] = roundRate(val)
+		}
+		matrix.Cells = append(matrix.Cells, *c)
+	}
+	for ns := range namespaces {
+		matrix.Namespaces = append(matrix.Namespaces, ns)
+	}
+
+	sort.Slice(matrix.Cells, func(i, j int) bool {
+		if matrix.Cells[i].Source != matrix.Cells[j].Source {
+			return matrix.Cells[i].Source < matrix.Cells[j].Source
+		}
+		return matrix.Cells[i].Dest < matrix.Cells[j].Dest
+	})
+	sort.Strings(matrix.Namespaces)
+
+	return matrix
+}
+
+// roundRate rounds to 3 decimal places, the precision of the telemetry
+func roundRate(val float64) float64 {
+	return math.Round(val*1000) / 1000
+}
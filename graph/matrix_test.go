@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockMatrixTrafficMap returns istio-system:ingress -> bookinfo:productpage -> bookinfo:reviews -> ratings:ratings,
+// with bookinfo:productpage -> ratings:mongodb tcp traffic
+func mockMatrixTrafficMap() TrafficMap {
+	trafficMap := NewTrafficMap()
+	newNode := func(namespace, workload string) *Node {
+		n := NewNode("east", namespace, "", namespace, workload, "", "", GraphTypeWorkload)
+		trafficMap[n.ID] = &n
+		return &n
+	}
+	addEdge := func(source, dest *Node, protocol string, rate, errRate, mtls float64) {
+		e := source.AddEdge(dest)
+		e.Metadata[ProtocolKey] = protocol
+		AddToMetadata(protocol, rate-errRate, "200", "-", "", source.Metadata, dest.Metadata, e.Metadata)
+		AddToMetadata(protocol, errRate, "500", "-", "", source.Metadata, dest.Metadata, e.Metadata)
+		if mtls > 0.0 {
+			e.Metadata[IsMTLS] = mtls
+		}
+	}
+
+	ingress := newNode("istio-system", "ingress")
+	productpage := newNode("bookinfo", "productpage")
+	reviews := newNode("bookinfo", "reviews")
+	ratings := newNode("ratings", "ratings")
+	mongodb := newNode("ratings", "mongodb")
+
+	addEdge(ingress, productpage, "http", 10.0, 1.0, 0.0)
+	addEdge(productpage, reviews, "http", 8.0, 0.0, 100.0)
+	addEdge(reviews, ratings, "grpc", 3.0, 0.0, 100.0)
+	addEdge(productpage, ratings, "http", 1.0, 0.5, 0.0)
+	addEdge(productpage, mongodb, "tcp", 500.0, 0.0, 100.0)
+
+	return trafficMap
+}
+
+func TestBuildNamespaceMatrix(t *testing.T) {
+	assert := assert.New(t)
+
+	o := Options{}
+	o.TelemetryOptions.Duration = 10 * time.Minute
+	o.TelemetryOptions.QueryTime = 1000
+
+	matrix := BuildNamespaceMatrix(mockMatrixTrafficMap(), o)
+	assert.Equal(int64(600), matrix.Duration)
+	assert.Equal(int64(1000), matrix.Timestamp)
+	assert.Equal([]string{"bookinfo", "istio-system", "ratings"}, matrix.Namespaces)
+	assert.Equal(3, len(matrix.Cells))
+
+	internal := matrix.Cells[0]
+	assert.Equal("bookinfo", internal.Source)
+	assert.Equal("bookinfo", internal.Dest)
+	assert.Equal(8.0, internal.RequestRate)
+	assert.Equal(0.0, internal.ErrorRate)
+	assert.Equal(100.0, internal.MTLSPercentage)
+
+	ratings := matrix.Cells[1]
+	assert.Equal("bookinfo", ratings.Source)
+	assert.Equal("ratings", ratings.Dest)
+	assert.Equal(4.0, ratings.RequestRate)
+	assert.Equal(0.5, ratings.ErrorRate)
+	assert.Equal(12.5, ratings.PercentErr)
+	assert.Equal(map[string]float64{"grpc": 3.0, "http": 1.0, "tcp": 500.0}, ratings.Protocols)
+	// weighted by the edge rates: (3 * 100 + 1 * 0 + 500 * 100) / 504
+	assert.Equal(99.802, ratings.MTLSPercentage)
+
+	ingress := matrix.Cells[2]
+	assert.Equal("istio-system", ingress.Source)
+	assert.Equal("bookinfo", ingress.Dest)
+	assert.Equal(10.0, ingress.RequestRate)
+	assert.Equal(10.0, ingress.PercentErr)
+	assert.Equal(0.0, ingress.MTLSPercentage)
+
+	content, err := NamespaceMatrixCSV{NamespaceMatrix: matrix}.Marshal()
+	assert.NoError(err)
+	assert.Equal(`source,dest,requestRate,errorRate,percentErr,mtlsPercentage,grpc,http,tcp
+bookinfo,bookinfo,8,0,0,100,0,8,0
+bookinfo,ratings,4,0.5,12.5,99.802,3,1,500
+istio-system,bookinfo,10,1,10,0,0,10,0
+`, string(content))
+}
@@ -15,8 +15,11 @@ func NewMetadata() Metadata {
 const (
 	Aggregate             MetadataKey = "aggregate" // the prom attribute used for aggregation
 	AggregateValue        MetadataKey = "aggregateValue"
+	Anomaly               MetadataKey = "anomaly"        // *AnomalyInfo
+	CollapsedNodes        MetadataKey = "collapsedNodes" // []string, IDs of the nodes collapsed into an aggregate node, see CollapseTrafficMap
 	DestPrincipal         MetadataKey = "destPrincipal"
 	DestServices          MetadataKey = "destServices"
+	Diff                  MetadataKey = "diff" // *DiffInfo, set only for diff graphs
 	HasCB                 MetadataKey = "hasCB"
 	HasFaultInjection     MetadataKey = "hasFaultInjection"
 	HasHealthConfig       MetadataKey = "hasHealthConfig"
@@ -28,8 +31,12 @@ const (
 	HasRequestTimeout     MetadataKey = "hasRequestTimeout"
 	HasVS                 MetadataKey = "hasVS"
 	HasWorkloadEntry      MetadataKey = "hasWorkloadEntry"
+	IsAnomalous           MetadataKey = "isAnomalous"
+	IsCrossCluster        MetadataKey = "isCrossCluster" // an edge between nodes of different clusters, set only for federated graphs
 	IsDead                MetadataKey = "isDead"
 	IsEgressCluster       MetadataKey = "isEgressCluster"  // PassthroughCluster or BlackHoleCluster
+	IsEgressGateway       MetadataKey = "isEgressGateway"  // Identifies a node that is an Istio egress gateway
+	IsGatewayAPI          MetadataKey = "isGatewayAPI"     // Identifies a node that is a Kubernetes Gateway API gateway
 	IsIngressGateway      MetadataKey = "isIngressGateway" // Identifies a node that is an Istio ingress gateway
 	IsIdle                MetadataKey = "isIdle"
 	IsInaccessible        MetadataKey = "isInaccessible"
@@ -37,12 +44,42 @@ const (
 	IsOutside             MetadataKey = "isOutside"
 	IsRoot                MetadataKey = "isRoot"
 	IsServiceEntry        MetadataKey = "isServiceEntry"
+	IsWaypoint            MetadataKey = "isWaypoint"  // Identifies a node that is an Istio ambient waypoint proxy
+	Labels                MetadataKey = "labels"      // map[string]string, the node's values for the boxBy label keys
+	Percentiles           MetadataKey = "percentiles" // *PercentileInfo
 	ProtocolKey           MetadataKey = "protocol"
 	ResponseTime          MetadataKey = "responseTime"
+	RootCauseScore        MetadataKey = "rootCauseScore"
 	SourcePrincipal       MetadataKey = "sourcePrincipal"
+	Sparkline             MetadataKey = "sparkline" // *SparklineInfo
 	Throughput            MetadataKey = "throughput"
 )
 
+// AnomalyInfo holds the z-scores of a node or edge's current traffic, relative to its baseline traffic.
+// ZScores are keyed by measure name (errorRate, requestRate, responseTime). A measure without a baseline
+// has no z-score.
+type AnomalyInfo struct {
+	ZScores map[string]float64 `json:"zScores"`
+}
+
+// PercentileInfo holds percentiles of an edge's request traffic, keyed by percentile (e.g. p99), and the
+// request and response size histograms, as cumulative request rates keyed by bucket upper bound (e.g. 1000,
+// +Inf). Sizes are in bytes, response times in millis.
+type PercentileInfo struct {
+	RequestSize         map[string]float64 `json:"requestSize,omitempty"`
+	RequestSizeBuckets  map[string]float64 `json:"requestSizeBuckets,omitempty"`
+	ResponseSize        map[string]float64 `json:"responseSize,omitempty"`
+	ResponseSizeBuckets map[string]float64 `json:"responseSizeBuckets,omitempty"`
+	ResponseTime        map[string]float64 `json:"responseTime,omitempty"`
+}
+
+// SparklineInfo holds a request rate (requests/sec) time series, its points evenly spaced over the time window
+// and the last one at the query time. Step is the time between points, in seconds.
+type SparklineInfo struct {
+	Rates []float64 `json:"rates"`
+	Step  int64     `json:"step"`
+}
+
 // DestServicesMetadata key=Service.Key()
 type DestServicesMetadata map[string]ServiceName
 
@@ -57,5 +94,13 @@ func (dsm DestServicesMetadata) Add(key string, service ServiceName) DestService
 	return dsm
 }
 
-type GatewaysMetadata map[string][]string
+// GatewayMetadata holds the hostnames served by a gateway resource and the names of the routes
+// (VirtualServices or HTTPRoutes) bound to it
+type GatewayMetadata struct {
+	Hostnames []string
+	Routes    []string
+}
+
+// GatewaysMetadata key=gateway resource name
+type GatewaysMetadata map[string]*GatewayMetadata
 type VirtualServicesMetadata map[string][]string
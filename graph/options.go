@@ -3,6 +3,7 @@ package graph
 // Options.go holds the option settings for a single graph request.
 
 import (
+	"crypto/sha256"
 	"fmt"
 	net_http "net/http"
 	"net/url"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/kiali/kiali/business"
@@ -22,7 +24,11 @@ import (
 // The supported vendors
 const (
 	VendorCytoscape        string = "cytoscape"
+	VendorDot              string = "dot"
+	VendorGraphML          string = "graphml"
 	VendorIstio            string = "istio"
+	VendorJaeger           string = "jaeger"
+	VendorJGF              string = "jgf"
 	defaultConfigVendor    string = VendorCytoscape
 	defaultTelemetryVendor string = VendorIstio
 )
@@ -30,9 +36,11 @@ const (
 const (
 	BoxByApp                  string = "app"
 	BoxByCluster              string = "cluster"
+	BoxByLabelPrefix          string = "label:" // boxBy=label:<key>
 	BoxByNamespace            string = "namespace"
 	BoxByNone                 string = "none"
 	NamespaceIstio            string = "istio-system"
+	RateConnections           string = "connections" // tcp connections opened
 	RateNone                  string = "none"
 	RateReceived              string = "received" // tcp bytes received, grpc response messages, etc
 	RateRequests              string = "requests" // request count
@@ -42,10 +50,19 @@ const (
 	defaultDuration           string = "10m"
 	defaultGraphType          string = GraphTypeWorkload
 	defaultIncludeIdleEdges   bool   = false
+	defaultIncludeRoutes      bool   = false
 	defaultInjectServiceNodes bool   = false
+	defaultMatrixFormat       string = MatrixFormatJSON
+	defaultMaxNodes           int    = 0 // no limit
+	defaultPathLimit          int    = 10
+	defaultPathRankBy         string = PathRankByResponseTime
 	defaultRateGrpc           string = RateRequests
 	defaultRateHttp           string = RateRequests
 	defaultRateTcp            string = RateSent
+	defaultRefreshInterval    string = "5s"
+	maxRefreshInterval        string = "10s" // a stream connection lives for 25s, see handlers.GraphNamespacesStream
+	maxSnapshotName           int    = 253
+	minRefreshInterval        string = "5s"
 )
 
 const (
@@ -75,7 +92,8 @@ type CommonOptions struct {
 
 // ConfigOptions are those supplied to Config Vendors
 type ConfigOptions struct {
-	BoxBy string
+	BoxBy    string
+	MaxNodes int // collapse low-traffic leaf nodes to limit the graph size, 0 for no limit
 	CommonOptions
 }
 
@@ -94,20 +112,24 @@ type RequestedRates struct {
 type TelemetryOptions struct {
 	AccessibleNamespaces map[string]time.Time
 	Appenders            RequestedAppenders // requested appenders, nil if param not supplied
+	Hide                 *FindExpression    // nodes and edges to prune, nil if param not supplied
 	IncludeIdleEdges     bool               // include edges with request rates of 0
+	IncludeRoutes        bool               // break down request traffic by route, where present in the telemetry
 	InjectServiceNodes   bool               // inject destination service nodes between source and destination nodes.
 	Namespaces           NamespaceInfoMap
 	Rates                RequestedRates
+	UserKey              string `json:"-"` // identifies the requesting user's credentials, to keep per-user graph state apart
 	CommonOptions
 	NodeOptions
 }
 
-// Options comprises all available options
+// Options comprises all available options. The json tags keep the CommonOptions of the embedded
+// ConfigOptions and TelemetryOptions distinct when serialized (e.g. graph snapshots).
 type Options struct {
-	ConfigVendor    string
-	TelemetryVendor string
-	ConfigOptions
-	TelemetryOptions
+	ConfigVendor     string
+	TelemetryVendor  string
+	ConfigOptions    `json:"ConfigOptions"`
+	TelemetryOptions `json:"TelemetryOptions"`
 }
 
 func NewOptions(r *net_http.Request) Options {
@@ -124,8 +146,11 @@ func NewOptions(r *net_http.Request) Options {
 	// query params
 	params := r.URL.Query()
 	var duration model.Duration
+	var hide *FindExpression
 	var includeIdleEdges bool
+	var includeRoutes bool
 	var injectServiceNodes bool
+	var maxNodes int
 	var queryTime int64
 	appenders := RequestedAppenders{All: true}
 	boxBy := params.Get("boxBy")
@@ -133,8 +158,11 @@ func NewOptions(r *net_http.Request) Options {
 	configVendor := params.Get("configVendor")
 	durationString := params.Get("duration")
 	graphType := params.Get("graphType")
+	hideString := params.Get("hide")
 	includeIdleEdgesString := params.Get("includeIdleEdges")
+	includeRoutesString := params.Get("includeRoutes")
 	injectServiceNodesString := params.Get("injectServiceNodes")
+	maxNodesString := params.Get("maxNodes")
 	namespaces := params.Get("namespaces") // csl of namespaces
 	queryTimeString := params.Get("queryTime")
 	rateGrpc := params.Get("rateGrpc")
@@ -155,7 +183,7 @@ func NewOptions(r *net_http.Request) Options {
 	}
 	if configVendor == "" {
 		configVendor = defaultConfigVendor
-	} else if configVendor != VendorCytoscape {
+	} else if configVendor != VendorCytoscape && configVendor != VendorDot && configVendor != VendorGraphML && configVendor != VendorJGF {
 		BadRequest(fmt.Sprintf("Invalid configVendor [%s]", configVendor))
 	}
 	if durationString == "" {
@@ -180,18 +208,30 @@ func NewOptions(r *net_http.Request) Options {
 		boxBy = defaultBoxBy
 	} else {
 		for _, box := range strings.Split(boxBy, ",") {
-			switch strings.TrimSpace(box) {
-			case BoxByApp:
+			box = strings.TrimSpace(box)
+			switch {
+			case box == BoxByApp:
 				continue
-			case BoxByCluster:
+			case box == BoxByCluster:
 				continue
-			case BoxByNamespace:
+			case box == BoxByNamespace:
 				continue
+			case strings.HasPrefix(box, BoxByLabelPrefix):
+				if errs := validation.IsQualifiedName(strings.TrimPrefix(box, BoxByLabelPrefix)); len(errs) > 0 {
+					BadRequest(fmt.Sprintf("Invalid boxBy label [%s]: %s", box, strings.Join(errs, "; ")))
+				}
 			default:
 				BadRequest(fmt.Sprintf("Invalid boxBy [%s]", boxBy))
 			}
 		}
 	}
+	if hideString != "" {
+		var hideErr error
+		hide, hideErr = ParseFindExpression(hideString)
+		if hideErr != nil {
+			BadRequest(fmt.Sprintf("Invalid hide [%s]: %v", hideString, hideErr))
+		}
+	}
 	if includeIdleEdgesString == "" {
 		includeIdleEdges = defaultIncludeIdleEdges
 	} else {
@@ -201,6 +241,15 @@ func NewOptions(r *net_http.Request) Options {
 			BadRequest(fmt.Sprintf("Invalid includeIdleEdges [%s]", includeIdleEdgesString))
 		}
 	}
+	if includeRoutesString == "" {
+		includeRoutes = defaultIncludeRoutes
+	} else {
+		var includeRoutesErr error
+		includeRoutes, includeRoutesErr = strconv.ParseBool(includeRoutesString)
+		if includeRoutesErr != nil {
+			BadRequest(fmt.Sprintf("Invalid includeRoutes [%s]", includeRoutesString))
+		}
+	}
 	if injectServiceNodesString == "" {
 		injectServiceNodes = defaultInjectServiceNodes
 	} else {
@@ -210,6 +259,15 @@ func NewOptions(r *net_http.Request) Options {
 			BadRequest(fmt.Sprintf("Invalid injectServiceNodes [%s]", injectServiceNodesString))
 		}
 	}
+	if maxNodesString == "" {
+		maxNodes = defaultMaxNodes
+	} else {
+		var maxNodesErr error
+		maxNodes, maxNodesErr = strconv.Atoi(maxNodesString)
+		if maxNodesErr != nil || maxNodes < 0 {
+			BadRequest(fmt.Sprintf("Invalid maxNodes [%s]", maxNodesString))
+		}
+	}
 	if queryTimeString == "" {
 		queryTime = time.Now().Unix()
 	} else {
@@ -221,8 +279,19 @@ func NewOptions(r *net_http.Request) Options {
 	}
 	if telemetryVendor == "" {
 		telemetryVendor = defaultTelemetryVendor
-	} else if telemetryVendor != VendorIstio {
-		BadRequest(fmt.Sprintf("Invalid telemetryVendor [%s]", telemetryVendor))
+	}
+	if vendor, found := GetTelemetryVendor(telemetryVendor); !found {
+		BadRequest(fmt.Sprintf("Invalid telemetryVendor [%s], must be one of %v", telemetryVendor, TelemetryVendorNames()))
+	} else if !appenders.All {
+		supported := make(map[string]bool)
+		for _, appenderName := range vendor.Appenders() {
+			supported[appenderName] = true
+		}
+		for _, appenderName := range appenders.AppenderNames {
+			if appenderName != "" && !supported[appenderName] {
+				BadRequest(fmt.Sprintf("Invalid appender [%s] for telemetryVendor [%s]", appenderName, telemetryVendor))
+			}
+		}
 	}
 
 	// Process namespaces options:
@@ -304,6 +373,8 @@ func NewOptions(r *net_http.Request) Options {
 
 	if rateTcp != "" {
 		switch rateTcp {
+		case RateConnections:
+			rates.Tcp = RateConnections
 		case RateNone:
 			rates.Tcp = RateNone
 		case RateReceived:
@@ -326,7 +397,8 @@ func NewOptions(r *net_http.Request) Options {
 		ConfigVendor:    configVendor,
 		TelemetryVendor: telemetryVendor,
 		ConfigOptions: ConfigOptions{
-			BoxBy: boxBy,
+			BoxBy:    boxBy,
+			MaxNodes: maxNodes,
 			CommonOptions: CommonOptions{
 				Duration:  time.Duration(duration),
 				GraphType: graphType,
@@ -337,10 +409,13 @@ func NewOptions(r *net_http.Request) Options {
 		TelemetryOptions: TelemetryOptions{
 			AccessibleNamespaces: accessibleNamespaces,
 			Appenders:            appenders,
+			Hide:                 hide,
 			IncludeIdleEdges:     includeIdleEdges,
+			IncludeRoutes:        includeRoutes,
 			InjectServiceNodes:   injectServiceNodes,
 			Namespaces:           namespaceMap,
 			Rates:                rates,
+			UserKey:              getUserKey(authInfo),
 			CommonOptions: CommonOptions{
 				Duration:  time.Duration(duration),
 				GraphType: graphType,
@@ -363,6 +438,261 @@ func NewOptions(r *net_http.Request) Options {
 	return options
 }
 
+// DiffOptions are those supplied to a diff graph request. The embedded Options describe the current
+// time window, Base describes the time window against which the current window is compared.
+type DiffOptions struct {
+	Base Options
+	Options
+}
+
+// NewDiffOptions returns the options for a diff graph request. In addition to the standard graph
+// query params it requires baseQueryTime and optionally accepts baseDuration (default: duration).
+func NewDiffOptions(r *net_http.Request) DiffOptions {
+	o := NewOptions(r)
+
+	params := r.URL.Query()
+	baseDurationString := params.Get("baseDuration")
+	baseQueryTimeString := params.Get("baseQueryTime")
+
+	var baseDuration time.Duration
+	var baseQueryTime int64
+
+	if baseDurationString == "" {
+		baseDuration = o.TelemetryOptions.Duration
+	} else {
+		duration, durationErr := model.ParseDuration(baseDurationString)
+		if durationErr != nil {
+			BadRequest(fmt.Sprintf("Invalid baseDuration [%s]", baseDurationString))
+		}
+		baseDuration = time.Duration(duration)
+	}
+	if baseQueryTimeString == "" {
+		BadRequest("A diff graph requires the baseQueryTime query parameter.")
+	} else {
+		var queryTimeErr error
+		baseQueryTime, queryTimeErr = strconv.ParseInt(baseQueryTimeString, 10, 64)
+		if queryTimeErr != nil {
+			BadRequest(fmt.Sprintf("Invalid baseQueryTime [%s]", baseQueryTimeString))
+		}
+	}
+
+	base := o
+	base.ConfigOptions.Duration = baseDuration
+	base.ConfigOptions.QueryTime = baseQueryTime
+	base.TelemetryOptions.Duration = baseDuration
+	base.TelemetryOptions.QueryTime = baseQueryTime
+	base.Namespaces = NewNamespaceInfoMap()
+	for name, ns := range o.Namespaces {
+		base.Namespaces[name] = NamespaceInfo{
+			Name:     name,
+			Duration: getSafeNamespaceDuration(name, o.AccessibleNamespaces[name], baseDuration, baseQueryTime),
+			IsIstio:  ns.IsIstio,
+		}
+	}
+
+	return DiffOptions{
+		Base:    base,
+		Options: o,
+	}
+}
+
+// StreamOptions are those supplied to a streaming graph request. The embedded Options describe the
+// graph, RefreshInterval how often it is regenerated and the deltas pushed to the subscribers.
+type StreamOptions struct {
+	RefreshInterval time.Duration
+	Options
+}
+
+// NewStreamOptions returns the options for a streaming graph request. In addition to the standard graph
+// query params it optionally accepts refreshInterval (default: 5s, minimum: 5s, maximum: 10s). The maximum
+// ensures a stream connection, closed before the server's write timeout, receives deltas and not just the
+// initial graph. The queryTime param is not supported, a stream always reports the most recent time window.
+func NewStreamOptions(r *net_http.Request) StreamOptions {
+	o := NewOptions(r)
+
+	params := r.URL.Query()
+	refreshIntervalString := params.Get("refreshInterval")
+
+	if o.ConfigVendor != VendorCytoscape {
+		BadRequest(fmt.Sprintf("Invalid configVendor [%s]. Graph streaming supports only configVendor cytoscape.", o.ConfigVendor))
+	}
+	if params.Get("queryTime") != "" {
+		BadRequest("Graph streaming does not support the queryTime query parameter.")
+	}
+	if refreshIntervalString == "" {
+		refreshIntervalString = defaultRefreshInterval
+	}
+	refreshInterval, refreshIntervalErr := model.ParseDuration(refreshIntervalString)
+	if refreshIntervalErr != nil {
+		BadRequest(fmt.Sprintf("Invalid refreshInterval [%s]", refreshIntervalString))
+	}
+	minInterval, _ := model.ParseDuration(minRefreshInterval)
+	if refreshInterval < minInterval {
+		BadRequest(fmt.Sprintf("Invalid refreshInterval [%s], the minimum is [%s]", refreshIntervalString, minRefreshInterval))
+	}
+	maxInterval, _ := model.ParseDuration(maxRefreshInterval)
+	if refreshInterval > maxInterval {
+		BadRequest(fmt.Sprintf("Invalid refreshInterval [%s], the maximum is [%s]", refreshIntervalString, maxRefreshInterval))
+	}
+
+	return StreamOptions{
+		RefreshInterval: time.Duration(refreshInterval),
+		Options:         o,
+	}
+}
+
+// PathOptions are those supplied to a path analysis request. The embedded Options describe the graph
+// in which paths are found from the Source node to the Dest node.
+type PathOptions struct {
+	Dest   string
+	Limit  int
+	RankBy string
+	Source string
+	Options
+}
+
+// NewPathOptions returns the options for a path analysis request. In addition to the standard graph
+// query params it requires source and dest (TrafficMap node IDs), and optionally accepts rankBy
+// (default: responseTime) and limit (default: 10).
+func NewPathOptions(r *net_http.Request) PathOptions {
+	o := NewOptions(r)
+
+	params := r.URL.Query()
+	dest := params.Get("dest")
+	limitString := params.Get("limit")
+	rankBy := params.Get("rankBy")
+	source := params.Get("source")
+
+	if source == "" || dest == "" {
+		BadRequest("Path analysis requires the source and dest query parameters.")
+	}
+
+	limit := defaultPathLimit
+	if limitString != "" {
+		var limitErr error
+		limit, limitErr = strconv.Atoi(limitString)
+		if limitErr != nil || limit <= 0 {
+			BadRequest(fmt.Sprintf("Invalid limit [%s]", limitString))
+		}
+	}
+
+	switch rankBy {
+	case "":
+		rankBy = defaultPathRankBy
+	case PathRankByErrorRate, PathRankByResponseTime:
+	default:
+		BadRequest(fmt.Sprintf("Invalid rankBy [%s], must be one of: %s | %s", rankBy, PathRankByErrorRate, PathRankByResponseTime))
+	}
+
+	// response times are provided by an appender, when the vendor requires it make sure it is requested
+	if rankBy == PathRankByResponseTime && !o.TelemetryOptions.Appenders.All {
+		vendor, _ := GetTelemetryVendor(o.TelemetryVendor)
+		if contains(vendor.Appenders(), "responseTime") && !contains(o.TelemetryOptions.Appenders.AppenderNames, "responseTime") {
+			BadRequest(fmt.Sprintf("Path analysis with rankBy [%s] requires the responseTime appender.", rankBy))
+		}
+	}
+
+	return PathOptions{
+		Dest:    dest,
+		Limit:   limit,
+		RankBy:  rankBy,
+		Source:  source,
+		Options: o,
+	}
+}
+
+// SnapshotOptions are those supplied to a graph snapshot request. The embedded Options describe the graph
+// to be generated and saved, Name optionally names the snapshot.
+type SnapshotOptions struct {
+	Name string
+	Options
+}
+
+// NewSnapshotOptions returns the options for a graph snapshot request. In addition to the standard graph
+// query params it optionally accepts name. Snapshots support only configVendor cytoscape.
+func NewSnapshotOptions(r *net_http.Request) SnapshotOptions {
+	o := NewOptions(r)
+
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+
+	if o.ConfigVendor != VendorCytoscape {
+		BadRequest(fmt.Sprintf("Invalid configVendor [%s]. Graph snapshots support only configVendor cytoscape.", o.ConfigVendor))
+	}
+	if len(name) > maxSnapshotName {
+		BadRequest(fmt.Sprintf("Invalid name, the maximum length is [%d]", maxSnapshotName))
+	}
+
+	return SnapshotOptions{
+		Name:    name,
+		Options: o,
+	}
+}
+
+// MatrixOptions are those supplied to a namespace dependency matrix request. The embedded Options describe the
+// graph reduced to the matrix, Format is the matrix format.
+type MatrixOptions struct {
+	Format string
+	Options
+}
+
+// NewMatrixOptions returns the options for a namespace dependency matrix request. In addition to the standard
+// graph query params it optionally accepts format (default: json). Service nodes are never injected, they would
+// count the traffic to a service twice. The appenders are limited to the security policy appender, providing
+// the mTLS percentages, when supported by the telemetry vendor.
+func NewMatrixOptions(r *net_http.Request) MatrixOptions {
+	o := NewOptions(r)
+
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "":
+		format = defaultMatrixFormat
+	case MatrixFormatCSV, MatrixFormatJSON:
+	default:
+		BadRequest(fmt.Sprintf("Invalid format [%s], must be one of: %s | %s", format, MatrixFormatCSV, MatrixFormatJSON))
+	}
+
+	o.TelemetryOptions.InjectServiceNodes = false
+	o.TelemetryOptions.Appenders = RequestedAppenders{All: false, AppenderNames: []string{}}
+	if vendor, _ := GetTelemetryVendor(o.TelemetryVendor); contains(vendor.Appenders(), "securityPolicy") {
+		o.TelemetryOptions.Appenders.AppenderNames = append(o.TelemetryOptions.Appenders.AppenderNames, "securityPolicy")
+	}
+
+	return MatrixOptions{
+		Format:  format,
+		Options: o,
+	}
+}
+
+// IsBoxBy returns true if box is one of the requested boxBy values
+func (o ConfigOptions) IsBoxBy(box string) bool {
+	for _, b := range strings.Split(o.BoxBy, ",") {
+		if strings.TrimSpace(b) == box {
+			return true
+		}
+	}
+	return false
+}
+
+// BoxByLabels returns the label keys of the requested label boxing, in the requested order (outer box first)
+func (o ConfigOptions) BoxByLabels() []string {
+	labels := []string{}
+	for _, b := range strings.Split(o.BoxBy, ",") {
+		if b = strings.TrimSpace(b); strings.HasPrefix(b, BoxByLabelPrefix) {
+			labels = append(labels, strings.TrimPrefix(b, BoxByLabelPrefix))
+		}
+	}
+	return labels
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // GetGraphKind will return the kind of graph represented by the options.
 func (o *TelemetryOptions) GetGraphKind() string {
 	if o.NodeOptions.App != "" ||
@@ -395,6 +725,12 @@ func getAccessibleNamespaces(authInfo *api.AuthInfo) map[string]time.Time {
 	return namespaceMap
 }
 
+// getUserKey returns a hash of the user's credentials. Graphs generated for different keys may differ, as
+// the user's RBAC applies to the config consulted in their generation.
+func getUserKey(authInfo *api.AuthInfo) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(authInfo.Token+"\n"+authInfo.Username+"\n"+authInfo.Impersonate)))
+}
+
 // getSafeNamespaceDuration returns a safe duration for the query. If queryTime-requestedDuration > namespace
 // creation time just return the requestedDuration.  Otherwise reduce the duration as needed to ensure the
 // namespace existed for the entire time range.  An error is generated if no safe duration exists (i.e. the
@@ -0,0 +1,190 @@
+package graph
+
+// Path.go provides path analysis for a TrafficMap. It finds the simple paths (i.e. paths without
+// repeated nodes) between two nodes and ranks them by cumulative response time or error rate, to help
+// identify the slow or failing hops between two points of the mesh.
+
+import (
+	"math"
+	"sort"
+)
+
+// The supported path rankings
+const (
+	PathRankByErrorRate    string = "errorRate"
+	PathRankByResponseTime string = "responseTime"
+)
+
+// maxPaths limits the number of simple paths enumerated, which can grow exponentially with the graph size
+const maxPaths = 1000
+
+// maxPathSteps limits the number of edges traversed by the path enumeration, which in a dense graph can
+// be exponential even when few paths are found
+const maxPathSteps = 100000
+
+// PathHop is a single edge of a path
+type PathHop struct {
+	Source       string  `json:"source"`
+	Dest         string  `json:"dest"`
+	Protocol     string  `json:"protocol"`
+	ErrorRate    float64 `json:"errorRate"`    // percentage of requests in error
+	ResponseTime float64 `json:"responseTime"` // in millis, 0 if unavailable
+}
+
+// Path is a simple path between two nodes. CriticalHop is the index of the hop contributing the most to
+// the ranking, i.e. the slowest hop or the hop with the highest error rate.
+type Path struct {
+	CriticalHop  int       `json:"criticalHop"`
+	ErrorRate    float64   `json:"errorRate"` // percentage of requests in error on any hop
+	Hops         []PathHop `json:"hops"`
+	ResponseTime float64   `json:"responseTime"` // cumulative, in millis
+}
+
+// PathAnalysis holds the ranked paths between two nodes. Truncated is true if the path enumeration
+// stopped at the maximum number of paths, or of traversed edges.
+type PathAnalysis struct {
+	Dest      string `json:"dest"`
+	Paths     []Path `json:"paths"`
+	RankBy    string `json:"rankBy"`
+	Source    string `json:"source"`
+	Truncated bool   `json:"truncated"`
+}
+
+// FindPaths returns up to limit simple paths from the source node to the dest node, ranked in descending
+// order by rankBy. Parallel edges (i.e. different protocols) produce different paths.
+func FindPaths(trafficMap TrafficMap, sourceID, destID, rankBy string, limit int) PathAnalysis {
+	analysis := PathAnalysis{
+		Dest:   destID,
+		Paths:  []Path{},
+		RankBy: rankBy,
+		Source: sourceID,
+	}
+
+	source, ok := trafficMap[sourceID]
+	if !ok {
+		return analysis
+	}
+	if _, ok := trafficMap[destID]; !ok {
+		return analysis
+	}
+
+	// only descend into nodes from which the dest node can be reached
+	reachesDest := nodesReaching(trafficMap, destID)
+	if !reachesDest[sourceID] {
+		return analysis
+	}
+
+	steps := 0
+	visited := map[string]bool{sourceID: true}
+	hops := []PathHop{}
+	var visit func(n *Node)
+	visit = func(n *Node) {
+		for _, e := range n.Edges {
+			if analysis.Truncated {
+				return
+			}
+			if visited[e.Dest.ID] || !reachesDest[e.Dest.ID] {
+				continue
+			}
+			if steps++; steps > maxPathSteps {
+				analysis.Truncated = true
+				return
+			}
+			hops = append(hops, newPathHop(e))
+			if e.Dest.ID == destID {
+				if len(analysis.Paths) == maxPaths {
+					analysis.Truncated = true
+				} else {
+					analysis.Paths = append(analysis.Paths, newPath(hops, rankBy))
+				}
+			} else {
+				visited[e.Dest.ID] = true
+				visit(e.Dest)
+				delete(visited, e.Dest.ID)
+			}
+			hops = hops[:len(hops)-1]
+		}
+	}
+	visit(source)
+
+	sort.SliceStable(analysis.Paths, func(i, j int) bool {
+		pi, pj := analysis.Paths[i], analysis.Paths[j]
+		if rankBy == PathRankByErrorRate && pi.ErrorRate != pj.ErrorRate {
+			return pi.ErrorRate > pj.ErrorRate
+		}
+		if pi.ResponseTime != pj.ResponseTime {
+			return pi.ResponseTime > pj.ResponseTime
+		}
+		return len(pi.Hops) < len(pj.Hops)
+	})
+	if len(analysis.Paths) > limit {
+		analysis.Paths = analysis.Paths[:limit]
+	}
+
+	return analysis
+}
+
+// nodesReaching returns the IDs of the nodes with a path to the dest node, including the dest node itself
+func nodesReaching(trafficMap TrafficMap, destID string) map[string]bool {
+	incoming := make(map[string][]string)
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			incoming[e.Dest.ID] = append(incoming[e.Dest.ID], n.ID)
+		}
+	}
+
+	reaching := map[string]bool{destID: true}
+	queue := []string{destID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, sourceID := range incoming[id] {
+			if !reaching[sourceID] {
+				reaching[sourceID] = true
+				queue = append(queue, sourceID)
+			}
+		}
+	}
+	return reaching
+}
+
+func newPathHop(e *Edge) PathHop {
+	hop := PathHop{
+		Source:       e.Source.ID,
+		Dest:         e.Dest.ID,
+		ResponseTime: getValue(e.Metadata, ResponseTime),
+	}
+	if protocol, ok := e.Metadata[ProtocolKey].(string); ok {
+		hop.Protocol = protocol
+		for _, p := range Protocols {
+			if p.Name == protocol {
+				hop.ErrorRate = percentErrOf(p, e.Metadata)
+			}
+		}
+	}
+	return hop
+}
+
+// newPath returns a Path for a copy of the hops. The path error rate is the chance of a request failing
+// on any hop, assuming independent failures.
+func newPath(hops []PathHop, rankBy string) Path {
+	path := Path{Hops: append([]PathHop{}, hops...)}
+
+	success := 1.0
+	for i, hop := range path.Hops {
+		path.ResponseTime += hop.ResponseTime
+		success *= 1.0 - hop.ErrorRate/100.0
+
+		critical := path.Hops[path.CriticalHop]
+		if rankBy == PathRankByErrorRate {
+			if hop.ErrorRate > critical.ErrorRate {
+				path.CriticalHop = i
+			}
+		} else if hop.ResponseTime > critical.ResponseTime {
+			path.CriticalHop = i
+		}
+	}
+	path.ErrorRate = math.Round((1.0-success)*100000.0) / 1000.0
+
+	return path
+}
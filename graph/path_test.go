@@ -0,0 +1,130 @@
+package graph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockPathTrafficMap returns ingress -> productpage -> reviews-v1|reviews-v2 -> ratings, with
+// a reviews-v2 -> productpage cycle
+func mockPathTrafficMap() TrafficMap {
+	trafficMap := NewTrafficMap()
+	newNode := func(workload string) *Node {
+		n := NewNode("east", "bookinfo", "", "bookinfo", workload, "", "", GraphTypeWorkload)
+		trafficMap[n.ID] = &n
+		return &n
+	}
+	addEdge := func(source, dest *Node, rate, errRate, responseTime float64) {
+		e := source.AddEdge(dest)
+		e.Metadata[ProtocolKey] = "http"
+		AddToMetadata("http", rate-errRate, "200", "-", "", source.Metadata, dest.Metadata, e.Metadata)
+		AddToMetadata("http", errRate, "500", "-", "", source.Metadata, dest.Metadata, e.Metadata)
+		e.Metadata[ResponseTime] = responseTime
+	}
+
+	ingress := newNode("ingress")
+	productpage := newNode("productpage")
+	reviewsV1 := newNode("reviews-v1")
+	reviewsV2 := newNode("reviews-v2")
+	ratings := newNode("ratings")
+
+	addEdge(ingress, productpage, 10.0, 0.0, 50.0)
+	addEdge(productpage, reviewsV1, 5.0, 0.0, 10.0)
+	addEdge(productpage, reviewsV2, 5.0, 0.0, 30.0)
+	addEdge(reviewsV1, ratings, 5.0, 2.5, 5.0)
+	addEdge(reviewsV2, ratings, 5.0, 0.5, 60.0)
+	addEdge(reviewsV2, productpage, 1.0, 0.0, 1.0)
+
+	return trafficMap
+}
+
+func TestFindPathsByResponseTime(t *testing.T) {
+	assert := assert.New(t)
+
+	analysis := FindPaths(mockPathTrafficMap(), "wl_east_bookinfo_ingress", "wl_east_bookinfo_ratings", PathRankByResponseTime, 10)
+	assert.False(analysis.Truncated)
+	assert.Equal(2, len(analysis.Paths))
+
+	slowest := analysis.Paths[0]
+	assert.Equal(140.0, slowest.ResponseTime)
+	assert.Equal(3, len(slowest.Hops))
+	assert.Equal("wl_east_bookinfo_reviews-v2", slowest.Hops[1].Dest)
+	assert.Equal(2, slowest.CriticalHop)
+	assert.Equal(10.0, slowest.ErrorRate)
+
+	fastest := analysis.Paths[1]
+	assert.Equal(65.0, fastest.ResponseTime)
+	assert.Equal(0, fastest.CriticalHop)
+	assert.Equal(50.0, fastest.ErrorRate)
+	assert.Equal(50.0, fastest.Hops[2].ErrorRate)
+}
+
+func TestFindPathsByErrorRate(t *testing.T) {
+	assert := assert.New(t)
+
+	analysis := FindPaths(mockPathTrafficMap(), "wl_east_bookinfo_ingress", "wl_east_bookinfo_ratings", PathRankByErrorRate, 1)
+	assert.Equal(1, len(analysis.Paths))
+	assert.Equal("wl_east_bookinfo_reviews-v1", analysis.Paths[0].Hops[1].Dest)
+	assert.Equal(2, analysis.Paths[0].CriticalHop)
+}
+
+func TestFindPathsNotFound(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := mockPathTrafficMap()
+
+	// no path in the reverse direction
+	analysis := FindPaths(trafficMap, "wl_east_bookinfo_ratings", "wl_east_bookinfo_ingress", PathRankByResponseTime, 10)
+	assert.Equal(0, len(analysis.Paths))
+
+	analysis = FindPaths(trafficMap, "wl_east_bookinfo_ingress", "wl_east_bookinfo_unknown", PathRankByResponseTime, 10)
+	assert.Equal(0, len(analysis.Paths))
+	assert.Equal("wl_east_bookinfo_unknown", analysis.Dest)
+}
+
+// mockDensePathTrafficMap returns source -> dest, and source -> a clique of n nodes that only leads back to source
+func mockDensePathTrafficMap(n int) TrafficMap {
+	trafficMap := NewTrafficMap()
+	newNode := func(workload string) *Node {
+		node := NewNode("east", "bookinfo", "", "bookinfo", workload, "", "", GraphTypeWorkload)
+		trafficMap[node.ID] = &node
+		return &node
+	}
+
+	source := newNode("source")
+	source.AddEdge(newNode("dest"))
+	clique := make([]*Node, n)
+	for i := range clique {
+		clique[i] = newNode(fmt.Sprintf("clique-%d", i))
+		source.AddEdge(clique[i])
+		clique[i].AddEdge(source)
+	}
+	for _, a := range clique {
+		for _, b := range clique {
+			if a != b {
+				a.AddEdge(b)
+			}
+		}
+	}
+
+	return trafficMap
+}
+
+func TestFindPathsDense(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := mockDensePathTrafficMap(20)
+
+	// the clique can not reach the unknown node, it is not searched
+	trafficMap["wl_east_bookinfo_unknown"] = &Node{ID: "wl_east_bookinfo_unknown"}
+	analysis := FindPaths(trafficMap, "wl_east_bookinfo_clique-0", "wl_east_bookinfo_unknown", PathRankByResponseTime, 10)
+	assert.False(analysis.Truncated)
+	assert.Equal(0, len(analysis.Paths))
+
+	// the clique can reach dest only through source, the search is stopped
+	analysis = FindPaths(trafficMap, "wl_east_bookinfo_source", "wl_east_bookinfo_dest", PathRankByResponseTime, 10)
+	assert.True(analysis.Truncated)
+	assert.Equal(1, len(analysis.Paths))
+}
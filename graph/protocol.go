@@ -125,6 +125,8 @@ var HTTP = Protocol{
 //
 const (
 	tcp            = "tcp"
+	tcpClosed      = "tcpClosed"    // connections closed per second, only for rateTcp=connections
+	tcpLongLived   = "tcpLongLived" // estimated open connections opened prior to the time window, only for rateTcp=connections
 	tcpResponses   = "tcpResponses"
 	tcpIn          = "tcpIn"
 	tcpOut         = "tcpOut"
@@ -137,6 +139,8 @@ var TCP = Protocol{
 	Name: tcp,
 	EdgeRates: []Rate{
 		{Name: tcp, IsTotal: true, Precision: 2},
+		{Name: tcpClosed, Precision: 2},
+		{Name: tcpLongLived, Precision: 0},
 	},
 	EdgeResponses: tcpResponses,
 	NodeRates: []Rate{
@@ -168,11 +172,35 @@ func AddToMetadata(protocol string, val float64, code, flags, host string, sourc
 	}
 }
 
+// AddToMetadataTCPConnections adds TCP connection information to the edge. For rateTcp=connections the edge's
+// tcp rate is the rate of opened connections (see AddToMetadata), this adds the rate of closed connections and
+// the estimated number of long-lived connections.
+func AddToMetadataTCPConnections(closedVal, longLivedVal float64, edgeMetadata Metadata) {
+	addToMetadataValue(edgeMetadata, tcpClosed, closedVal)
+	addToMetadataValue(edgeMetadata, tcpLongLived, longLivedVal)
+}
+
+// AddRouteToMetadata adds a single traffic value to the edge's breakdown of the response code by route. The
+// route is typically the route name, or the request path. It complements AddToMetadata, and is a no-op for
+// protocols without routes (i.e. tcp).
+func AddRouteToMetadata(protocol string, val float64, code, route string, edgeMetadata Metadata) {
+	if val <= 0.0 || route == "" {
+		return
+	}
+
+	switch protocol {
+	case grpc:
+		addToMetadataResponses(edgeMetadata, grpcResponses, code, "", "", route, val)
+	case http:
+		addToMetadataResponses(edgeMetadata, httpResponses, code, "", "", route, val)
+	}
+}
+
 func addToMetadataGrpc(val float64, code, flags, host string, sourceMetadata, destMetadata, edgeMetadata Metadata) {
 	addToMetadataValue(sourceMetadata, grpcOut, val)
 	addToMetadataValue(destMetadata, grpcIn, val)
 	addToMetadataValue(edgeMetadata, grpc, val)
-	addToMetadataResponses(edgeMetadata, grpcResponses, code, flags, host, val)
+	addToMetadataResponses(edgeMetadata, grpcResponses, code, flags, host, "", val)
 
 	switch {
 	case code == "-":
@@ -198,7 +226,7 @@ func addToMetadataHTTP(val float64, code, flags, host string, sourceMetadata, de
 	addToMetadataValue(sourceMetadata, httpOut, val)
 	addToMetadataValue(destMetadata, httpIn, val)
 	addToMetadataValue(edgeMetadata, http, val)
-	addToMetadataResponses(edgeMetadata, httpResponses, code, flags, host, val)
+	addToMetadataResponses(edgeMetadata, httpResponses, code, flags, host, "", val)
 
 	// note, we don't track 2xx because it's not used downstream and can be easily
 	// calculated: 2xx = (rate - NoResponse - 3xx - 4xx - 5xx)
@@ -222,7 +250,7 @@ func addToMetadataTCP(val float64, flags, host string, sourceMetadata, destMetad
 	addToMetadataValue(sourceMetadata, tcpOut, val)
 	addToMetadataValue(destMetadata, tcpIn, val)
 	addToMetadataValue(edgeMetadata, tcp, val)
-	addToMetadataResponses(edgeMetadata, tcpResponses, "-", flags, host, val)
+	addToMetadataResponses(edgeMetadata, tcpResponses, "-", flags, host, "", val)
 }
 
 // IsHTTPErr return true if code is 4xx or 5xx
@@ -235,6 +263,23 @@ func IsGRPCErr(code string) bool {
 	return code != "0" && code != ""
 }
 
+// EdgeErrRate returns the rate of requests in error for the edge, as defined by the edge protocol. It is 0
+// for protocols without errors (i.e. tcp).
+func EdgeErrRate(edge *Edge) float64 {
+	errRate := 0.0
+	for _, p := range Protocols {
+		if p.Name != edge.Metadata[ProtocolKey] {
+			continue
+		}
+		for _, r := range p.EdgeRates {
+			if r.IsErr {
+				errRate += getValue(edge.Metadata, r.Name)
+			}
+		}
+	}
+	return errRate
+}
+
 // AddOutgoingEdgeToMetadata updates the source node's outgoing traffic with the outgoing edge traffic value
 func AddOutgoingEdgeToMetadata(sourceMetadata, edgeMetadata Metadata) {
 	if val, valOk := edgeMetadata[grpc]; valOk {
@@ -305,6 +350,12 @@ func AggregateEdgeTraffic(edge, aggregateEdge *Edge) {
 		if val, ok := edge.Metadata[tcp]; ok {
 			addToMetadataValue(aggregateEdge.Metadata, tcp, val.(float64))
 		}
+		if val, ok := edge.Metadata[tcpClosed]; ok {
+			addToMetadataValue(aggregateEdge.Metadata, tcpClosed, val.(float64))
+		}
+		if val, ok := edge.Metadata[tcpLongLived]; ok {
+			addToMetadataValue(aggregateEdge.Metadata, tcpLongLived, val.(float64))
+		}
 		if responses, ok := edge.Metadata[tcpResponses]; ok {
 			addToResponses(aggregateEdge.Metadata, tcpResponses, responses.(Responses))
 		}
@@ -340,6 +391,7 @@ func addToMetadataValue(md Metadata, k MetadataKey, v float64) {
 //      "www.google.com" : 100.00
 //    },
 //  } ...
+// When requested, a response code is also broken down by routes:percentageOfTraffic.
 
 // ResponseFlags maps flags to request percentage
 type ResponseFlags map[string]float64
@@ -347,10 +399,14 @@ type ResponseFlags map[string]float64
 // ResponseHosts maps hosts to request percentage
 type ResponseHosts map[string]float64
 
+// ResponseRoutes maps routes to request percentage
+type ResponseRoutes map[string]float64
+
 // ResponseDetail consolidates response detail for a response code
 type ResponseDetail struct {
-	Flags ResponseFlags
-	Hosts ResponseHosts
+	Flags  ResponseFlags
+	Hosts  ResponseHosts
+	Routes ResponseRoutes
 }
 
 // Responses maps codes to ResponseDetail
@@ -359,15 +415,18 @@ type Responses map[string]*ResponseDetail
 func addToResponses(md Metadata, k MetadataKey, responses Responses) {
 	for code, detailsValMap := range responses {
 		for flags, val := range detailsValMap.Flags {
-			addToMetadataResponses(md, k, code, flags, "", val)
+			addToMetadataResponses(md, k, code, flags, "", "", val)
 		}
 		for host, val := range detailsValMap.Hosts {
-			addToMetadataResponses(md, k, code, "", host, val)
+			addToMetadataResponses(md, k, code, "", host, "", val)
+		}
+		for route, val := range detailsValMap.Routes {
+			addToMetadataResponses(md, k, code, "", "", route, val)
 		}
 	}
 }
 
-func addToMetadataResponses(md Metadata, k MetadataKey, code, flags, host string, v float64) {
+func addToMetadataResponses(md Metadata, k MetadataKey, code, flags, host, route string, v float64) {
 	if md == nil {
 		return
 	}
@@ -399,6 +458,12 @@ func addToMetadataResponses(md Metadata, k MetadataKey, code, flags, host string
 	if host != "" {
 		responseDetail.Hosts[host] += v
 	}
+	if route != "" {
+		if responseDetail.Routes == nil {
+			responseDetail.Routes = ResponseRoutes{}
+		}
+		responseDetail.Routes[route] += v
+	}
 }
 
 // averageMetadataValue is currently unused but shows how to perform averaging using metadata values.
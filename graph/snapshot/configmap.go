@@ -0,0 +1,111 @@
+package snapshot
+
+import (
+	"encoding/json"
+
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/log"
+)
+
+const (
+	configMapDataKey        = "snapshot.json"
+	configMapInfoAnnotation = "kiali.io/graph-snapshot-info" // the Info, to list snapshots without decoding them
+	configMapLabel          = "kiali.io/graph-snapshot"
+	configMapNamePrefix     = "kiali-graph-snapshot-"
+)
+
+// ConfigMapStore persists each snapshot as a ConfigMap in a namespace. Note that a ConfigMap is limited
+// to 1MiB, saving a larger snapshot fails.
+type ConfigMapStore struct {
+	Client    kubernetes.ClientInterface
+	Namespace string
+}
+
+// NewConfigMapStore returns a store for the namespace
+func NewConfigMapStore(client kubernetes.ClientInterface, namespace string) ConfigMapStore {
+	return ConfigMapStore{Client: client, Namespace: namespace}
+}
+
+// Delete implements Store
+func (s ConfigMapStore) Delete(id string) error {
+	if err := ValidateID(id); err != nil {
+		return err
+	}
+	if err := s.Client.DeleteConfigMap(s.Namespace, configMapNamePrefix+id); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// Get implements Store
+func (s ConfigMapStore) Get(id string) (*Snapshot, error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+	configMap, err := s.Client.GetConfigMap(s.Namespace, configMapNamePrefix+id)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if _, ok := configMap.Labels[configMapLabel]; !ok {
+		return nil, ErrNotFound
+	}
+
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal([]byte(configMap.Data[configMapDataKey]), snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// List implements Store
+func (s ConfigMapStore) List() ([]Info, error) {
+	configMaps, err := s.Client.GetConfigMaps(s.Namespace, configMapLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := []Info{}
+	for _, configMap := range configMaps {
+		info := Info{}
+		if err := json.Unmarshal([]byte(configMap.Annotations[configMapInfoAnnotation]), &info); err != nil {
+			log.Warningf("Skipping unreadable graph snapshot [%s]: %v", configMap.Name, err)
+			continue
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// Save implements Store
+func (s ConfigMapStore) Save(snapshot *Snapshot) error {
+	if err := ValidateID(snapshot.Info.ID); err != nil {
+		return err
+	}
+	info, err := json.Marshal(snapshot.Info)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	configMap := &core_v1.ConfigMap{
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:        configMapNamePrefix + snapshot.Info.ID,
+			Namespace:   s.Namespace,
+			Labels:      map[string]string{configMapLabel: "true"},
+			Annotations: map[string]string{configMapInfoAnnotation: string(info)},
+		},
+		Data: map[string]string{configMapDataKey: string(data)},
+	}
+	_, err = s.Client.CreateConfigMap(s.Namespace, configMap)
+	return err
+}
@@ -0,0 +1,105 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kiali/kiali/log"
+)
+
+const fileExtension = ".json"
+
+// FileStore persists each snapshot as a JSON file in a local directory
+type FileStore struct {
+	Directory string
+}
+
+// NewFileStore returns a store for the directory, created on the first save
+func NewFileStore(directory string) FileStore {
+	return FileStore{Directory: directory}
+}
+
+func (s FileStore) path(id string) string {
+	return filepath.Join(s.Directory, id+fileExtension)
+}
+
+// Delete implements Store
+func (s FileStore) Delete(id string) error {
+	if err := ValidateID(id); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Get implements Store
+func (s FileStore) Get(id string) (*Snapshot, error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+	bytes, err := ioutil.ReadFile(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(bytes, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// List implements Store
+func (s FileStore) List() ([]Info, error) {
+	files, err := ioutil.ReadDir(s.Directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Info{}, nil
+		}
+		return nil, err
+	}
+
+	infos := []Info{}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), fileExtension) {
+			continue
+		}
+		bytes, err := ioutil.ReadFile(filepath.Join(s.Directory, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		// decode only the info, skipping the config and options
+		snapshot := struct {
+			Info Info `json:"info"`
+		}{}
+		if err := json.Unmarshal(bytes, &snapshot); err != nil {
+			log.Warningf("Skipping unreadable graph snapshot [%s]: %v", f.Name(), err)
+			continue
+		}
+		infos = append(infos, snapshot.Info)
+	}
+	return infos, nil
+}
+
+// Save implements Store
+func (s FileStore) Save(snapshot *Snapshot) error {
+	if err := ValidateID(snapshot.Info.ID); err != nil {
+		return err
+	}
+	bytes, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.Directory, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path(snapshot.Info.ID), bytes, 0600)
+}
@@ -0,0 +1,165 @@
+// Package snapshot provides the persistence of generated graphs, so that a graph can later be replayed
+// exactly as it was generated (e.g. for incident reviews).
+package snapshot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/kubernetes"
+)
+
+// The supported snapshot stores
+const (
+	StoreConfigMap = "configmap"
+	StoreFile      = "file"
+)
+
+// ErrNotFound is returned by a Store when the requested snapshot does not exist
+var ErrNotFound = errors.New("graph snapshot not found")
+
+var validID = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// Info describes a saved snapshot
+type Info struct {
+	Created    time.Time `json:"created"`
+	Duration   string    `json:"duration"` // the graph's time range duration
+	GraphType  string    `json:"graphType"`
+	ID         string    `json:"id"`
+	Name       string    `json:"name,omitempty"` // optional, user-supplied
+	Namespaces []string  `json:"namespaces"`
+	QueryTime  int64     `json:"queryTime"` // unix time in seconds, the end of the graph's time range
+}
+
+// Snapshot is a saved graph, holding the generated config and the options used to generate it
+type Snapshot struct {
+	Config  cytoscape.Config `json:"config"`
+	Info    Info             `json:"info"`
+	Options graph.Options    `json:"options"`
+}
+
+// Store persists snapshots
+type Store interface {
+	// Delete removes the snapshot, it is not an error if the snapshot does not exist
+	Delete(id string) error
+	// Get returns the snapshot, or ErrNotFound
+	Get(id string) (*Snapshot, error)
+	// List returns the info of every snapshot, in no particular order
+	List() ([]Info, error)
+	// Save persists a new snapshot
+	Save(snapshot *Snapshot) error
+}
+
+// NewSnapshot returns a new snapshot of the config, generated using the provided options. The accessible
+// namespaces are specific to the requesting user, and are not saved.
+func NewSnapshot(name string, cytoConfig cytoscape.Config, o graph.Options) (*Snapshot, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	o.AccessibleNamespaces = nil
+
+	namespaces := make([]string, 0, len(o.Namespaces))
+	for namespace := range o.Namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	return &Snapshot{
+		Config: cytoConfig,
+		Info: Info{
+			Created:    time.Now().UTC(),
+			Duration:   o.TelemetryOptions.Duration.String(),
+			GraphType:  o.TelemetryOptions.GraphType,
+			ID:         id,
+			Name:       name,
+			Namespaces: namespaces,
+			QueryTime:  o.TelemetryOptions.QueryTime,
+		},
+		Options: o,
+	}, nil
+}
+
+// newID returns a unique, sortable ID usable as a file name and as part of a Kubernetes resource name
+func newID() (string, error) {
+	random := make([]byte, 4)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%s", time.Now().Unix(), hex.EncodeToString(random)), nil
+}
+
+// ValidateID returns an error if the ID can't identify a snapshot
+func ValidateID(id string) error {
+	if !validID.MatchString(id) {
+		return fmt.Errorf("invalid graph snapshot ID [%s]", id)
+	}
+	return nil
+}
+
+// NewStore returns the configured snapshot store. The configmap store requires a client able to manage the
+// ConfigMaps of the Kiali deployment namespace.
+func NewStore(client kubernetes.ClientInterface) (Store, error) {
+	cfg := config.Get()
+	switch cfg.Graph.Snapshots.Store {
+	case StoreConfigMap:
+		return NewConfigMapStore(client, cfg.Deployment.Namespace), nil
+	case StoreFile:
+		return NewFileStore(cfg.Graph.Snapshots.Directory), nil
+	default:
+		return nil, fmt.Errorf("unsupported graph snapshot store [%s], must be one of: %s | %s", cfg.Graph.Snapshots.Store, StoreConfigMap, StoreFile)
+	}
+}
+
+// ApplyRetention removes the snapshots older than maxAge, and the oldest snapshots of the namespaces beyond maxCount.
+// The count applies per set of namespaces, so that saving snapshots of some namespaces does not remove the snapshots
+// of others. A limit of 0 is no limit.
+func ApplyRetention(store Store, namespaces []string, maxCount int, maxAge time.Duration) error {
+	infos, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	// most recent first
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Created.After(infos[j].Created)
+	})
+
+	now := time.Now()
+	count := 0
+	for _, info := range infos {
+		remove := maxAge > 0 && now.Sub(info.Created) > maxAge
+		if sameNamespaces(info.Namespaces, namespaces) {
+			count++
+			remove = remove || (maxCount > 0 && count > maxCount)
+		}
+		if remove {
+			if err := store.Delete(info.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sameNamespaces returns true if both sorted lists hold the same namespaces
+func sameNamespaces(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
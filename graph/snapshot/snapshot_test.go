@@ -0,0 +1,161 @@
+package snapshot
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+)
+
+func mockSnapshot(t *testing.T, name string, created time.Time) *Snapshot {
+	o := graph.Options{ConfigVendor: graph.VendorCytoscape, TelemetryVendor: graph.VendorIstio}
+	o.ConfigOptions.BoxBy = graph.BoxByNamespace
+	o.TelemetryOptions.GraphType = graph.GraphTypeVersionedApp
+	o.TelemetryOptions.Duration = 10 * time.Minute
+	o.TelemetryOptions.QueryTime = 1000
+	o.Namespaces = graph.NamespaceInfoMap{
+		"tutorial": graph.NamespaceInfo{Name: "tutorial"},
+		"bookinfo": graph.NamespaceInfo{Name: "bookinfo"},
+	}
+	o.AccessibleNamespaces = map[string]time.Time{"bookinfo": {}, "tutorial": {}}
+
+	cytoConfig := cytoscape.Config{Timestamp: 1000, Duration: 600, GraphType: graph.GraphTypeVersionedApp}
+	s, err := NewSnapshot(name, cytoConfig, o)
+	assert.NoError(t, err)
+	s.Info.Created = created
+	return s
+}
+
+func TestNewSnapshot(t *testing.T) {
+	assert := assert.New(t)
+
+	s := mockSnapshot(t, "incident", time.Now())
+	assert.NoError(ValidateID(s.Info.ID))
+	assert.Equal("incident", s.Info.Name)
+	assert.Equal([]string{"bookinfo", "tutorial"}, s.Info.Namespaces)
+	assert.Equal("10m0s", s.Info.Duration)
+	assert.Equal(graph.GraphTypeVersionedApp, s.Info.GraphType)
+	assert.Equal(int64(1000), s.Info.QueryTime)
+	assert.Nil(s.Options.AccessibleNamespaces)
+
+	assert.Error(ValidateID("../kiali"))
+	assert.Error(ValidateID(""))
+}
+
+func TestFileStore(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "graph-snapshots")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	// the directory is created on the first save
+	store := NewFileStore(dir + "/snapshots")
+	infos, err := store.List()
+	assert.NoError(err)
+	assert.Empty(infos)
+
+	s := mockSnapshot(t, "incident", time.Now())
+	assert.NoError(store.Save(s))
+
+	infos, err = store.List()
+	assert.NoError(err)
+	assert.Equal(1, len(infos))
+	assert.Equal(s.Info.ID, infos[0].ID)
+
+	saved, err := store.Get(s.Info.ID)
+	assert.NoError(err)
+	assert.Equal(s.Config, saved.Config)
+	assert.Equal(graph.BoxByNamespace, saved.Options.BoxBy)
+	assert.Equal(graph.GraphTypeVersionedApp, saved.Options.TelemetryOptions.GraphType)
+	assert.Equal(10*time.Minute, saved.Options.TelemetryOptions.Duration)
+
+	assert.NoError(store.Delete(s.Info.ID))
+	_, err = store.Get(s.Info.ID)
+	assert.Equal(ErrNotFound, err)
+	assert.NoError(store.Delete(s.Info.ID))
+}
+
+func TestApplyRetention(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "graph-snapshots")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	store := NewFileStore(dir)
+	now := time.Now()
+	var snapshots []*Snapshot
+	for _, age := range []time.Duration{time.Minute, time.Hour, 2 * time.Hour, 48 * time.Hour} {
+		s := mockSnapshot(t, age.String(), now.Add(-age))
+		assert.NoError(store.Save(s))
+		snapshots = append(snapshots, s)
+	}
+
+	other := mockSnapshot(t, "other", now.Add(-3*time.Hour))
+	other.Info.Namespaces = []string{"bookinfo"}
+	assert.NoError(store.Save(other))
+
+	// max age removes the 48h snapshot
+	assert.NoError(ApplyRetention(store, []string{"bookinfo", "tutorial"}, 0, 24*time.Hour))
+	infos, _ := store.List()
+	assert.Equal(4, len(infos))
+
+	// max count removes the oldest of the same namespaces
+	assert.NoError(ApplyRetention(store, []string{"bookinfo", "tutorial"}, 2, 0))
+	infos, _ = store.List()
+	assert.Equal(3, len(infos))
+	_, err = store.Get(snapshots[2].Info.ID)
+	assert.Equal(ErrNotFound, err)
+	_, err = store.Get(snapshots[0].Info.ID)
+	assert.NoError(err)
+	_, err = store.Get(other.Info.ID)
+	assert.NoError(err)
+}
+
+func TestConfigMapStore(t *testing.T) {
+	assert := assert.New(t)
+
+	k8s := new(kubetest.K8SClientMock)
+	store := NewConfigMapStore(k8s, "istio-system")
+	s := mockSnapshot(t, "incident", time.Now())
+	name := configMapNamePrefix + s.Info.ID
+
+	var created *core_v1.ConfigMap
+	k8s.On("CreateConfigMap", "istio-system", mock.AnythingOfType("*v1.ConfigMap")).Run(func(args mock.Arguments) {
+		created = args.Get(1).(*core_v1.ConfigMap)
+	}).Return(&core_v1.ConfigMap{}, nil)
+	assert.NoError(store.Save(s))
+	assert.Equal(name, created.Name)
+	assert.Equal("true", created.Labels[configMapLabel])
+
+	k8s.On("GetConfigMaps", "istio-system", configMapLabel).Return([]core_v1.ConfigMap{*created}, nil)
+	infos, err := store.List()
+	assert.NoError(err)
+	assert.Equal(1, len(infos))
+	assert.Equal(s.Info.ID, infos[0].ID)
+	assert.Equal("incident", infos[0].Name)
+
+	k8s.On("GetConfigMap", "istio-system", name).Return(created, nil)
+	saved, err := store.Get(s.Info.ID)
+	assert.NoError(err)
+	assert.Equal(s.Config, saved.Config)
+
+	notFound := errors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "missing")
+	k8s.On("GetConfigMap", "istio-system", configMapNamePrefix+"missing").Return(&core_v1.ConfigMap{}, notFound)
+	_, err = store.Get("missing")
+	assert.Equal(ErrNotFound, err)
+
+	k8s.On("DeleteConfigMap", "istio-system", configMapNamePrefix+"missing").Return(notFound)
+	assert.NoError(store.Delete("missing"))
+}
@@ -1,12 +1,25 @@
 package graph
 
 import (
+	"fmt"
+	"sort"
+	"sync"
+
 	"github.com/kiali/kiali/prometheus"
 )
 
-// TelemetryVendor is an interface that must be satisfied for each telemetry implementation.
+// TelemetryVendor is an interface that must be satisfied for each telemetry implementation.  An implementation
+// is made available by registering it, typically from an init() function in the implementing package.
 type TelemetryVendor interface {
 
+	// Name is required by the TelemetryVendor interface.  It must return the unique vendor name, which is
+	// the value of the telemetryVendor query param used to select the vendor.
+	Name() string
+
+	// Appenders is required by the TelemetryVendor interface.  It must return the names of the appenders
+	// supported by the vendor, which may be requested via the appenders query param.
+	Appenders() []string
+
 	// BuildNamespaceTrafficMap is required by the TelemetryVendor interface.  It must produce a valid
 	// TrafficMap for the requested namespaces, It is recommended to use the graph/util.go definitions for
 	// error handling. It should be modeled after the Istio implementation.
@@ -17,3 +30,41 @@ type TelemetryVendor interface {
 	// error handling. It should be modeled after the Istio implementation.
 	BuildNodeTrafficMap(o TelemetryOptions, client *prometheus.Client, globalInfo *AppenderGlobalInfo) TrafficMap
 }
+
+var (
+	telemetryVendors      = make(map[string]TelemetryVendor) // key=vendor name
+	telemetryVendorsMutex sync.RWMutex
+)
+
+// RegisterTelemetryVendor makes the telemetry vendor available by name. It panics if the name is already registered.
+func RegisterTelemetryVendor(vendor TelemetryVendor) {
+	telemetryVendorsMutex.Lock()
+	defer telemetryVendorsMutex.Unlock()
+
+	if _, found := telemetryVendors[vendor.Name()]; found {
+		panic(fmt.Sprintf("TelemetryVendor [%s] is already registered", vendor.Name()))
+	}
+	telemetryVendors[vendor.Name()] = vendor
+}
+
+// GetTelemetryVendor returns the registered telemetry vendor with the provided name, or false if not found
+func GetTelemetryVendor(name string) (TelemetryVendor, bool) {
+	telemetryVendorsMutex.RLock()
+	defer telemetryVendorsMutex.RUnlock()
+
+	vendor, found := telemetryVendors[name]
+	return vendor, found
+}
+
+// TelemetryVendorNames returns the sorted names of the registered telemetry vendors
+func TelemetryVendorNames() []string {
+	telemetryVendorsMutex.RLock()
+	defer telemetryVendorsMutex.RUnlock()
+
+	names := make([]string, 0, len(telemetryVendors))
+	for name := range telemetryVendors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
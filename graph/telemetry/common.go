@@ -8,8 +8,24 @@ import (
 
 	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus/internalmetrics"
 )
 
+// RunAppenders runs the appenders, in order, on a namespace (or node) traffic map. It is the common
+// appender pipeline, usable by any telemetry vendor producing a TrafficMap.
+func RunAppenders(appenders []graph.Appender, trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo, namespaceInfo *graph.AppenderNamespaceInfo) {
+	for _, a := range appenders {
+		appenderTimer := internalmetrics.GetGraphAppenderTimePrometheusTimer(a.Name())
+		a.AppendGraph(trafficMap, globalInfo, namespaceInfo)
+		appenderTimer.ObserveDuration()
+	}
+}
+
+// RunFinalizers runs the finalizers on the final TrafficMap, see RunAppenders. There is no namespace info.
+func RunFinalizers(finalizers []graph.Appender, trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo) {
+	RunAppenders(finalizers, trafficMap, globalInfo, nil)
+}
+
 // MergeTrafficMaps typically combines two namespace traffic maps. It ensures that we only
 // have unique nodes by removing duplicate nodes and merging their edges.  When removing a
 // duplicate prefer an instance from the namespace being merged-in because it is guaranteed
@@ -47,6 +63,17 @@ func MergeTrafficMaps(trafficMap graph.TrafficMap, ns string, nsTrafficMap graph
 	}
 }
 
+// MarkCrossClusterEdges sets metadata for the edges between nodes of different, known, clusters.
+func MarkCrossClusterEdges(trafficMap graph.TrafficMap) {
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			if graph.IsOK(n.Cluster) && graph.IsOK(e.Dest.Cluster) && n.Cluster != e.Dest.Cluster {
+				e.Metadata[graph.IsCrossCluster] = true
+			}
+		}
+	}
+}
+
 // MarkOutsideOrInaccessible sets metadata for outsider and inaccessible nodes.  It should be called
 // after all appender work is completed.
 func MarkOutsideOrInaccessible(trafficMap graph.TrafficMap, o graph.TelemetryOptions) {
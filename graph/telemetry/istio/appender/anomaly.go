@@ -0,0 +1,249 @@
+package appender
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/telemetry/istio/util"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus"
+)
+
+const (
+	// AnomalyAppenderName uniquely identifies the appender: anomaly
+	AnomalyAppenderName = "anomaly"
+
+	// The supported anomaly baselines
+	AnomalyBaselineLastWeek = "lastWeek" // the hour leading up to the same time last week
+	AnomalyBaselineTrailing = "24h"      // the 24 hours leading up to the current time window
+
+	// The anomaly measures, used as the AnomalyInfo z-score keys
+	anomalyErrorRate    = "errorRate"
+	anomalyRequestRate  = "requestRate"
+	anomalyResponseTime = "responseTime"
+
+	// anomalyResolution is the step used to sample the baseline rates
+	anomalyResolution = time.Minute
+)
+
+// AnomalyAppender is responsible for flagging the edges whose current request traffic deviates from their
+// baseline traffic. The baseline is a longer time window (the trailing 24h, or the same hour last week) sampled
+// with the same rate range as the current traffic. For each of request rate, error rate (5xx responses) and
+// average response time a z-score is calculated as (current - baseline mean) / baseline stddev. An edge is
+// anomalous when the absolute value of any z-score reaches the threshold. A node is anomalous when any of
+// its incoming edges is anomalous, and is assigned the incoming edge z-scores furthest from 0.
+// Note: like response times, z-scores can't be aggregated and so, when injecting service nodes, only the
+// service node's outgoing edges are scored.
+// Name: anomaly
+type AnomalyAppender struct {
+	Baseline           string
+	GraphType          string
+	InjectServiceNodes bool
+	Namespaces         graph.NamespaceInfoMap
+	QueryTime          int64 // unix time in seconds
+	Rates              graph.RequestedRates
+	Threshold          float64 // absolute z-score
+}
+
+// Name implements Appender
+func (a AnomalyAppender) Name() string {
+	return AnomalyAppenderName
+}
+
+// AppendGraph implements Appender
+func (a AnomalyAppender) AppendGraph(trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo, namespaceInfo *graph.AppenderNamespaceInfo) {
+	if len(trafficMap) == 0 {
+		return
+	}
+
+	// Anomalies only apply to request traffic (not TCP or gRPC-message traffic)
+	if a.Rates.Grpc != graph.RateRequests && a.Rates.Http != graph.RateRequests {
+		return
+	}
+
+	if globalInfo.PromClient == nil {
+		var err error
+		globalInfo.PromClient, err = prometheus.NewClient()
+		graph.CheckError(err)
+	}
+
+	a.appendGraph(trafficMap, namespaceInfo.Namespace, globalInfo.PromClient)
+}
+
+func (a AnomalyAppender) appendGraph(trafficMap graph.TrafficMap, namespace string, client *prometheus.Client) {
+	log.Tracef("Generating anomalies for baseline [%s]; namespace = %v", a.Baseline, namespace)
+
+	// create map to quickly look up z-scores, key=edge key, value=z-score by measure
+	zScoreMap := make(map[string]map[string]float64)
+	duration := int(a.Namespaces[namespace].Duration.Seconds())
+	groupBy := "source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol"
+
+	// query prometheus for the z-scores of each measure in two queries:
+	// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic
+	// 2) Outgoing: query source telemetry to capture namespace workloads' outgoing traffic
+	selectors := []string{
+		fmt.Sprintf(`reporter="destination",destination_service_namespace="%s"`, namespace),
+		fmt.Sprintf(`reporter="source",source_workload_namespace="%s"`, namespace),
+	}
+	for _, selector := range selectors {
+		rate := func(metric, labels string) string {
+			return fmt.Sprintf(`sum(rate(%s{%s%s}[%vs])) by (%s)`, metric, selector, labels, duration, groupBy)
+		}
+		measures := map[string]string{
+			anomalyErrorRate:    rate("istio_requests_total", `,response_code=~"5.*"`),
+			anomalyRequestRate:  rate("istio_requests_total", ""),
+			anomalyResponseTime: fmt.Sprintf("%s / %s", rate("istio_request_duration_milliseconds_sum", ""), rate("istio_request_duration_milliseconds_count", "")),
+		}
+		for _, measure := range []string{anomalyErrorRate, anomalyRequestRate, anomalyResponseTime} {
+			vector := promQuery(a.zScoreQuery(measures[measure], duration), time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
+			a.populateZScoreMap(zScoreMap, measure, &vector)
+		}
+	}
+
+	a.applyAnomalies(trafficMap, zScoreMap)
+}
+
+// zScoreQuery returns a query for the z-score of the current value of expr, relative to its baseline samples
+func (a AnomalyAppender) zScoreQuery(expr string, duration int) string {
+	window, offset := time.Duration(24)*time.Hour, time.Duration(duration)*time.Second
+	if a.Baseline == AnomalyBaselineLastWeek {
+		window, offset = time.Hour, time.Duration(7*24)*time.Hour
+	}
+	baseline := fmt.Sprintf("(%s)[%vs:%vs] offset %vs", expr, int(window.Seconds()), int(anomalyResolution.Seconds()), int(offset.Seconds()))
+
+	return fmt.Sprintf("(%s - avg_over_time(%s)) / stddev_over_time(%s)", expr, baseline, baseline)
+}
+
+func (a AnomalyAppender) applyAnomalies(trafficMap graph.TrafficMap, zScoreMap map[string]map[string]float64) {
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			key := fmt.Sprintf("%s %s %s", e.Source.ID, e.Dest.ID, e.Metadata[graph.ProtocolKey].(string))
+			zScores, ok := zScoreMap[key]
+			if !ok {
+				continue
+			}
+			e.Metadata[graph.Anomaly] = &graph.AnomalyInfo{ZScores: zScores}
+			if !a.isAnomalous(zScores) {
+				continue
+			}
+			e.Metadata[graph.IsAnomalous] = true
+
+			dest := e.Dest
+			dest.Metadata[graph.IsAnomalous] = true
+			destInfo, ok := dest.Metadata[graph.Anomaly].(*graph.AnomalyInfo)
+			if !ok {
+				destInfo = &graph.AnomalyInfo{ZScores: make(map[string]float64)}
+				dest.Metadata[graph.Anomaly] = destInfo
+			}
+			for measure, zScore := range zScores {
+				if current, ok := destInfo.ZScores[measure]; !ok || math.Abs(zScore) > math.Abs(current) {
+					destInfo.ZScores[measure] = zScore
+				}
+			}
+		}
+	}
+}
+
+func (a AnomalyAppender) isAnomalous(zScores map[string]float64) bool {
+	for _, zScore := range zScores {
+		if math.Abs(zScore) >= a.Threshold {
+			return true
+		}
+	}
+	return false
+}
+
+func (a AnomalyAppender) populateZScoreMap(zScoreMap map[string]map[string]float64, measure string, vector *model.Vector) {
+	skipRequestsGrpc := a.Rates.Grpc != graph.RateRequests
+	skipRequestsHttp := a.Rates.Http != graph.RateRequests
+
+	for _, s := range *vector {
+		m := s.Metric
+		lSourceCluster, sourceClusterOk := m["source_cluster"]
+		lSourceWlNs, sourceWlNsOk := m["source_workload_namespace"]
+		lSourceWl, sourceWlOk := m["source_workload"]
+		lSourceApp, sourceAppOk := m["source_canonical_service"]
+		lSourceVer, sourceVerOk := m["source_canonical_revision"]
+		lDestCluster, destClusterOk := m["destination_cluster"]
+		lDestSvcNs, destSvcNsOk := m["destination_service_namespace"]
+		lDestSvc, destSvcOk := m["destination_service"]
+		lDestSvcName, destSvcNameOk := m["destination_service_name"]
+		lDestWlNs, destWlNsOk := m["destination_workload_namespace"]
+		lDestWl, destWlOk := m["destination_workload"]
+		lDestApp, destAppOk := m["destination_canonical_service"]
+		lDestVer, destVerOk := m["destination_canonical_revision"]
+		lProtocol, protocolOk := m["request_protocol"]
+
+		if !sourceWlNsOk || !sourceWlOk || !sourceAppOk || !sourceVerOk || !destSvcNsOk || !destSvcNameOk || !destSvcOk || !destWlNsOk || !destWlOk || !destAppOk || !destVerOk || !protocolOk {
+			log.Warningf("populateZScoreMap: Skipping %s, missing expected labels", m.String())
+			continue
+		}
+
+		sourceWlNs := string(lSourceWlNs)
+		sourceWl := string(lSourceWl)
+		sourceApp := string(lSourceApp)
+		sourceVer := string(lSourceVer)
+		destSvc := string(lDestSvc)
+		protocol := string(lProtocol)
+
+		if (skipRequestsHttp && protocol == graph.HTTP.Name) || (skipRequestsGrpc && protocol == graph.GRPC.Name) {
+			continue
+		}
+
+		// handle clusters
+		sourceCluster, destCluster := util.HandleClusters(lSourceCluster, sourceClusterOk, lDestCluster, destClusterOk)
+
+		if util.IsBadSourceTelemetry(sourceCluster, sourceClusterOk, sourceWlNs, sourceWl, sourceApp) {
+			continue
+		}
+
+		val := float64(s.Value)
+
+		// handle unusual destinations
+		destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer, _ := util.HandleDestination(sourceCluster, sourceWlNs, sourceWl, destCluster, string(lDestSvcNs), string(lDestSvc), string(lDestSvcName), string(lDestWlNs), string(lDestWl), string(lDestApp), string(lDestVer))
+
+		if util.IsBadDestTelemetry(destCluster, destClusterOk, destSvcNs, destSvc, destSvcName, destWl) {
+			continue
+		}
+
+		// A flat baseline (stddev 0) produces NaN or Inf, there is no z-score, just skip it
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			continue
+		}
+
+		// don't inject a service node if destSvcName is not set or the dest node is already a service node.
+		inject := false
+		if a.InjectServiceNodes && graph.IsOK(destSvcName) {
+			_, destNodeType := graph.Id(destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer, a.GraphType)
+			inject = (graph.NodeTypeService != destNodeType)
+		}
+
+		if inject {
+			a.addZScore(zScoreMap, measure, val, protocol, destCluster, destSvcNs, destSvcName, "", "", "", destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer)
+		} else {
+			a.addZScore(zScoreMap, measure, val, protocol, sourceCluster, sourceWlNs, "", sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer)
+		}
+	}
+}
+
+func (a AnomalyAppender) addZScore(zScoreMap map[string]map[string]float64, measure string, val float64, protocol, sourceCluster, sourceNs, sourceSvc, sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvc, destWlNs, destWl, destApp, destVer string) {
+	sourceID, _ := graph.Id(sourceCluster, sourceNs, sourceSvc, sourceNs, sourceWl, sourceApp, sourceVer, a.GraphType)
+	destID, _ := graph.Id(destCluster, destSvcNs, destSvc, destWlNs, destWl, destApp, destVer, a.GraphType)
+	key := fmt.Sprintf("%s %s %s", sourceID, destID, protocol)
+
+	zScores, ok := zScoreMap[key]
+	if !ok {
+		zScores = make(map[string]float64)
+		zScoreMap[key] = zScores
+	}
+
+	// Several series may contribute to the same edge (e.g. the workloads of an app, or edges within the
+	// namespace reported by both queries). Z-scores can't be aggregated, keep the one furthest from 0.
+	if current, found := zScores[measure]; !found || math.Abs(val) > math.Abs(current) {
+		zScores[measure] = val
+	}
+}
@@ -0,0 +1,224 @@
+package appender
+
+import (
+	"math"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/graph"
+)
+
+func TestAnomaly(t *testing.T) {
+	assert := assert.New(t)
+
+	ingressToProductpage := model.Metric{
+		"source_cluster":                 business.DefaultClusterID,
+		"source_workload_namespace":      "istio-system",
+		"source_workload":                "ingressgateway-unknown",
+		"source_canonical_service":       "ingressgateway",
+		"source_canonical_revision":      model.LabelValue(graph.Unknown),
+		"destination_cluster":            business.DefaultClusterID,
+		"destination_service_namespace":  "bookinfo",
+		"destination_service":            "productpage.bookinfo.svc.cluster.local",
+		"destination_service_name":       "productpage",
+		"destination_workload_namespace": "bookinfo",
+		"destination_workload":           "productpage-v1",
+		"destination_canonical_service":  "productpage",
+		"destination_canonical_revision": "v1",
+		"request_protocol":               "http"}
+	productpageToReviewsV1 := model.Metric{
+		"source_cluster":                 business.DefaultClusterID,
+		"source_workload_namespace":      "bookinfo",
+		"source_workload":                "productpage-v1",
+		"source_canonical_service":       "productpage",
+		"source_canonical_revision":      "v1",
+		"destination_cluster":            business.DefaultClusterID,
+		"destination_service_namespace":  "bookinfo",
+		"destination_service":            "reviews.bookinfo.svc.cluster.local",
+		"destination_service_name":       "reviews",
+		"destination_workload_namespace": "bookinfo",
+		"destination_workload":           "reviews-v1",
+		"destination_canonical_service":  "reviews",
+		"destination_canonical_revision": "v1",
+		"request_protocol":               "http"}
+	productpageToReviewsV2 := model.Metric{
+		"source_cluster":                 business.DefaultClusterID,
+		"source_workload_namespace":      "bookinfo",
+		"source_workload":                "productpage-v1",
+		"source_canonical_service":       "productpage",
+		"source_canonical_revision":      "v1",
+		"destination_cluster":            business.DefaultClusterID,
+		"destination_service_namespace":  "bookinfo",
+		"destination_service":            "reviews.bookinfo.svc.cluster.local",
+		"destination_service_name":       "reviews",
+		"destination_workload_namespace": "bookinfo",
+		"destination_workload":           "reviews-v2",
+		"destination_canonical_service":  "reviews",
+		"destination_canonical_revision": "v2",
+		"request_protocol":               "http"}
+
+	// 1) Incoming: errorRate, requestRate, responseTime
+	q0 := `round((sum(rate(istio_requests_total{reporter="destination",destination_service_namespace="bookinfo",response_code=~"5.*"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol) - avg_over_time((sum(rate(istio_requests_total{reporter="destination",destination_service_namespace="bookinfo",response_code=~"5.*"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol))[86400s:60s] offset 60s)) / stddev_over_time((sum(rate(istio_requests_total{reporter="destination",destination_service_namespace="bookinfo",response_code=~"5.*"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol))[86400s:60s] offset 60s),0.001)`
+	v0 := model.Vector{}
+
+	q1 := `round((sum(rate(istio_requests_total{reporter="destination",destination_service_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol) - avg_over_time((sum(rate(istio_requests_total{reporter="destination",destination_service_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol))[86400s:60s] offset 60s)) / stddev_over_time((sum(rate(istio_requests_total{reporter="destination",destination_service_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol))[86400s:60s] offset 60s),0.001)`
+	v1 := model.Vector{
+		&model.Sample{
+			Metric: ingressToProductpage,
+			Value:  0.5},
+		&model.Sample{
+			Metric: productpageToReviewsV1,
+			Value:  4.2}}
+
+	q2 := `round((sum(rate(istio_request_duration_milliseconds_sum{reporter="destination",destination_service_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol) / sum(rate(istio_request_duration_milliseconds_count{reporter="destination",destination_service_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol) - avg_over_time((sum(rate(istio_request_duration_milliseconds_sum{reporter="destination",destination_service_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol) / sum(rate(istio_request_duration_milliseconds_count{reporter="destination",destination_service_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol))[86400s:60s] offset 60s)) / stddev_over_time((sum(rate(istio_request_duration_milliseconds_sum{reporter="destination",destination_service_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol) / sum(rate(istio_request_duration_milliseconds_count{reporter="destination",destination_service_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol))[86400s:60s] offset 60s),0.001)`
+	v2 := model.Vector{
+		&model.Sample{
+			Metric: productpageToReviewsV1,
+			Value:  1.0}}
+
+	// 2) Outgoing: errorRate, requestRate, responseTime
+	q3 := `round((sum(rate(istio_requests_total{reporter="source",source_workload_namespace="bookinfo",response_code=~"5.*"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol) - avg_over_time((sum(rate(istio_requests_total{reporter="source",source_workload_namespace="bookinfo",response_code=~"5.*"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol))[86400s:60s] offset 60s)) / stddev_over_time((sum(rate(istio_requests_total{reporter="source",source_workload_namespace="bookinfo",response_code=~"5.*"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol))[86400s:60s] offset 60s),0.001)`
+	v3 := model.Vector{
+		&model.Sample{
+			Metric: productpageToReviewsV2,
+			Value:  -3.5}}
+
+	q4 := `round((sum(rate(istio_requests_total{reporter="source",source_workload_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol) - avg_over_time((sum(rate(istio_requests_total{reporter="source",source_workload_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol))[86400s:60s] offset 60s)) / stddev_over_time((sum(rate(istio_requests_total{reporter="source",source_workload_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol))[86400s:60s] offset 60s),0.001)`
+	v4 := model.Vector{
+		&model.Sample{
+			Metric: productpageToReviewsV1,
+			Value:  2.0},
+		&model.Sample{
+			Metric: productpageToReviewsV2,
+			Value:  model.SampleValue(math.NaN())}}
+
+	q5 := `round((sum(rate(istio_request_duration_milliseconds_sum{reporter="source",source_workload_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol) / sum(rate(istio_request_duration_milliseconds_count{reporter="source",source_workload_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol) - avg_over_time((sum(rate(istio_request_duration_milliseconds_sum{reporter="source",source_workload_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol) / sum(rate(istio_request_duration_milliseconds_count{reporter="source",source_workload_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol))[86400s:60s] offset 60s)) / stddev_over_time((sum(rate(istio_request_duration_milliseconds_sum{reporter="source",source_workload_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol) / sum(rate(istio_request_duration_milliseconds_count{reporter="source",source_workload_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol))[86400s:60s] offset 60s),0.001)`
+	v5 := model.Vector{}
+
+	client, api, err := setupMocked()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	mockQuery(api, q0, &v0)
+	mockQuery(api, q1, &v1)
+	mockQuery(api, q2, &v2)
+	mockQuery(api, q3, &v3)
+	mockQuery(api, q4, &v4)
+	mockQuery(api, q5, &v5)
+
+	trafficMap := anomalyTestTraffic()
+	ingressID, _ := graph.Id(business.DefaultClusterID, "istio-system", "", "istio-system", "ingressgateway-unknown", "ingressgateway", graph.Unknown, graph.GraphTypeVersionedApp)
+	ingress, ok := trafficMap[ingressID]
+	assert.Equal(true, ok)
+	assert.Equal(1, len(ingress.Edges))
+	assert.Equal(nil, ingress.Edges[0].Metadata[graph.Anomaly])
+
+	duration, _ := time.ParseDuration("60s")
+	appender := AnomalyAppender{
+		Baseline:           AnomalyBaselineTrailing,
+		GraphType:          graph.GraphTypeVersionedApp,
+		InjectServiceNodes: false,
+		Namespaces: map[string]graph.NamespaceInfo{
+			"bookinfo": {
+				Name:     "bookinfo",
+				Duration: duration,
+			},
+		},
+		QueryTime: time.Now().Unix(),
+		Rates: graph.RequestedRates{
+			Grpc: graph.RateRequests,
+			Http: graph.RateRequests,
+			Tcp:  graph.RateTotal,
+		},
+		Threshold: 3.0,
+	}
+
+	appender.appendGraph(trafficMap, "bookinfo", client)
+
+	// ingress -> productpage is within the threshold
+	assert.Equal(map[string]float64{"requestRate": 0.5}, ingress.Edges[0].Metadata[graph.Anomaly].(*graph.AnomalyInfo).ZScores)
+	assert.Equal(nil, ingress.Edges[0].Metadata[graph.IsAnomalous])
+
+	productpage := ingress.Edges[0].Dest
+	assert.Equal("productpage", productpage.App)
+	assert.Equal(nil, productpage.Metadata[graph.IsAnomalous])
+	assert.Equal(nil, productpage.Metadata[graph.Anomaly])
+	assert.Equal(2, len(productpage.Edges))
+
+	for _, e := range productpage.Edges {
+		assert.Equal(true, e.Metadata[graph.IsAnomalous])
+		assert.Equal(true, e.Dest.Metadata[graph.IsAnomalous])
+		switch e.Dest.Version {
+		case "v1":
+			// the request rate z-score furthest from 0 is kept
+			assert.Equal(map[string]float64{"requestRate": 4.2, "responseTime": 1.0}, e.Metadata[graph.Anomaly].(*graph.AnomalyInfo).ZScores)
+			assert.Equal(map[string]float64{"requestRate": 4.2, "responseTime": 1.0}, e.Dest.Metadata[graph.Anomaly].(*graph.AnomalyInfo).ZScores)
+		case "v2":
+			// the NaN request rate z-score (flat baseline) is skipped
+			assert.Equal(map[string]float64{"errorRate": -3.5}, e.Metadata[graph.Anomaly].(*graph.AnomalyInfo).ZScores)
+		default:
+			assert.Fail("unexpected reviews version", e.Dest.Version)
+		}
+	}
+}
+
+func TestAnomalyLastWeekBaseline(t *testing.T) {
+	assert := assert.New(t)
+
+	appender := AnomalyAppender{Baseline: AnomalyBaselineLastWeek}
+	assert.Equal("(x - avg_over_time((x)[3600s:60s] offset 604800s)) / stddev_over_time((x)[3600s:60s] offset 604800s)", appender.zScoreQuery("x", 600))
+
+	appender = AnomalyAppender{Baseline: AnomalyBaselineTrailing}
+	assert.Equal("(x - avg_over_time((x)[86400s:60s] offset 600s)) / stddev_over_time((x)[86400s:60s] offset 600s)", appender.zScoreQuery("x", 600))
+}
+
+func TestParseAnomalyAppender(t *testing.T) {
+	assert := assert.New(t)
+
+	o := graph.TelemetryOptions{
+		Appenders: graph.RequestedAppenders{All: true},
+	}
+	o.Params = url.Values{}
+	for _, a := range ParseAppenders(o) {
+		assert.NotEqual(AnomalyAppenderName, a.Name())
+	}
+
+	o.Appenders = graph.RequestedAppenders{All: false, AppenderNames: []string{AnomalyAppenderName}}
+	o.Params.Set("anomalyThreshold", "2.5")
+	appenders := ParseAppenders(o)
+	assert.Equal(1, len(appenders))
+	assert.Equal(AnomalyBaselineTrailing, appenders[0].(AnomalyAppender).Baseline)
+	assert.Equal(2.5, appenders[0].(AnomalyAppender).Threshold)
+
+	o.Params.Set("anomalyThreshold", "-1")
+	assert.Panics(func() { ParseAppenders(o) })
+
+	o.Params.Set("anomalyThreshold", "3")
+	o.Params.Set("anomalyBaseline", "yesterday")
+	assert.Panics(func() { ParseAppenders(o) })
+}
+
+func anomalyTestTraffic() graph.TrafficMap {
+	ingress := graph.NewNode(business.DefaultClusterID, "istio-system", "", "istio-system", "ingressgateway-unknown", "ingressgateway", graph.Unknown, graph.GraphTypeVersionedApp)
+	productpage := graph.NewNode(business.DefaultClusterID, "bookinfo", "productpage", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeVersionedApp)
+	reviewsV1 := graph.NewNode(business.DefaultClusterID, "bookinfo", "reviews", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeVersionedApp)
+	reviewsV2 := graph.NewNode(business.DefaultClusterID, "bookinfo", "reviews", "bookinfo", "reviews-v2", "reviews", "v2", graph.GraphTypeVersionedApp)
+	trafficMap := graph.NewTrafficMap()
+
+	trafficMap[ingress.ID] = &ingress
+	trafficMap[productpage.ID] = &productpage
+	trafficMap[reviewsV1.ID] = &reviewsV1
+	trafficMap[reviewsV2.ID] = &reviewsV2
+
+	ingress.AddEdge(&productpage).Metadata[graph.ProtocolKey] = "http"
+	productpage.AddEdge(&reviewsV1).Metadata[graph.ProtocolKey] = "http"
+	productpage.AddEdge(&reviewsV2).Metadata[graph.ProtocolKey] = "http"
+
+	return trafficMap
+}
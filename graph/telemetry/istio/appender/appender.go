@@ -2,6 +2,8 @@ package appender
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/graph"
@@ -9,11 +11,35 @@ import (
 )
 
 const (
-	defaultAggregate      = "request_operation"
-	defaultQuantile       = 0.95
-	defaultThroughputType = "response"
+	defaultAggregate        = "request_operation"
+	defaultAnomalyBaseline  = AnomalyBaselineTrailing
+	defaultAnomalyThreshold = 3.0
+	defaultPercentiles      = "50,90,99"
+	defaultQuantile         = 0.95
+	defaultSparklinePoints  = 20
+	defaultThroughputType   = "response"
+	maxSparklinePoints      = 200
 )
 
+// AppenderNames are the names of the supported appenders, in the order they are run
+var AppenderNames = []string{
+	ServiceEntryAppenderName,
+	DeadNodeAppenderName,
+	WorkloadEntryAppenderName,
+	ResponseTimeAppenderName,
+	SecurityPolicyAppenderName,
+	ThroughputAppenderName,
+	PercentilesAppenderName,
+	AnomalyAppenderName,
+	SparklineAppenderName,
+	AggregateNodeAppenderName,
+	HealthConfigAppenderName,
+	IdleNodeAppenderName,
+	IstioAppenderName,
+	SidecarsCheckAppenderName,
+	RootCauseAppenderName,
+}
+
 // ParseAppenders determines which appenders should run for this graphing request
 func ParseAppenders(o graph.TelemetryOptions) []graph.Appender {
 
@@ -23,6 +49,8 @@ func ParseAppenders(o graph.TelemetryOptions) []graph.Appender {
 			switch appenderName {
 			case AggregateNodeAppenderName:
 				requestedAppenders[AggregateNodeAppenderName] = true
+			case AnomalyAppenderName:
+				requestedAppenders[AnomalyAppenderName] = true
 			case DeadNodeAppenderName:
 				requestedAppenders[DeadNodeAppenderName] = true
 			case HealthConfigAppenderName:
@@ -31,14 +59,20 @@ func ParseAppenders(o graph.TelemetryOptions) []graph.Appender {
 				requestedAppenders[IdleNodeAppenderName] = true
 			case IstioAppenderName:
 				requestedAppenders[IstioAppenderName] = true
+			case PercentilesAppenderName:
+				requestedAppenders[PercentilesAppenderName] = true
 			case ResponseTimeAppenderName:
 				requestedAppenders[ResponseTimeAppenderName] = true
+			case RootCauseAppenderName:
+				requestedAppenders[RootCauseAppenderName] = true
 			case SecurityPolicyAppenderName:
 				requestedAppenders[SecurityPolicyAppenderName] = true
 			case ServiceEntryAppenderName:
 				requestedAppenders[ServiceEntryAppenderName] = true
 			case SidecarsCheckAppenderName:
 				requestedAppenders[SidecarsCheckAppenderName] = true
+			case SparklineAppenderName:
+				requestedAppenders[SparklineAppenderName] = true
 			case ThroughputAppenderName:
 				requestedAppenders[ThroughputAppenderName] = true
 			case WorkloadEntryAppenderName:
@@ -58,6 +92,7 @@ func ParseAppenders(o graph.TelemetryOptions) []graph.Appender {
 	// - lazily inject aggregate nodes so other decorations can influence the new nodes/edges, if necessary
 	// Add orphan (idle) services
 	// Run remaining appenders
+	// Note: the root_cause appender is a finalizer, see ParseFinalizers
 	var appenders []graph.Appender
 
 	if _, ok := requestedAppenders[ServiceEntryAppenderName]; ok || o.Appenders.All {
@@ -133,6 +168,77 @@ func ParseAppenders(o graph.TelemetryOptions) []graph.Appender {
 		}
 		appenders = append(appenders, a)
 	}
+	// The histogram queries are expensive, so the percentiles appender runs only when explicitly requested
+	if _, ok := requestedAppenders[PercentilesAppenderName]; ok {
+		percentilesString := o.Params.Get("percentiles")
+		if percentilesString == "" {
+			percentilesString = defaultPercentiles
+		}
+		var percentiles []float64
+		for _, percentileString := range strings.Split(percentilesString, ",") {
+			percentile, err := strconv.ParseFloat(strings.TrimSpace(percentileString), 64)
+			if err != nil || percentile <= 0.0 || percentile > 100.0 {
+				graph.BadRequest(fmt.Sprintf("Invalid percentiles, expecting a comma-separated list of numbers in (0, 100]. [%s]", percentilesString))
+			}
+			percentiles = append(percentiles, percentile)
+		}
+		a := PercentilesAppender{
+			GraphType:          o.GraphType,
+			InjectServiceNodes: o.InjectServiceNodes,
+			Namespaces:         o.Namespaces,
+			Percentiles:        percentiles,
+			QueryTime:          o.QueryTime,
+			Rates:              o.Rates,
+		}
+		appenders = append(appenders, a)
+	}
+	// The baseline queries are expensive, so the anomaly appender runs only when explicitly requested
+	if _, ok := requestedAppenders[AnomalyAppenderName]; ok {
+		baseline := o.Params.Get("anomalyBaseline")
+		if baseline != "" {
+			if baseline != AnomalyBaselineLastWeek && baseline != AnomalyBaselineTrailing {
+				graph.BadRequest(fmt.Sprintf("Invalid anomalyBaseline, expecting one of (%s, %s). [%s]", AnomalyBaselineTrailing, AnomalyBaselineLastWeek, baseline))
+			}
+		} else {
+			baseline = defaultAnomalyBaseline
+		}
+		threshold := defaultAnomalyThreshold
+		if thresholdString := o.Params.Get("anomalyThreshold"); thresholdString != "" {
+			var err error
+			if threshold, err = strconv.ParseFloat(thresholdString, 64); err != nil || threshold <= 0.0 {
+				graph.BadRequest(fmt.Sprintf("Invalid anomalyThreshold, expecting a positive number. [%s]", thresholdString))
+			}
+		}
+		a := AnomalyAppender{
+			Baseline:           baseline,
+			GraphType:          o.GraphType,
+			InjectServiceNodes: o.InjectServiceNodes,
+			Namespaces:         o.Namespaces,
+			QueryTime:          o.QueryTime,
+			Rates:              o.Rates,
+			Threshold:          threshold,
+		}
+		appenders = append(appenders, a)
+	}
+	// The range queries are expensive, so the sparkline appender runs only when explicitly requested
+	if _, ok := requestedAppenders[SparklineAppenderName]; ok {
+		points := defaultSparklinePoints
+		if pointsString := o.Params.Get("sparklinePoints"); pointsString != "" {
+			var err error
+			if points, err = strconv.Atoi(pointsString); err != nil || points < 2 || points > maxSparklinePoints {
+				graph.BadRequest(fmt.Sprintf("Invalid sparklinePoints, expecting an integer in [2, %d]. [%s]", maxSparklinePoints, pointsString))
+			}
+		}
+		a := SparklineAppender{
+			GraphType:          o.GraphType,
+			InjectServiceNodes: o.InjectServiceNodes,
+			Namespaces:         o.Namespaces,
+			Points:             points,
+			QueryTime:          o.QueryTime,
+			Rates:              o.Rates,
+		}
+		appenders = append(appenders, a)
+	}
 	if _, ok := requestedAppenders[AggregateNodeAppenderName]; ok || o.Appenders.All {
 		aggregate := o.NodeOptions.Aggregate
 		if aggregate == "" {
@@ -181,6 +287,27 @@ func ParseAppenders(o graph.TelemetryOptions) []graph.Appender {
 	return appenders
 }
 
+// ParseFinalizers determines which finalizers should run for this graphing request. Finalizers are appenders
+// run once on the final TrafficMap, after the namespace TrafficMaps are merged, they are passed no namespace info.
+// A finalizer runs only when explicitly requested.
+func ParseFinalizers(o graph.TelemetryOptions) []graph.Appender {
+	var finalizers []graph.Appender
+
+	if o.Appenders.All {
+		return finalizers
+	}
+	for _, appenderName := range o.Appenders.AppenderNames {
+		if appenderName == RootCauseAppenderName {
+			a := RootCauseAppender{
+				Namespaces: o.Namespaces,
+			}
+			finalizers = append(finalizers, a)
+		}
+	}
+
+	return finalizers
+}
+
 const (
 	serviceDefinitionListKey = "serviceDefinitionListKey" // global vendor info map[namespace]serviceDefinitionList
 	serviceEntryHostsKey     = "serviceEntryHostsKey"     // global vendor info service entries for all accessible namespaces
@@ -10,6 +10,7 @@ import (
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/kubernetes"
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/models"
 )
@@ -18,7 +19,10 @@ const IstioAppenderName = "istio"
 
 // IstioAppender is responsible for badging nodes with special Istio significance:
 // - CircuitBreaker: n.Metadata[HasCB] = true
-// - Ingress Gateways: n.Metadata[IsIngressGateway] = Map of GatewayName => hosts
+// - Ingress Gateways: n.Metadata[IsIngressGateway] = Map of GatewayName => hosts and VirtualServices
+// - Egress Gateways: n.Metadata[IsEgressGateway] = Map of GatewayName => hosts and VirtualServices
+// - Gateway API Gateways: n.Metadata[IsGatewayAPI] = Map of GatewayName => hosts and HTTPRoutes
+// - Waypoint proxies: n.Metadata[IsWaypoint] = true
 // - VirtualService: n.Metadata[HasVS] = Map of VirtualServiceName => hosts
 // Name: istio
 type IstioAppender struct {
@@ -207,88 +211,235 @@ func addLabels(trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo
 	}
 }
 
+// gatewayWorkload is a gateway deployment and the graph nodes representing it
+type gatewayWorkload struct {
+	namespace string
+	workload  models.WorkloadListItem
+	nodes     []*graph.Node
+}
+
+// isEgress returns true if the workload is an Istio egress gateway
+func (gw gatewayWorkload) isEgress() bool {
+	return gw.workload.Labels[istioComponentLabel] == "EgressGateways"
+}
+
+const istioComponentLabel = "operator.istio.io/component"
+
 func (a IstioAppender) decorateGateways(trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo, namespaceInfo *graph.AppenderNamespaceInfo) {
-	// Get ingress-gateways deployments in the namespace. Then, find if the graph is showing any of them. If so, flag the GW nodes.
-	ingressWorkloads := a.getIngressGatewayWorkloads(globalInfo)
-	istioAppLabelName := config.Get().IstioLabels.AppLabelName
+	// Get the gateway deployments in the accessible namespaces. Then, find if the graph is showing any of them. If so, flag the GW nodes.
+	gwWorkloads := mapGatewayNodes(trafficMap, a.getGatewayWorkloads(globalInfo))
+
+	// If there is any gateway node, find the Istio Gateway and Gateway API resources and match them
+	// against the gateways in the graph.
+	if len(gwWorkloads) == 0 {
+		return
+	}
 
-	ingressNodeMapping := make(map[*models.WorkloadListItem][]*graph.Node)
-	for ingressNs, ingressWorkloadsList := range ingressWorkloads {
-		for _, gw := range ingressWorkloadsList {
+	gateways, virtualServices := a.getIstioGatewayResources(globalInfo)
+	applyIstioGateways(gwWorkloads, gateways, virtualServices)
+
+	if config.Get().Extensions.GatewayAPI.Enabled {
+		k8sGateways, httpRoutes := a.getK8sGatewayResources(globalInfo)
+		applyK8sGateways(gwWorkloads, k8sGateways, httpRoutes)
+	}
+}
+
+// mapGatewayNodes returns the gateway workloads shown in the graph, along with their nodes. A node represents
+// a gateway workload when it is an app or workload node for the workload, in the workload's namespace.
+func mapGatewayNodes(trafficMap graph.TrafficMap, workloads map[string][]models.WorkloadListItem) []*gatewayWorkload {
+	appLabelName := config.Get().IstioLabels.AppLabelName
+
+	gwWorkloads := []*gatewayWorkload{}
+	for namespace, workloadList := range workloads {
+		for _, workload := range workloadList {
+			gw := &gatewayWorkload{namespace: namespace, workload: workload}
+			app, hasApp := workload.Labels[appLabelName]
 			for _, node := range trafficMap {
-				if _, ok := node.Metadata[graph.IsIngressGateway]; !ok {
-					if (node.NodeType == graph.NodeTypeApp || node.NodeType == graph.NodeTypeWorkload) && node.App == gw.Labels[istioAppLabelName] && node.Namespace == ingressNs {
-						node.Metadata[graph.IsIngressGateway] = graph.GatewaysMetadata{}
-						ingressNodeMapping[&gw] = append(ingressNodeMapping[&gw], node)
-					}
+				if node.Namespace != namespace || (node.NodeType != graph.NodeTypeApp && node.NodeType != graph.NodeTypeWorkload) {
+					continue
+				}
+				if node.Workload == workload.Name || (hasApp && node.App == app) {
+					gw.nodes = append(gw.nodes, node)
 				}
 			}
+			if len(gw.nodes) > 0 {
+				gwWorkloads = append(gwWorkloads, gw)
+			}
 		}
 	}
+	return gwWorkloads
+}
 
-	// If there is any ingress gateway node in the processing namespace, find Gateway CRDs and
-	// match them against gateways in the graph.
-	if len(ingressNodeMapping) != 0 {
-		gatewaysCrds := a.getIstioGatewayResources(globalInfo)
+// applyIstioGateways flags the nodes of the gateway workloads selected by Istio Gateways. The metadata holds,
+// for each Gateway, the hostnames of its servers and the VirtualServices bound to it. Egress gateways
+// serve the external hosts they proxy.
+func applyIstioGateways(gwWorkloads []*gatewayWorkload, gateways []networking_v1alpha3.Gateway, virtualServices []networking_v1alpha3.VirtualService) {
+	for _, gw := range gwWorkloads {
+		key := graph.IsIngressGateway
+		if gw.isEgress() {
+			key = graph.IsEgressGateway
+		}
+		// a workload labeled as an Istio gateway is flagged even if no Gateway selects it yet
+		if _, isIstioGateway := gw.workload.Labels[istioComponentLabel]; isIstioGateway {
+			addGatewayMetadata(gw.nodes, key, "", nil, nil)
+		}
 
-		for _, gwCrd := range gatewaysCrds {
-			gwSelector := labels.Set(gwCrd.Spec.Selector).AsSelector()
-			for gw, nodes := range ingressNodeMapping {
-				if gwSelector.Matches(labels.Set(gw.Labels)) {
+		for _, gwCrd := range gateways {
+			if !labels.Set(gwCrd.Spec.Selector).AsSelector().Matches(labels.Set(gw.workload.Labels)) {
+				continue
+			}
 
-					// If we are here, the GatewayCrd selects the Gateway workload.
-					// So, all node graphs associated with the GW workload should be listening
-					// requests for the hostnames listed in the GatewayCRD.
+			// If we are here, the Gateway selects the gateway workload. So, all node graphs associated with
+			// the workload should be listening requests for the hostnames listed in the Gateway.
+			var hostnames []string
+			for _, gwServer := range gwCrd.Spec.Servers {
+				hostnames = append(hostnames, gwServer.Hosts...)
+			}
+			addGatewayMetadata(gw.nodes, key, gwCrd.Name, hostnames, getBoundVirtualServices(gwCrd, virtualServices))
+		}
+	}
+}
+
+// getBoundVirtualServices returns the names of the VirtualServices bound to the Gateway, a VirtualService
+// in another namespace is returned as <namespace>/<name>.
+func getBoundVirtualServices(gateway networking_v1alpha3.Gateway, virtualServices []networking_v1alpha3.VirtualService) []string {
+	var routes []string
+	for _, vs := range virtualServices {
+		for _, gwName := range vs.Spec.Gateways {
+			if !strings.Contains(gwName, "/") {
+				gwName = vs.Namespace + "/" + gwName
+			}
+			if gwName == gateway.Namespace+"/"+gateway.Name {
+				routes = append(routes, routeName(vs.Namespace, vs.Name, gateway.Namespace))
+				break
+			}
+		}
+	}
+	return routes
+}
 
-					// Let's extract the hostnames and add them to the node metadata.
-					for _, node := range nodes {
-						gwServers := gwCrd.Spec.Servers
-						var hostnames []string
+// applyK8sGateways flags the nodes of the gateway workloads deployed for Kubernetes Gateway API Gateways.
+// The metadata holds, for each Gateway, the hostnames of its listeners and of the HTTPRoutes attached to
+// it, and the names of those HTTPRoutes. Gateways of the Istio waypoint class are ambient waypoint proxies.
+func applyK8sGateways(gwWorkloads []*gatewayWorkload, k8sGateways []kubernetes.K8sGateway, httpRoutes []kubernetes.K8sHTTPRoute) {
+	for _, gw := range gwWorkloads {
+		gwName, ok := gw.workload.Labels[kubernetes.K8sGatewayNameLabel]
+		if !ok {
+			continue
+		}
+		for _, k8sGateway := range k8sGateways {
+			if k8sGateway.Name != gwName || k8sGateway.Namespace != gw.namespace {
+				continue
+			}
 
-						for _, gwServer := range gwServers {
-							gwHosts := gwServer.Hosts
-							hostnames = append(hostnames, gwHosts...)
-						}
+			if k8sGateway.Spec.GatewayClassName == kubernetes.K8sGatewayClassWaypoint {
+				for _, node := range gw.nodes {
+					node.Metadata[graph.IsWaypoint] = true
+				}
+				continue
+			}
 
-						// Metadata format: { gatewayName => array of hostnames }
-						node.Metadata[graph.IsIngressGateway].(graph.GatewaysMetadata)[gwCrd.Name] = hostnames
-					}
+			var hostnames, routes []string
+			for _, listener := range k8sGateway.Spec.Listeners {
+				if listener.Hostname != nil {
+					hostnames = append(hostnames, *listener.Hostname)
+				}
+			}
+			for _, route := range httpRoutes {
+				if route.IsParent(k8sGateway) {
+					hostnames = append(hostnames, route.Spec.Hostnames...)
+					routes = append(routes, routeName(route.Namespace, route.Name, k8sGateway.Namespace))
 				}
 			}
+			addGatewayMetadata(gw.nodes, graph.IsGatewayAPI, k8sGateway.Name, hostnames, routes)
 		}
 	}
 }
 
-func (a IstioAppender) getIngressGatewayWorkloads(globalInfo *graph.AppenderGlobalInfo) map[string][]models.WorkloadListItem {
-	ingressWorkloads := make(map[string][]models.WorkloadListItem)
+// addGatewayMetadata adds the gateway's hostnames and routes to the nodes' metadata. An empty gateway name only
+// flags the nodes.
+func addGatewayMetadata(nodes []*graph.Node, key graph.MetadataKey, gatewayName string, hostnames, routes []string) {
+	for _, node := range nodes {
+		gwMetadata, ok := node.Metadata[key].(graph.GatewaysMetadata)
+		if !ok {
+			gwMetadata = graph.GatewaysMetadata{}
+			node.Metadata[key] = gwMetadata
+		}
+		if gatewayName != "" {
+			// Metadata format: { gatewayName => hostnames and routes }
+			gwMetadata[gatewayName] = &graph.GatewayMetadata{Hostnames: hostnames, Routes: routes}
+		}
+	}
+}
+
+func routeName(namespace, name, gatewayNamespace string) string {
+	if namespace == gatewayNamespace {
+		return name
+	}
+	return namespace + "/" + name
+}
+
+// getGatewayWorkloads returns the Istio ingress and egress gateway deployments, and the deployments generated
+// by Istio for Kubernetes Gateway API Gateways, keyed by namespace.
+func (a IstioAppender) getGatewayWorkloads(globalInfo *graph.AppenderGlobalInfo) map[string][]models.WorkloadListItem {
+	gwWorkloads := make(map[string][]models.WorkloadListItem)
 	for namespace := range a.AccessibleNamespaces {
 		wList, err := globalInfo.Business.Workload.GetWorkloadList(namespace, false)
 		graph.CheckError(err)
 
-		// Find Ingress Gateway deployments
 		for _, workload := range wList.Workloads {
-			if workload.Type == "Deployment" {
-				if labelValue, ok := workload.Labels["operator.istio.io/component"]; ok && labelValue == "IngressGateways" {
-					ingressWorkloads[namespace] = append(ingressWorkloads[namespace], workload)
-				}
+			if workload.Type != "Deployment" {
+				continue
+			}
+			component := workload.Labels[istioComponentLabel]
+			_, isK8sGateway := workload.Labels[kubernetes.K8sGatewayNameLabel]
+			if component == "IngressGateways" || component == "EgressGateways" || isK8sGateway {
+				gwWorkloads[namespace] = append(gwWorkloads[namespace], workload)
 			}
 		}
 	}
 
-	return ingressWorkloads
+	return gwWorkloads
 }
 
-func (a IstioAppender) getIstioGatewayResources(globalInfo *graph.AppenderGlobalInfo) []networking_v1alpha3.Gateway {
-	retVal := []networking_v1alpha3.Gateway{}
+func (a IstioAppender) getIstioGatewayResources(globalInfo *graph.AppenderGlobalInfo) ([]networking_v1alpha3.Gateway, []networking_v1alpha3.VirtualService) {
+	gateways := []networking_v1alpha3.Gateway{}
+	virtualServices := []networking_v1alpha3.VirtualService{}
 	for namespace := range a.AccessibleNamespaces {
 		istioCfg, err := globalInfo.Business.IstioConfig.GetIstioConfigList(business.IstioConfigCriteria{
-			IncludeGateways: true,
-			Namespace:       namespace,
+			IncludeGateways:        true,
+			IncludeVirtualServices: true,
+			Namespace:              namespace,
 		})
 		graph.CheckError(err)
 
-		retVal = append(retVal, istioCfg.Gateways...)
+		gateways = append(gateways, istioCfg.Gateways...)
+		virtualServices = append(virtualServices, istioCfg.VirtualServices...)
+	}
+
+	return gateways, virtualServices
+}
+
+func (a IstioAppender) getK8sGatewayResources(globalInfo *graph.AppenderGlobalInfo) ([]kubernetes.K8sGateway, []kubernetes.K8sHTTPRoute) {
+	k8sGateways := []kubernetes.K8sGateway{}
+	httpRoutes := []kubernetes.K8sHTTPRoute{}
+	for namespace := range a.AccessibleNamespaces {
+		// The Gateway API badges are optional, skip them rather than failing the graph when the resources
+		// can not be read (e.g. the user has no access to them)
+		gws, err := globalInfo.Business.IstioConfig.GetK8sGateways(namespace)
+		if err != nil {
+			log.Warningf("Skipping Gateway API gateways, unable to get the Gateways of namespace [%s]: %v", namespace, err)
+			return []kubernetes.K8sGateway{}, []kubernetes.K8sHTTPRoute{}
+		}
+		routes, err := globalInfo.Business.IstioConfig.GetK8sHTTPRoutes(namespace)
+		if err != nil {
+			log.Warningf("Skipping Gateway API gateways, unable to get the HTTPRoutes of namespace [%s]: %v", namespace, err)
+			return []kubernetes.K8sGateway{}, []kubernetes.K8sHTTPRoute{}
+		}
+
+		k8sGateways = append(k8sGateways, gws...)
+		httpRoutes = append(httpRoutes, routes...)
 	}
 
-	return retVal
+	return k8sGateways, httpRoutes
 }
@@ -1,7 +1,9 @@
 package appender
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	osproject_v1 "github.com/openshift/api/project/v1"
 	"github.com/stretchr/testify/assert"
@@ -14,7 +16,9 @@ import (
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/kubernetes"
 	"github.com/kiali/kiali/kubernetes/kubetest"
+	"github.com/kiali/kiali/models"
 )
 
 func setupTrafficMap() (map[string]*graph.Node, string, string, string, string, string, string) {
@@ -322,3 +326,155 @@ func TestSEInAppBox(t *testing.T) {
 
 	check.Equal("fooApp", trafficMap[serviceEntryNode.ID].App)
 }
+
+func setupGatewayTrafficMap() (graph.TrafficMap, string, string, string, string) {
+	trafficMap := graph.NewTrafficMap()
+
+	ingressNode := graph.NewNode(business.DefaultClusterID, graph.Unknown, "", "istio-system", "istio-ingressgateway", "istio-ingressgateway", graph.Unknown, graph.GraphTypeWorkload)
+	trafficMap[ingressNode.ID] = &ingressNode
+
+	egressNode := graph.NewNode(business.DefaultClusterID, graph.Unknown, "", "istio-system", "istio-egressgateway", "istio-egressgateway", graph.Unknown, graph.GraphTypeWorkload)
+	trafficMap[egressNode.ID] = &egressNode
+
+	k8sGatewayNode := graph.NewNode(business.DefaultClusterID, graph.Unknown, "", "bookinfo", "bookinfo-gateway-istio", graph.Unknown, graph.Unknown, graph.GraphTypeWorkload)
+	trafficMap[k8sGatewayNode.ID] = &k8sGatewayNode
+
+	waypointNode := graph.NewNode(business.DefaultClusterID, graph.Unknown, "", "bookinfo", "waypoint", graph.Unknown, graph.Unknown, graph.GraphTypeWorkload)
+	trafficMap[waypointNode.ID] = &waypointNode
+
+	return trafficMap, ingressNode.ID, egressNode.ID, k8sGatewayNode.ID, waypointNode.ID
+}
+
+func setupGatewayWorkloads() map[string][]models.WorkloadListItem {
+	return map[string][]models.WorkloadListItem{
+		"istio-system": {
+			{Name: "istio-ingressgateway", Type: "Deployment", Labels: map[string]string{"app": "istio-ingressgateway", "istio": "ingressgateway", "operator.istio.io/component": "IngressGateways"}},
+			{Name: "istio-egressgateway", Type: "Deployment", Labels: map[string]string{"app": "istio-egressgateway", "istio": "egressgateway", "operator.istio.io/component": "EgressGateways"}},
+			{Name: "not-in-graph", Type: "Deployment", Labels: map[string]string{"operator.istio.io/component": "IngressGateways"}},
+		},
+		"bookinfo": {
+			{Name: "bookinfo-gateway-istio", Type: "Deployment", Labels: map[string]string{"istio.io/gateway-name": "bookinfo-gateway"}},
+			{Name: "waypoint", Type: "Deployment", Labels: map[string]string{"istio.io/gateway-name": "waypoint"}},
+		},
+	}
+}
+
+func TestIstioGateways(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	trafficMap, ingressNodeId, egressNodeId, k8sGatewayNodeId, _ := setupGatewayTrafficMap()
+	gwWorkloads := mapGatewayNodes(trafficMap, setupGatewayWorkloads())
+	assert.Equal(4, len(gwWorkloads))
+
+	ingressGw := networking_v1alpha3.Gateway{}
+	ingressGw.Name = "bookinfo-gateway"
+	ingressGw.Namespace = "bookinfo"
+	ingressGw.Spec.Selector = map[string]string{"istio": "ingressgateway"}
+	ingressGw.Spec.Servers = []*api_networking_v1alpha3.Server{{Hosts: []string{"bookinfo.example.com"}}}
+
+	egressGw := networking_v1alpha3.Gateway{}
+	egressGw.Name = "egress-gateway"
+	egressGw.Namespace = "istio-system"
+	egressGw.Spec.Selector = map[string]string{"istio": "egressgateway"}
+	egressGw.Spec.Servers = []*api_networking_v1alpha3.Server{{Hosts: []string{"edition.cnn.com"}}}
+
+	bookinfoVs := networking_v1alpha3.VirtualService{}
+	bookinfoVs.Name = "bookinfo"
+	bookinfoVs.Namespace = "bookinfo"
+	bookinfoVs.Spec.Gateways = []string{"bookinfo-gateway"}
+
+	cnnVs := networking_v1alpha3.VirtualService{}
+	cnnVs.Name = "direct-cnn-through-egress-gateway"
+	cnnVs.Namespace = "default"
+	cnnVs.Spec.Gateways = []string{"mesh", "istio-system/egress-gateway"}
+
+	otherVs := networking_v1alpha3.VirtualService{}
+	otherVs.Name = "other"
+	otherVs.Namespace = "default"
+	otherVs.Spec.Gateways = []string{"bookinfo-gateway"}
+
+	applyIstioGateways(gwWorkloads, []networking_v1alpha3.Gateway{ingressGw, egressGw}, []networking_v1alpha3.VirtualService{bookinfoVs, cnnVs, otherVs})
+
+	assert.Equal(graph.GatewaysMetadata{
+		"bookinfo-gateway": {Hostnames: []string{"bookinfo.example.com"}, Routes: []string{"bookinfo"}},
+	}, trafficMap[ingressNodeId].Metadata[graph.IsIngressGateway])
+	assert.Nil(trafficMap[ingressNodeId].Metadata[graph.IsEgressGateway])
+
+	assert.Equal(graph.GatewaysMetadata{
+		"egress-gateway": {Hostnames: []string{"edition.cnn.com"}, Routes: []string{"default/direct-cnn-through-egress-gateway"}},
+	}, trafficMap[egressNodeId].Metadata[graph.IsEgressGateway])
+	assert.Nil(trafficMap[egressNodeId].Metadata[graph.IsIngressGateway])
+
+	assert.Nil(trafficMap[k8sGatewayNodeId].Metadata[graph.IsIngressGateway])
+}
+
+func TestK8sGateways(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	trafficMap, ingressNodeId, _, k8sGatewayNodeId, waypointNodeId := setupGatewayTrafficMap()
+	gwWorkloads := mapGatewayNodes(trafficMap, setupGatewayWorkloads())
+
+	hostname := "*.bookinfo.example.com"
+	bookinfoGw := kubernetes.K8sGateway{}
+	bookinfoGw.Name = "bookinfo-gateway"
+	bookinfoGw.Namespace = "bookinfo"
+	bookinfoGw.Spec.GatewayClassName = "istio"
+	bookinfoGw.Spec.Listeners = []kubernetes.K8sGatewayListener{{Name: "http", Hostname: &hostname, Port: 80, Protocol: "HTTP"}}
+
+	waypointGw := kubernetes.K8sGateway{}
+	waypointGw.Name = "waypoint"
+	waypointGw.Namespace = "bookinfo"
+	waypointGw.Spec.GatewayClassName = kubernetes.K8sGatewayClassWaypoint
+
+	bookinfoNs := "bookinfo"
+	reviewsRoute := kubernetes.K8sHTTPRoute{}
+	reviewsRoute.Name = "reviews"
+	reviewsRoute.Namespace = "bookinfo"
+	reviewsRoute.Spec.ParentRefs = []kubernetes.K8sParentReference{{Name: "bookinfo-gateway"}}
+	reviewsRoute.Spec.Hostnames = []string{"reviews.bookinfo.example.com"}
+
+	ratingsRoute := kubernetes.K8sHTTPRoute{}
+	ratingsRoute.Name = "ratings"
+	ratingsRoute.Namespace = "ratings"
+	ratingsRoute.Spec.ParentRefs = []kubernetes.K8sParentReference{{Name: "bookinfo-gateway", Namespace: &bookinfoNs}}
+
+	unboundRoute := kubernetes.K8sHTTPRoute{}
+	unboundRoute.Name = "unbound"
+	unboundRoute.Namespace = "ratings"
+	unboundRoute.Spec.ParentRefs = []kubernetes.K8sParentReference{{Name: "bookinfo-gateway"}}
+	unboundRoute.Spec.Hostnames = []string{"unbound.example.com"}
+
+	applyK8sGateways(gwWorkloads, []kubernetes.K8sGateway{bookinfoGw, waypointGw}, []kubernetes.K8sHTTPRoute{reviewsRoute, ratingsRoute, unboundRoute})
+
+	assert.Equal(graph.GatewaysMetadata{
+		"bookinfo-gateway": {Hostnames: []string{"*.bookinfo.example.com", "reviews.bookinfo.example.com"}, Routes: []string{"reviews", "ratings/ratings"}},
+	}, trafficMap[k8sGatewayNodeId].Metadata[graph.IsGatewayAPI])
+	assert.Nil(trafficMap[k8sGatewayNodeId].Metadata[graph.IsWaypoint])
+
+	assert.Equal(true, trafficMap[waypointNodeId].Metadata[graph.IsWaypoint])
+	assert.Nil(trafficMap[waypointNodeId].Metadata[graph.IsGatewayAPI])
+
+	assert.Nil(trafficMap[ingressNodeId].Metadata[graph.IsGatewayAPI])
+}
+
+func TestK8sGatewaysUnreadable(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	conf.Extensions.GatewayAPI.Enabled = true
+	config.Set(conf)
+
+	k8s := kubetest.NewK8SClientMock()
+	k8s.On("IsGatewayAPI").Return(true)
+	k8s.On("GetK8sGateways", "bookinfo").Return([]kubernetes.K8sGateway{}, errors.New("forbidden"))
+
+	globalInfo := graph.NewAppenderGlobalInfo()
+	globalInfo.Business = business.NewWithBackends(k8s, nil, nil)
+
+	a := IstioAppender{AccessibleNamespaces: map[string]time.Time{"bookinfo": time.Now()}}
+	k8sGateways, httpRoutes := a.getK8sGatewayResources(globalInfo)
+
+	assert.Empty(k8sGateways)
+	assert.Empty(httpRoutes)
+}
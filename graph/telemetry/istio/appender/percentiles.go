@@ -0,0 +1,341 @@
+package appender
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/telemetry/istio/util"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus"
+)
+
+const (
+	// PercentilesAppenderName uniquely identifies the appender: percentiles
+	PercentilesAppenderName = "percentiles"
+
+	// The percentile measures, each backed by an istio histogram metric
+	percentilesRequestSize  = "requestSize"
+	percentilesResponseSize = "responseSize"
+	percentilesResponseTime = "responseTime"
+)
+
+// percentilesMetrics maps each measure to its istio histogram
+var percentilesMetrics = map[string]string{
+	percentilesRequestSize:  "istio_request_bytes_bucket",
+	percentilesResponseSize: "istio_response_bytes_bucket",
+	percentilesResponseTime: "istio_request_duration_milliseconds_bucket",
+}
+
+// PercentilesAppender is responsible for adding a matrix of percentiles to the request traffic edges: for each of
+// response time (millis), request size and response size (bytes) the requested percentiles (default p50, p90, p99).
+// The request size and response size histograms are added as well.
+// Unlike the ResponseTimeAppender, which asks Prometheus for a single quantile, the appender fetches the histogram
+// buckets and calculates the percentiles itself. Buckets, unlike quantiles, can be aggregated, which allows for a
+// single query per histogram and for percentiles on every edge, including edges to injected service nodes.
+// Name: percentiles
+type PercentilesAppender struct {
+	GraphType          string
+	InjectServiceNodes bool
+	Namespaces         graph.NamespaceInfoMap
+	Percentiles        []float64 // e.g. 50, 90, 99
+	QueryTime          int64     // unix time in seconds
+	Rates              graph.RequestedRates
+}
+
+// histogram is a set of cumulative buckets, key=upper bound, value=rate
+type histogram map[float64]float64
+
+// edgeHistograms holds the histograms of the edges, key=edge key, value=histogram by measure
+type edgeHistograms map[string]map[string]histogram
+
+// Name implements Appender
+func (a PercentilesAppender) Name() string {
+	return PercentilesAppenderName
+}
+
+// AppendGraph implements Appender
+func (a PercentilesAppender) AppendGraph(trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo, namespaceInfo *graph.AppenderNamespaceInfo) {
+	if len(trafficMap) == 0 {
+		return
+	}
+
+	// Percentiles only apply to request traffic (not TCP or gRPC-message traffic)
+	if a.Rates.Grpc != graph.RateRequests && a.Rates.Http != graph.RateRequests {
+		return
+	}
+
+	if globalInfo.PromClient == nil {
+		var err error
+		globalInfo.PromClient, err = prometheus.NewClient()
+		graph.CheckError(err)
+	}
+
+	a.appendGraph(trafficMap, namespaceInfo.Namespace, globalInfo.PromClient)
+}
+
+func (a PercentilesAppender) appendGraph(trafficMap graph.TrafficMap, namespace string, client *prometheus.Client) {
+	log.Tracef("Generating percentiles %v; namespace = %v", a.Percentiles, namespace)
+
+	histograms := make(edgeHistograms)
+	duration := a.Namespaces[namespace].Duration
+	groupBy := "le,source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol"
+
+	for _, measure := range []string{percentilesRequestSize, percentilesResponseSize, percentilesResponseTime} {
+		// query prometheus for the histogram buckets in two queries. Empty buckets are not filtered, the
+		// interpolation requires the lower bound of each bucket, empty histograms are skipped when applied.
+		// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic
+		// note - the query order is important as both queries may have overlapping results for edges within
+		//        the namespace.  This query uses destination proxy and so must come first.
+		query := fmt.Sprintf(`sum(rate(%s{reporter="destination",destination_service_namespace="%s"}[%vs])) by (%s)`,
+			percentilesMetrics[measure],
+			namespace,
+			int(duration.Seconds()), // range duration for the query
+			groupBy)
+		incomingVector := promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
+		incoming := make(edgeHistograms)
+		a.populateHistograms(incoming, measure, &incomingVector)
+		histograms.merge(incoming)
+
+		// 2) Outgoing: query source telemetry to capture namespace workloads' outgoing traffic
+		query = fmt.Sprintf(`sum(rate(%s{reporter="source",source_workload_namespace="%s"}[%vs])) by (%s)`,
+			percentilesMetrics[measure],
+			namespace,
+			int(duration.Seconds()), // range duration for the query
+			groupBy)
+		outgoingVector := promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
+		outgoing := make(edgeHistograms)
+		a.populateHistograms(outgoing, measure, &outgoingVector)
+		histograms.merge(outgoing)
+	}
+
+	a.applyPercentiles(trafficMap, histograms)
+}
+
+// merge adds the edge histograms not yet reported, giving precedence to the earlier queries
+func (eh edgeHistograms) merge(other edgeHistograms) {
+	for key, measures := range other {
+		if _, ok := eh[key]; !ok {
+			eh[key] = make(map[string]histogram)
+		}
+		for measure, h := range measures {
+			if _, ok := eh[key][measure]; !ok {
+				eh[key][measure] = h
+			}
+		}
+	}
+}
+
+func (a PercentilesAppender) applyPercentiles(trafficMap graph.TrafficMap, histograms edgeHistograms) {
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			key := fmt.Sprintf("%s %s %s", e.Source.ID, e.Dest.ID, e.Metadata[graph.ProtocolKey].(string))
+			measures, ok := histograms[key]
+			if !ok {
+				continue
+			}
+			var info *graph.PercentileInfo
+			for measure, h := range measures {
+				if h.empty() {
+					continue
+				}
+				if info == nil {
+					info = &graph.PercentileInfo{}
+				}
+				percentiles := a.percentiles(h)
+				switch measure {
+				case percentilesRequestSize:
+					info.RequestSize = percentiles
+					info.RequestSizeBuckets = h.buckets()
+				case percentilesResponseSize:
+					info.ResponseSize = percentiles
+					info.ResponseSizeBuckets = h.buckets()
+				case percentilesResponseTime:
+					info.ResponseTime = percentiles
+				}
+			}
+			if info != nil {
+				e.Metadata[graph.Percentiles] = info
+			}
+		}
+	}
+}
+
+// percentiles returns the requested percentiles of the histogram, keyed by percentile (e.g. p99), or nil if
+// they can not be calculated
+func (a PercentilesAppender) percentiles(h histogram) map[string]float64 {
+	var percentiles map[string]float64
+	for _, p := range a.Percentiles {
+		if val := h.quantile(p / 100.0); !math.IsNaN(val) {
+			if percentiles == nil {
+				percentiles = make(map[string]float64)
+			}
+			percentiles["p"+strconv.FormatFloat(p, 'f', -1, 64)] = math.Round(val*1000.0) / 1000.0
+		}
+	}
+	return percentiles
+}
+
+func (a PercentilesAppender) populateHistograms(histograms edgeHistograms, measure string, vector *model.Vector) {
+	skipRequestsGrpc := a.Rates.Grpc != graph.RateRequests
+	skipRequestsHttp := a.Rates.Http != graph.RateRequests
+
+	for _, s := range *vector {
+		m := s.Metric
+		lLe, leOk := m["le"]
+		lSourceCluster, sourceClusterOk := m["source_cluster"]
+		lSourceWlNs, sourceWlNsOk := m["source_workload_namespace"]
+		lSourceWl, sourceWlOk := m["source_workload"]
+		lSourceApp, sourceAppOk := m["source_canonical_service"]
+		lSourceVer, sourceVerOk := m["source_canonical_revision"]
+		lDestCluster, destClusterOk := m["destination_cluster"]
+		lDestSvcNs, destSvcNsOk := m["destination_service_namespace"]
+		lDestSvc, destSvcOk := m["destination_service"]
+		lDestSvcName, destSvcNameOk := m["destination_service_name"]
+		lDestWlNs, destWlNsOk := m["destination_workload_namespace"]
+		lDestWl, destWlOk := m["destination_workload"]
+		lDestApp, destAppOk := m["destination_canonical_service"]
+		lDestVer, destVerOk := m["destination_canonical_revision"]
+		lProtocol, protocolOk := m["request_protocol"]
+
+		if !leOk || !sourceWlNsOk || !sourceWlOk || !sourceAppOk || !sourceVerOk || !destSvcNsOk || !destSvcNameOk || !destSvcOk || !destWlNsOk || !destWlOk || !destAppOk || !destVerOk || !protocolOk {
+			log.Warningf("populateHistograms: Skipping %s, missing expected labels", m.String())
+			continue
+		}
+
+		le, err := strconv.ParseFloat(string(lLe), 64)
+		if err != nil {
+			log.Warningf("populateHistograms: Skipping %s, invalid bucket", m.String())
+			continue
+		}
+
+		sourceWlNs := string(lSourceWlNs)
+		sourceWl := string(lSourceWl)
+		sourceApp := string(lSourceApp)
+		sourceVer := string(lSourceVer)
+		destSvc := string(lDestSvc)
+		protocol := string(lProtocol)
+
+		if (skipRequestsHttp && protocol == graph.HTTP.Name) || (skipRequestsGrpc && protocol == graph.GRPC.Name) {
+			continue
+		}
+
+		// handle clusters
+		sourceCluster, destCluster := util.HandleClusters(lSourceCluster, sourceClusterOk, lDestCluster, destClusterOk)
+
+		if util.IsBadSourceTelemetry(sourceCluster, sourceClusterOk, sourceWlNs, sourceWl, sourceApp) {
+			continue
+		}
+
+		val := float64(s.Value)
+
+		// handle unusual destinations
+		destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer, _ := util.HandleDestination(sourceCluster, sourceWlNs, sourceWl, destCluster, string(lDestSvcNs), string(lDestSvc), string(lDestSvcName), string(lDestWlNs), string(lDestWl), string(lDestApp), string(lDestVer))
+
+		if util.IsBadDestTelemetry(destCluster, destClusterOk, destSvcNs, destSvc, destSvcName, destWl) {
+			continue
+		}
+
+		// Should not happen but if NaN for any reason, Just skip it
+		if math.IsNaN(val) {
+			continue
+		}
+
+		// don't inject a service node if destSvcName is not set or the dest node is already a service node.
+		inject := false
+		if a.InjectServiceNodes && graph.IsOK(destSvcName) {
+			_, destNodeType := graph.Id(destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer, a.GraphType)
+			inject = (graph.NodeTypeService != destNodeType)
+		}
+
+		if inject {
+			// buckets can be aggregated, so unlike response times, set both the incoming and outgoing edge
+			a.addBucket(histograms, measure, le, val, protocol, sourceCluster, sourceWlNs, "", sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvcName, "", "", "", "")
+			a.addBucket(histograms, measure, le, val, protocol, destCluster, destSvcNs, destSvcName, "", "", "", destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer)
+		} else {
+			a.addBucket(histograms, measure, le, val, protocol, sourceCluster, sourceWlNs, "", sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer)
+		}
+	}
+}
+
+func (a PercentilesAppender) addBucket(histograms edgeHistograms, measure string, le, val float64, protocol, sourceCluster, sourceNs, sourceSvc, sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvc, destWlNs, destWl, destApp, destVer string) {
+	sourceID, _ := graph.Id(sourceCluster, sourceNs, sourceSvc, sourceNs, sourceWl, sourceApp, sourceVer, a.GraphType)
+	destID, _ := graph.Id(destCluster, destSvcNs, destSvc, destWlNs, destWl, destApp, destVer, a.GraphType)
+	key := fmt.Sprintf("%s %s %s", sourceID, destID, protocol)
+
+	if _, ok := histograms[key]; !ok {
+		histograms[key] = make(map[string]histogram)
+	}
+	h, ok := histograms[key][measure]
+	if !ok {
+		h = make(histogram)
+		histograms[key][measure] = h
+	}
+	h[le] += val
+}
+
+// empty returns true if the histogram has no observations
+func (h histogram) empty() bool {
+	return h[math.Inf(+1)] <= 0
+}
+
+// buckets returns the histogram's bucket rates keyed by upper bound, e.g. 1000 or +Inf
+func (h histogram) buckets() map[string]float64 {
+	buckets := make(map[string]float64, len(h))
+	for le, val := range h {
+		buckets[strconv.FormatFloat(le, 'f', -1, 64)] = math.Round(val*1000.0) / 1000.0
+	}
+	return buckets
+}
+
+// quantile returns the q-quantile (0 <= q <= 1) of the histogram, interpolated like the Prometheus
+// histogram_quantile function. It returns NaN for an empty histogram, or one without a +Inf bucket.
+func (h histogram) quantile(q float64) float64 {
+	bounds := make([]float64, 0, len(h))
+	for le := range h {
+		bounds = append(bounds, le)
+	}
+	sort.Float64s(bounds)
+
+	if len(bounds) < 2 || !math.IsInf(bounds[len(bounds)-1], +1) {
+		return math.NaN()
+	}
+
+	// the buckets are cumulative, protect against non-monotonic counts (e.g. due to rounding)
+	counts := make([]float64, len(bounds))
+	for i, le := range bounds {
+		counts[i] = h[le]
+		if i > 0 && counts[i] < counts[i-1] {
+			counts[i] = counts[i-1]
+		}
+	}
+
+	observations := counts[len(counts)-1]
+	if observations == 0 {
+		return math.NaN()
+	}
+	rank := q * observations
+	b := sort.Search(len(counts)-1, func(i int) bool { return counts[i] >= rank })
+
+	switch {
+	case b == len(counts)-1:
+		return bounds[len(bounds)-2]
+	case b == 0 && bounds[0] <= 0:
+		return bounds[0]
+	}
+
+	bucketStart := 0.0
+	bucketEnd := bounds[b]
+	count := counts[b]
+	if b > 0 {
+		bucketStart = bounds[b-1]
+		count -= counts[b-1]
+		rank -= counts[b-1]
+	}
+	return bucketStart + (bucketEnd-bucketStart)*(rank/count)
+}
@@ -0,0 +1,221 @@
+package appender
+
+import (
+	"math"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/graph"
+)
+
+// histogramVector returns the cumulative buckets of a histogram, counts are keyed by upper bound
+func histogramVector(metric func(le string) model.Metric, counts map[string]float64) model.Vector {
+	vector := model.Vector{}
+	for le, count := range counts {
+		vector = append(vector, &model.Sample{Metric: metric(le), Value: model.SampleValue(count)})
+	}
+	return vector
+}
+
+func TestPercentiles(t *testing.T) {
+	assert := assert.New(t)
+
+	ingressToProductpage := func(le string) model.Metric {
+		return model.Metric{
+			"le":                             model.LabelValue(le),
+			"source_cluster":                 business.DefaultClusterID,
+			"source_workload_namespace":      "istio-system",
+			"source_workload":                "ingressgateway-unknown",
+			"source_canonical_service":       "ingressgateway",
+			"source_canonical_revision":      model.LabelValue(graph.Unknown),
+			"destination_cluster":            business.DefaultClusterID,
+			"destination_service_namespace":  "bookinfo",
+			"destination_service":            "productpage.bookinfo.svc.cluster.local",
+			"destination_service_name":       "productpage",
+			"destination_workload_namespace": "bookinfo",
+			"destination_workload":           "productpage-v1",
+			"destination_canonical_service":  "productpage",
+			"destination_canonical_revision": "v1",
+			"request_protocol":               "http"}
+	}
+	productpageToReviews := func(le string) model.Metric {
+		return model.Metric{
+			"le":                             model.LabelValue(le),
+			"source_cluster":                 business.DefaultClusterID,
+			"source_workload_namespace":      "bookinfo",
+			"source_workload":                "productpage-v1",
+			"source_canonical_service":       "productpage",
+			"source_canonical_revision":      "v1",
+			"destination_cluster":            business.DefaultClusterID,
+			"destination_service_namespace":  "bookinfo",
+			"destination_service":            "reviews.bookinfo.svc.cluster.local",
+			"destination_service_name":       "reviews",
+			"destination_workload_namespace": "bookinfo",
+			"destination_workload":           "reviews-v1",
+			"destination_canonical_service":  "reviews",
+			"destination_canonical_revision": "v1",
+			"request_protocol":               "http"}
+	}
+
+	// requestSize
+	q0 := `round(sum(rate(istio_request_bytes_bucket{reporter="destination",destination_service_namespace="bookinfo"}[60s])) by (le,source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol),0.001)`
+	v0 := histogramVector(productpageToReviews, map[string]float64{"10": 0.0, "100": 2.0, "1000": 4.0, "+Inf": 4.0})
+
+	// the edge is reported by both queries, the incoming (destination) buckets are preferred
+	q1 := `round(sum(rate(istio_request_bytes_bucket{reporter="source",source_workload_namespace="bookinfo"}[60s])) by (le,source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol),0.001)`
+	v1 := histogramVector(productpageToReviews, map[string]float64{"100": 4.0, "1000": 4.0, "+Inf": 4.0})
+
+	// responseSize
+	q2 := `round(sum(rate(istio_response_bytes_bucket{reporter="destination",destination_service_namespace="bookinfo"}[60s])) by (le,source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol),0.001)`
+	v2 := histogramVector(productpageToReviews, map[string]float64{"100": 0.0, "+Inf": 0.0})
+
+	q3 := `round(sum(rate(istio_response_bytes_bucket{reporter="source",source_workload_namespace="bookinfo"}[60s])) by (le,source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol),0.001)`
+	v3 := model.Vector{}
+
+	// responseTime
+	q4 := `round(sum(rate(istio_request_duration_milliseconds_bucket{reporter="destination",destination_service_namespace="bookinfo"}[60s])) by (le,source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol),0.001)`
+	v4 := histogramVector(ingressToProductpage, map[string]float64{"5": 0.0, "10": 5.0, "50": 9.0, "+Inf": 10.0})
+
+	q5 := `round(sum(rate(istio_request_duration_milliseconds_bucket{reporter="source",source_workload_namespace="bookinfo"}[60s])) by (le,source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol),0.001)`
+	v5 := model.Vector{}
+
+	client, api, err := setupMocked()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	mockQuery(api, q0, &v0)
+	mockQuery(api, q1, &v1)
+	mockQuery(api, q2, &v2)
+	mockQuery(api, q3, &v3)
+	mockQuery(api, q4, &v4)
+	mockQuery(api, q5, &v5)
+
+	trafficMap := percentilesTestTraffic()
+	ingressID, _ := graph.Id(business.DefaultClusterID, "istio-system", "", "istio-system", "ingressgateway-unknown", "ingressgateway", graph.Unknown, graph.GraphTypeVersionedApp)
+	ingress, ok := trafficMap[ingressID]
+	assert.Equal(true, ok)
+	assert.Equal(1, len(ingress.Edges))
+	assert.Equal(nil, ingress.Edges[0].Metadata[graph.Percentiles])
+
+	duration, _ := time.ParseDuration("60s")
+	appender := PercentilesAppender{
+		GraphType:          graph.GraphTypeVersionedApp,
+		InjectServiceNodes: true,
+		Namespaces: map[string]graph.NamespaceInfo{
+			"bookinfo": {
+				Name:     "bookinfo",
+				Duration: duration,
+			},
+		},
+		Percentiles: []float64{50, 90, 99},
+		QueryTime:   time.Now().Unix(),
+		Rates: graph.RequestedRates{
+			Grpc: graph.RateRequests,
+			Http: graph.RateRequests,
+			Tcp:  graph.RateTotal,
+		},
+	}
+
+	appender.appendGraph(trafficMap, "bookinfo", client)
+
+	// buckets are aggregated, both the edge to the service node and the edge from the service node are set
+	// the empty bucket is the lower bound of the p50 bucket
+	expected := &graph.PercentileInfo{ResponseTime: map[string]float64{"p50": 10.0, "p90": 50.0, "p99": 50.0}}
+	assert.Equal(expected, ingress.Edges[0].Metadata[graph.Percentiles])
+
+	productpageService := ingress.Edges[0].Dest
+	assert.Equal(graph.NodeTypeService, productpageService.NodeType)
+	assert.Equal(1, len(productpageService.Edges))
+	assert.Equal(expected, productpageService.Edges[0].Metadata[graph.Percentiles])
+
+	productpage := productpageService.Edges[0].Dest
+	assert.Equal(1, len(productpage.Edges))
+	// the empty response size histogram is skipped
+	expected = &graph.PercentileInfo{
+		RequestSize:        map[string]float64{"p50": 100.0, "p90": 820.0, "p99": 982.0},
+		RequestSizeBuckets: map[string]float64{"10": 0.0, "100": 2.0, "1000": 4.0, "+Inf": 4.0},
+	}
+	assert.Equal(expected, productpage.Edges[0].Metadata[graph.Percentiles])
+
+	reviewsService := productpage.Edges[0].Dest
+	assert.Equal(1, len(reviewsService.Edges))
+	assert.Equal(expected, reviewsService.Edges[0].Metadata[graph.Percentiles])
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	assert := assert.New(t)
+
+	h := histogram{10: 5.0, 50: 9.0, math.Inf(+1): 10.0}
+	assert.Equal(5.0, h.quantile(0.25))
+	assert.Equal(10.0, h.quantile(0.5))
+	assert.Equal(30.0, h.quantile(0.7))
+	assert.Equal(50.0, h.quantile(0.99))
+
+	// empty lower buckets bound the interpolation
+	h = histogram{5: 0.0, 10: 5.0, 50: 9.0, math.Inf(+1): 10.0}
+	assert.Equal(7.5, h.quantile(0.25))
+
+	// non-monotonic buckets are corrected
+	h = histogram{10: 5.0, 50: 4.0, math.Inf(+1): 10.0}
+	assert.Equal(50.0, h.quantile(0.9))
+
+	// no +Inf bucket
+	h = histogram{10: 5.0, 50: 9.0}
+	assert.True(math.IsNaN(h.quantile(0.5)))
+
+	// no observations
+	h = histogram{10: 0.0, math.Inf(+1): 0.0}
+	assert.True(math.IsNaN(h.quantile(0.5)))
+}
+
+func TestParsePercentilesAppender(t *testing.T) {
+	assert := assert.New(t)
+
+	o := graph.TelemetryOptions{
+		Appenders: graph.RequestedAppenders{All: true},
+	}
+	o.Params = url.Values{}
+	for _, a := range ParseAppenders(o) {
+		assert.NotEqual(PercentilesAppenderName, a.Name())
+	}
+
+	o.Appenders = graph.RequestedAppenders{All: false, AppenderNames: []string{PercentilesAppenderName}}
+	appenders := ParseAppenders(o)
+	assert.Equal(1, len(appenders))
+	assert.Equal([]float64{50, 90, 99}, appenders[0].(PercentilesAppender).Percentiles)
+
+	o.Params.Set("percentiles", "75, 99.9")
+	appenders = ParseAppenders(o)
+	assert.Equal([]float64{75, 99.9}, appenders[0].(PercentilesAppender).Percentiles)
+
+	o.Params.Set("percentiles", "50,101")
+	assert.Panics(func() { ParseAppenders(o) })
+}
+
+func percentilesTestTraffic() graph.TrafficMap {
+	ingress := graph.NewNode(business.DefaultClusterID, "istio-system", "", "istio-system", "ingressgateway-unknown", "ingressgateway", graph.Unknown, graph.GraphTypeVersionedApp)
+	productpageService := graph.NewNode(business.DefaultClusterID, "bookinfo", "productpage", "", "", "", "", graph.GraphTypeVersionedApp)
+	productpage := graph.NewNode(business.DefaultClusterID, "bookinfo", "productpage", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeVersionedApp)
+	reviewsService := graph.NewNode(business.DefaultClusterID, "bookinfo", "reviews", "", "", "", "", graph.GraphTypeVersionedApp)
+	reviewsV1 := graph.NewNode(business.DefaultClusterID, "bookinfo", "reviews", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeVersionedApp)
+	trafficMap := graph.NewTrafficMap()
+
+	trafficMap[ingress.ID] = &ingress
+	trafficMap[productpageService.ID] = &productpageService
+	trafficMap[productpage.ID] = &productpage
+	trafficMap[reviewsService.ID] = &reviewsService
+	trafficMap[reviewsV1.ID] = &reviewsV1
+
+	ingress.AddEdge(&productpageService).Metadata[graph.ProtocolKey] = "http"
+	productpageService.AddEdge(&productpage).Metadata[graph.ProtocolKey] = "http"
+	productpage.AddEdge(&reviewsService).Metadata[graph.ProtocolKey] = "http"
+	reviewsService.AddEdge(&reviewsV1).Metadata[graph.ProtocolKey] = "http"
+
+	return trafficMap
+}
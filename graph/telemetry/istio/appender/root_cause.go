@@ -0,0 +1,60 @@
+package appender
+
+import (
+	"math"
+
+	"github.com/kiali/kiali/graph"
+)
+
+const RootCauseAppenderName = "rootCause"
+
+// RootCauseAppender is responsible for ranking the nodes returning errors as probable root causes. A node's
+// incoming error rate is the rate of error responses on its incoming edges. Errors may propagate upstream, so
+// the part of the incoming error rate explained by the error responses on the node's outgoing edges is considered
+// propagated, and the remainder as originating at the node. The originating error rate is set as the node's
+// rootCauseScore, a higher score being a more probable root cause. As such the most-downstream erroring nodes
+// score the highest, and nodes only relaying downstream errors are not scored.
+// The appender is a finalizer, it scores the final TrafficMap so that errors propagating across the requested
+// namespaces are accounted for. It runs only when explicitly requested.
+// Note: only nodes in the requested namespaces are scored, other nodes may have incoming or outgoing edges not
+// in the TrafficMap.
+// Name: rootCause
+type RootCauseAppender struct {
+	Namespaces graph.NamespaceInfoMap
+}
+
+// Name implements Appender
+func (a RootCauseAppender) Name() string {
+	return RootCauseAppenderName
+}
+
+// AppendGraph implements Appender
+func (a RootCauseAppender) AppendGraph(trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo, namespaceInfo *graph.AppenderNamespaceInfo) {
+	if len(trafficMap) == 0 {
+		return
+	}
+
+	a.scoreRootCauses(trafficMap)
+}
+
+func (a RootCauseAppender) scoreRootCauses(trafficMap graph.TrafficMap) {
+	inErrRates := make(map[string]float64)
+	outErrRates := make(map[string]float64)
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			errRate := graph.EdgeErrRate(e)
+			inErrRates[e.Dest.ID] += errRate
+			outErrRates[n.ID] += errRate
+		}
+	}
+
+	for id, n := range trafficMap {
+		if _, ok := a.Namespaces[n.Namespace]; !ok {
+			continue
+		}
+		propagated := math.Min(inErrRates[id], outErrRates[id])
+		if score := math.Round((inErrRates[id]-propagated)*1000.0) / 1000.0; score > 0.0 {
+			n.Metadata[graph.RootCauseScore] = score
+		}
+	}
+}
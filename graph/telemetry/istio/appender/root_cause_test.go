@@ -0,0 +1,99 @@
+package appender
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/graph"
+)
+
+func TestRootCause(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := rootCauseTestTraffic()
+
+	a := RootCauseAppender{Namespaces: graph.NamespaceInfoMap{"bookinfo": graph.NamespaceInfo{Name: "bookinfo"}}}
+	a.AppendGraph(trafficMap, nil, nil)
+
+	ingressID, _ := graph.Id(business.DefaultClusterID, "istio-system", "", "istio-system", "ingressgateway-unknown", "ingressgateway", graph.Unknown, graph.GraphTypeVersionedApp)
+	productpageID, _ := graph.Id(business.DefaultClusterID, "bookinfo", "productpage", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeVersionedApp)
+	reviewsID, _ := graph.Id(business.DefaultClusterID, "bookinfo", "reviews", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeVersionedApp)
+	ratingsID, _ := graph.Id(business.DefaultClusterID, "bookinfo", "ratings", "bookinfo", "ratings-v1", "ratings", "v1", graph.GraphTypeVersionedApp)
+	detailsID, _ := graph.Id(business.DefaultClusterID, "bookinfo", "details", "bookinfo", "details-v1", "details", "v1", graph.GraphTypeVersionedApp)
+	mysqlID, _ := graph.Id(business.DefaultClusterID, "bookinfo", "mysql", "bookinfo", "mysql-v1", "mysql", "v1", graph.GraphTypeVersionedApp)
+
+	// the most-downstream erroring node is the most probable root cause
+	assert.Equal(2.0, trafficMap[ratingsID].Metadata[graph.RootCauseScore])
+	// productpage returns 3 errors/sec, of which 2 propagate from reviews
+	assert.Equal(1.0, trafficMap[productpageID].Metadata[graph.RootCauseScore])
+
+	// reviews only relays the ratings errors, no errors for details, tcp traffic has no errors
+	_, ok := trafficMap[reviewsID].Metadata[graph.RootCauseScore]
+	assert.False(ok)
+	_, ok = trafficMap[detailsID].Metadata[graph.RootCauseScore]
+	assert.False(ok)
+	_, ok = trafficMap[mysqlID].Metadata[graph.RootCauseScore]
+	assert.False(ok)
+
+	// nodes outside of the namespace are not scored
+	_, ok = trafficMap[ingressID].Metadata[graph.RootCauseScore]
+	assert.False(ok)
+}
+
+func TestParseRootCauseFinalizer(t *testing.T) {
+	assert := assert.New(t)
+
+	o := graph.TelemetryOptions{
+		Appenders: graph.RequestedAppenders{All: true},
+	}
+	o.Params = url.Values{}
+	for _, a := range ParseAppenders(o) {
+		assert.NotEqual(RootCauseAppenderName, a.Name())
+	}
+	assert.Empty(ParseFinalizers(o))
+
+	// when requested it runs as a finalizer only
+	o.Appenders = graph.RequestedAppenders{All: false, AppenderNames: []string{RootCauseAppenderName}}
+	assert.Empty(ParseAppenders(o))
+	finalizers := ParseFinalizers(o)
+	assert.Equal(1, len(finalizers))
+	assert.Equal(RootCauseAppenderName, finalizers[0].Name())
+}
+
+func rootCauseTestTraffic() graph.TrafficMap {
+	ingress := graph.NewNode(business.DefaultClusterID, "istio-system", "", "istio-system", "ingressgateway-unknown", "ingressgateway", graph.Unknown, graph.GraphTypeVersionedApp)
+	productpage := graph.NewNode(business.DefaultClusterID, "bookinfo", "productpage", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeVersionedApp)
+	reviews := graph.NewNode(business.DefaultClusterID, "bookinfo", "reviews", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeVersionedApp)
+	ratings := graph.NewNode(business.DefaultClusterID, "bookinfo", "ratings", "bookinfo", "ratings-v1", "ratings", "v1", graph.GraphTypeVersionedApp)
+	details := graph.NewNode(business.DefaultClusterID, "bookinfo", "details", "bookinfo", "details-v1", "details", "v1", graph.GraphTypeVersionedApp)
+	mysql := graph.NewNode(business.DefaultClusterID, "bookinfo", "mysql", "bookinfo", "mysql-v1", "mysql", "v1", graph.GraphTypeVersionedApp)
+	trafficMap := graph.NewTrafficMap()
+
+	trafficMap[ingress.ID] = &ingress
+	trafficMap[productpage.ID] = &productpage
+	trafficMap[reviews.ID] = &reviews
+	trafficMap[ratings.ID] = &ratings
+	trafficMap[details.ID] = &details
+	trafficMap[mysql.ID] = &mysql
+
+	addEdge := func(source, dest *graph.Node, protocol string, rate, errRate float64) {
+		e := source.AddEdge(dest)
+		e.Metadata[graph.ProtocolKey] = protocol
+		code := "200"
+		if protocol == graph.GRPC.Name {
+			code = "0"
+		}
+		graph.AddToMetadata(protocol, rate-errRate, code, "-", "", source.Metadata, dest.Metadata, e.Metadata)
+		graph.AddToMetadata(protocol, errRate, "503", "-", "", source.Metadata, dest.Metadata, e.Metadata)
+	}
+	addEdge(&ingress, &productpage, graph.HTTP.Name, 10.0, 3.0)
+	addEdge(&productpage, &reviews, graph.GRPC.Name, 5.0, 2.0)
+	addEdge(&productpage, &details, graph.HTTP.Name, 5.0, 0.0)
+	addEdge(&reviews, &ratings, graph.HTTP.Name, 2.0, 2.0)
+	addEdge(&ratings, &mysql, graph.TCP.Name, 100.0, 0.0)
+
+	return trafficMap
+}
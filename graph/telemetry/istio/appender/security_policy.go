@@ -119,6 +119,19 @@ func (a SecurityPolicyAppender) appendGraph(trafficMap graph.TrafficMap, namespa
 			query = fmt.Sprintf(`%s OR (%s)`, query, tcpReceivedQuery)
 		}
 	}
+	if a.Rates.Tcp == graph.RateConnections {
+		tcpConnectionsQuery := fmt.Sprintf(`sum(rate(%s{reporter="destination",source_workload_namespace!="%v",destination_service_namespace="%v"}[%vs])) by (%s) > 0`,
+			"istio_tcp_connections_opened_total",
+			namespace,
+			namespace,
+			int(duration.Seconds()), // range duration for the query
+			groupBy)
+		if query == "" {
+			query = fmt.Sprintf(`(%s)`, tcpConnectionsQuery)
+		} else {
+			query = fmt.Sprintf(`%s OR (%s)`, query, tcpConnectionsQuery)
+		}
+	}
 	outVector := promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
 
 	// 2) query for requests originating from a workload inside of the namespace
@@ -179,6 +192,18 @@ func (a SecurityPolicyAppender) appendGraph(trafficMap graph.TrafficMap, namespa
 			query = fmt.Sprintf(`%s OR (%s)`, query, tcpReceivedQuery)
 		}
 	}
+	if a.Rates.Tcp == graph.RateConnections {
+		tcpConnectionsQuery := fmt.Sprintf(`sum(rate(%s{reporter="destination",source_workload_namespace="%v"}[%vs])) by (%s) > 0`,
+			"istio_tcp_connections_opened_total",
+			namespace,
+			int(duration.Seconds()), // range duration for the query
+			groupBy)
+		if query == "" {
+			query = fmt.Sprintf(`(%s)`, tcpConnectionsQuery)
+		} else {
+			query = fmt.Sprintf(`%s OR (%s)`, query, tcpConnectionsQuery)
+		}
+	}
 	inVector := promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
 
 	// create map to quickly look up securityPolicy
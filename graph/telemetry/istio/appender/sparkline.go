@@ -0,0 +1,240 @@
+package appender
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/telemetry/istio/util"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus"
+)
+
+const (
+	// SparklineAppenderName uniquely identifies the appender: sparkline
+	SparklineAppenderName = "sparkline"
+
+	// sparklineMinRateInterval is the minimum rate range of a point, shorter ranges may not hold two samples
+	sparklineMinRateInterval = time.Minute
+)
+
+// SparklineAppender is responsible for adding a request rate time series to the edges and nodes. The series
+// holds Points rates evenly spaced over the time window, the last one at the query time, each the request rate
+// over the preceding step (or sparklineMinRateInterval, if longer). The series of all edges are provided by a
+// single range query for each of the incoming and outgoing traffic. A node is assigned the sum of its incoming
+// edge series or, for a node without incoming edges, the sum of its outgoing edge series.
+// Name: sparkline
+type SparklineAppender struct {
+	GraphType          string
+	InjectServiceNodes bool
+	Namespaces         graph.NamespaceInfoMap
+	Points             int
+	QueryTime          int64 // unix time in seconds
+	Rates              graph.RequestedRates
+}
+
+// Name implements Appender
+func (a SparklineAppender) Name() string {
+	return SparklineAppenderName
+}
+
+// AppendGraph implements Appender
+func (a SparklineAppender) AppendGraph(trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo, namespaceInfo *graph.AppenderNamespaceInfo) {
+	if len(trafficMap) == 0 {
+		return
+	}
+
+	// Sparklines only apply to request traffic (not TCP or gRPC-message traffic)
+	if a.Rates.Grpc != graph.RateRequests && a.Rates.Http != graph.RateRequests {
+		return
+	}
+
+	if globalInfo.PromClient == nil {
+		var err error
+		globalInfo.PromClient, err = prometheus.NewClient()
+		graph.CheckError(err)
+	}
+
+	a.appendGraph(trafficMap, namespaceInfo.Namespace, globalInfo.PromClient)
+}
+
+func (a SparklineAppender) appendGraph(trafficMap graph.TrafficMap, namespace string, client *prometheus.Client) {
+	log.Tracef("Generating [%d] point sparklines; namespace = %v", a.Points, namespace)
+
+	// create map to quickly look up sparklines, key=edge key, value=rates
+	sparklineMap := make(map[string][]float64)
+	bounds := a.rangeBounds(a.Namespaces[namespace].Duration)
+	rateInterval := bounds.Step
+	if rateInterval < sparklineMinRateInterval {
+		rateInterval = sparklineMinRateInterval
+	}
+	groupBy := "source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol"
+
+	// query prometheus for the request rate series in two queries:
+	// 1) query for requests originating from a workload outside the namespace.
+	query := fmt.Sprintf(`sum(rate(istio_requests_total{reporter="destination",source_workload_namespace!="%s",destination_service_namespace="%s"}[%vs])) by (%s)`,
+		namespace,
+		namespace,
+		int(rateInterval.Seconds()), // range duration for the query
+		groupBy)
+	matrix := promQueryRange(query, bounds, client.GetContext(), client.API(), a)
+	a.populateSparklineMap(sparklineMap, &matrix, bounds)
+
+	// 2) query for requests originating from a workload inside of the namespace
+	query = fmt.Sprintf(`sum(rate(istio_requests_total{reporter="source",source_workload_namespace="%s"}[%vs])) by (%s)`,
+		namespace,
+		int(rateInterval.Seconds()), // range duration for the query
+		groupBy)
+	matrix = promQueryRange(query, bounds, client.GetContext(), client.API(), a)
+	a.populateSparklineMap(sparklineMap, &matrix, bounds)
+
+	applySparklines(trafficMap, sparklineMap, int64(bounds.Step.Seconds()))
+}
+
+// rangeBounds returns the range of the Points evaluation times, ending at the query time
+func (a SparklineAppender) rangeBounds(duration time.Duration) prom_v1.Range {
+	step := duration / time.Duration(a.Points)
+	if step < time.Second {
+		step = time.Second
+	}
+	end := time.Unix(a.QueryTime, 0)
+	return prom_v1.Range{
+		Start: end.Add(-step * time.Duration(a.Points-1)),
+		End:   end,
+		Step:  step,
+	}
+}
+
+func applySparklines(trafficMap graph.TrafficMap, sparklineMap map[string][]float64, step int64) {
+	incoming := make(map[string][]float64)
+	outgoing := make(map[string][]float64)
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			key := fmt.Sprintf("%s %s %s", e.Source.ID, e.Dest.ID, e.Metadata[graph.ProtocolKey].(string))
+			rates, ok := sparklineMap[key]
+			if !ok {
+				continue
+			}
+			e.Metadata[graph.Sparkline] = &graph.SparklineInfo{Rates: rates, Step: step}
+			incoming[e.Dest.ID] = addRates(incoming[e.Dest.ID], rates)
+			outgoing[e.Source.ID] = addRates(outgoing[e.Source.ID], rates)
+		}
+	}
+
+	for _, n := range trafficMap {
+		rates, ok := incoming[n.ID]
+		if !ok {
+			if rates, ok = outgoing[n.ID]; !ok {
+				continue
+			}
+		}
+		n.Metadata[graph.Sparkline] = &graph.SparklineInfo{Rates: rates, Step: step}
+	}
+}
+
+// addRates adds the rates to the sum, point by point, returning the updated sum
+func addRates(sum, rates []float64) []float64 {
+	if sum == nil {
+		sum = make([]float64, len(rates))
+	}
+	for i, rate := range rates {
+		sum[i] += rate
+	}
+	return sum
+}
+
+func (a SparklineAppender) populateSparklineMap(sparklineMap map[string][]float64, matrix *model.Matrix, bounds prom_v1.Range) {
+	skipRequestsGrpc := a.Rates.Grpc != graph.RateRequests
+	skipRequestsHttp := a.Rates.Http != graph.RateRequests
+
+	for _, s := range *matrix {
+		m := s.Metric
+		lSourceCluster, sourceClusterOk := m["source_cluster"]
+		lSourceWlNs, sourceWlNsOk := m["source_workload_namespace"]
+		lSourceWl, sourceWlOk := m["source_workload"]
+		lSourceApp, sourceAppOk := m["source_canonical_service"]
+		lSourceVer, sourceVerOk := m["source_canonical_revision"]
+		lDestCluster, destClusterOk := m["destination_cluster"]
+		lDestSvcNs, destSvcNsOk := m["destination_service_namespace"]
+		lDestSvc, destSvcOk := m["destination_service"]
+		lDestSvcName, destSvcNameOk := m["destination_service_name"]
+		lDestWlNs, destWlNsOk := m["destination_workload_namespace"]
+		lDestWl, destWlOk := m["destination_workload"]
+		lDestApp, destAppOk := m["destination_canonical_service"]
+		lDestVer, destVerOk := m["destination_canonical_revision"]
+		lProtocol, protocolOk := m["request_protocol"]
+
+		if !sourceWlNsOk || !sourceWlOk || !sourceAppOk || !sourceVerOk || !destSvcNsOk || !destSvcNameOk || !destSvcOk || !destWlNsOk || !destWlOk || !destAppOk || !destVerOk || !protocolOk {
+			log.Warningf("populateSparklineMap: Skipping %s, missing expected labels", m.String())
+			continue
+		}
+
+		sourceWlNs := string(lSourceWlNs)
+		sourceWl := string(lSourceWl)
+		sourceApp := string(lSourceApp)
+		sourceVer := string(lSourceVer)
+		destSvc := string(lDestSvc)
+		protocol := string(lProtocol)
+
+		if (skipRequestsHttp && protocol == graph.HTTP.Name) || (skipRequestsGrpc && protocol == graph.GRPC.Name) {
+			continue
+		}
+
+		// handle clusters
+		sourceCluster, destCluster := util.HandleClusters(lSourceCluster, sourceClusterOk, lDestCluster, destClusterOk)
+
+		if util.IsBadSourceTelemetry(sourceCluster, sourceClusterOk, sourceWlNs, sourceWl, sourceApp) {
+			continue
+		}
+
+		// handle unusual destinations
+		destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer, _ := util.HandleDestination(sourceCluster, sourceWlNs, sourceWl, destCluster, string(lDestSvcNs), string(lDestSvc), string(lDestSvcName), string(lDestWlNs), string(lDestWl), string(lDestApp), string(lDestVer))
+
+		if util.IsBadDestTelemetry(destCluster, destClusterOk, destSvcNs, destSvc, destSvcName, destWl) {
+			continue
+		}
+
+		rates := a.toRates(s.Values, bounds)
+
+		// don't inject a service node if destSvcName is not set or the dest node is already a service node.
+		inject := false
+		if a.InjectServiceNodes && graph.IsOK(destSvcName) {
+			_, destNodeType := graph.Id(destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer, a.GraphType)
+			inject = (graph.NodeTypeService != destNodeType)
+		}
+
+		// rates can be aggregated, the series is added to both the incoming and outgoing edges of an injected service node
+		if inject {
+			a.addSparkline(sparklineMap, rates, protocol, sourceCluster, sourceWlNs, "", sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvcName, "", "", "", "")
+			a.addSparkline(sparklineMap, rates, protocol, destCluster, destSvcNs, destSvcName, "", "", "", destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer)
+		} else {
+			a.addSparkline(sparklineMap, rates, protocol, sourceCluster, sourceWlNs, "", sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer)
+		}
+	}
+}
+
+// toRates returns the Points rates of the sampled values, a missing or NaN value is a 0 rate
+func (a SparklineAppender) toRates(values []model.SamplePair, bounds prom_v1.Range) []float64 {
+	rates := make([]float64, a.Points)
+	for _, v := range values {
+		i := int(math.Round(float64(v.Timestamp.Time().Sub(bounds.Start)) / float64(bounds.Step)))
+		if i < 0 || i >= a.Points || math.IsNaN(float64(v.Value)) {
+			continue
+		}
+		rates[i] = float64(v.Value)
+	}
+	return rates
+}
+
+func (a SparklineAppender) addSparkline(sparklineMap map[string][]float64, rates []float64, protocol, sourceCluster, sourceNs, sourceSvc, sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvc, destWlNs, destWl, destApp, destVer string) {
+	sourceID, _ := graph.Id(sourceCluster, sourceNs, sourceSvc, sourceNs, sourceWl, sourceApp, sourceVer, a.GraphType)
+	destID, _ := graph.Id(destCluster, destSvcNs, destSvc, destWlNs, destWl, destApp, destVer, a.GraphType)
+	key := fmt.Sprintf("%s %s %s", sourceID, destID, protocol)
+
+	// Several series may contribute to the same edge (e.g. the workloads of an app), add them up
+	sparklineMap[key] = addRates(sparklineMap[key], rates)
+}
@@ -0,0 +1,170 @@
+package appender
+
+import (
+	"math"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/graph"
+)
+
+func TestSparkline(t *testing.T) {
+	assert := assert.New(t)
+
+	ingressToProductpage := model.Metric{
+		"source_cluster":                 business.DefaultClusterID,
+		"source_workload_namespace":      "istio-system",
+		"source_workload":                "ingressgateway-unknown",
+		"source_canonical_service":       "ingressgateway",
+		"source_canonical_revision":      model.LabelValue(graph.Unknown),
+		"destination_cluster":            business.DefaultClusterID,
+		"destination_service_namespace":  "bookinfo",
+		"destination_service":            "productpage.bookinfo.svc.cluster.local",
+		"destination_service_name":       "productpage",
+		"destination_workload_namespace": "bookinfo",
+		"destination_workload":           "productpage-v1",
+		"destination_canonical_service":  "productpage",
+		"destination_canonical_revision": "v1",
+		"request_protocol":               "http"}
+	productpageToReviewsV1 := model.Metric{
+		"source_cluster":                 business.DefaultClusterID,
+		"source_workload_namespace":      "bookinfo",
+		"source_workload":                "productpage-v1",
+		"source_canonical_service":       "productpage",
+		"source_canonical_revision":      "v1",
+		"destination_cluster":            business.DefaultClusterID,
+		"destination_service_namespace":  "bookinfo",
+		"destination_service":            "reviews.bookinfo.svc.cluster.local",
+		"destination_service_name":       "reviews",
+		"destination_workload_namespace": "bookinfo",
+		"destination_workload":           "reviews-v1",
+		"destination_canonical_service":  "reviews",
+		"destination_canonical_revision": "v1",
+		"request_protocol":               "http"}
+	productpageToReviewsV2 := model.Metric{
+		"source_cluster":                 business.DefaultClusterID,
+		"source_workload_namespace":      "bookinfo",
+		"source_workload":                "productpage-v1",
+		"source_canonical_service":       "productpage",
+		"source_canonical_revision":      "v1",
+		"destination_cluster":            business.DefaultClusterID,
+		"destination_service_namespace":  "bookinfo",
+		"destination_service":            "reviews.bookinfo.svc.cluster.local",
+		"destination_service_name":       "reviews",
+		"destination_workload_namespace": "bookinfo",
+		"destination_workload":           "reviews-v2",
+		"destination_canonical_service":  "reviews",
+		"destination_canonical_revision": "v2",
+		"request_protocol":               "http"}
+
+	// 4 points over 60s, a 15s step ending at the query time
+	queryTime := int64(1000)
+	samples := func(values ...float64) []model.SamplePair {
+		var pairs []model.SamplePair
+		for i, v := range values {
+			pairs = append(pairs, model.SamplePair{Timestamp: model.TimeFromUnix(queryTime - 45 + int64(i*15)), Value: model.SampleValue(v)})
+		}
+		return pairs
+	}
+
+	q0 := `round(sum(rate(istio_requests_total{reporter="destination",source_workload_namespace!="bookinfo",destination_service_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol),0.001)`
+	m0 := model.Matrix{
+		&model.SampleStream{
+			Metric: ingressToProductpage,
+			Values: samples(1.0, 2.0, 3.0, 4.0)}}
+
+	q1 := `round(sum(rate(istio_requests_total{reporter="source",source_workload_namespace="bookinfo"}[60s])) by (source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol),0.001)`
+	m1 := model.Matrix{
+		&model.SampleStream{
+			Metric: productpageToReviewsV1,
+			Values: samples(0.5, 0.5, 0.5, 0.5)},
+		&model.SampleStream{
+			// the first point is missing and the last one is NaN
+			Metric: productpageToReviewsV2,
+			Values: samples(1.0, 1.0, 1.0, math.NaN())[1:]}}
+
+	client, api, err := setupMocked()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	mockQueryRange(api, q0, &m0)
+	mockQueryRange(api, q1, &m1)
+
+	trafficMap := anomalyTestTraffic()
+	ingressID, _ := graph.Id(business.DefaultClusterID, "istio-system", "", "istio-system", "ingressgateway-unknown", "ingressgateway", graph.Unknown, graph.GraphTypeVersionedApp)
+	ingress, ok := trafficMap[ingressID]
+	assert.Equal(true, ok)
+	assert.Equal(1, len(ingress.Edges))
+	assert.Equal(nil, ingress.Edges[0].Metadata[graph.Sparkline])
+
+	duration, _ := time.ParseDuration("60s")
+	appender := SparklineAppender{
+		GraphType:          graph.GraphTypeVersionedApp,
+		InjectServiceNodes: false,
+		Namespaces: map[string]graph.NamespaceInfo{
+			"bookinfo": {
+				Name:     "bookinfo",
+				Duration: duration,
+			},
+		},
+		Points:    4,
+		QueryTime: queryTime,
+		Rates: graph.RequestedRates{
+			Grpc: graph.RateRequests,
+			Http: graph.RateRequests,
+			Tcp:  graph.RateTotal,
+		},
+	}
+
+	appender.appendGraph(trafficMap, "bookinfo", client)
+
+	assert.Equal(&graph.SparklineInfo{Rates: []float64{1.0, 2.0, 3.0, 4.0}, Step: 15}, ingress.Edges[0].Metadata[graph.Sparkline])
+	// the root node is assigned its outgoing series
+	assert.Equal(&graph.SparklineInfo{Rates: []float64{1.0, 2.0, 3.0, 4.0}, Step: 15}, ingress.Metadata[graph.Sparkline])
+
+	productpage := ingress.Edges[0].Dest
+	assert.Equal(&graph.SparklineInfo{Rates: []float64{1.0, 2.0, 3.0, 4.0}, Step: 15}, productpage.Metadata[graph.Sparkline])
+	assert.Equal(2, len(productpage.Edges))
+
+	for _, e := range productpage.Edges {
+		switch e.Dest.Version {
+		case "v1":
+			assert.Equal(&graph.SparklineInfo{Rates: []float64{0.5, 0.5, 0.5, 0.5}, Step: 15}, e.Metadata[graph.Sparkline])
+			assert.Equal(&graph.SparklineInfo{Rates: []float64{0.5, 0.5, 0.5, 0.5}, Step: 15}, e.Dest.Metadata[graph.Sparkline])
+		case "v2":
+			assert.Equal(&graph.SparklineInfo{Rates: []float64{0.0, 1.0, 1.0, 0.0}, Step: 15}, e.Metadata[graph.Sparkline])
+		default:
+			assert.Fail("unexpected reviews version", e.Dest.Version)
+		}
+	}
+}
+
+func TestParseSparklineAppender(t *testing.T) {
+	assert := assert.New(t)
+
+	o := graph.TelemetryOptions{
+		Appenders: graph.RequestedAppenders{All: true},
+	}
+	o.Params = url.Values{}
+	for _, a := range ParseAppenders(o) {
+		assert.NotEqual(SparklineAppenderName, a.Name())
+	}
+
+	o.Appenders = graph.RequestedAppenders{All: false, AppenderNames: []string{SparklineAppenderName}}
+	appenders := ParseAppenders(o)
+	assert.Equal(1, len(appenders))
+	assert.Equal(defaultSparklinePoints, appenders[0].(SparklineAppender).Points)
+
+	o.Params.Set("sparklinePoints", "30")
+	appenders = ParseAppenders(o)
+	assert.Equal(30, appenders[0].(SparklineAppender).Points)
+
+	o.Params.Set("sparklinePoints", "1")
+	assert.Panics(func() { ParseAppenders(o) })
+}
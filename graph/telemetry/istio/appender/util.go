@@ -38,3 +38,26 @@ func promQuery(query string, queryTime time.Time, ctx context.Context, api prom_
 
 	return nil
 }
+
+func promQueryRange(query string, bounds prom_v1.Range, ctx context.Context, api prom_v1.API, a graph.Appender) model.Matrix {
+	// wrap with a round() to be in line with metrics api
+	query = fmt.Sprintf("round(%s,0.001)", query)
+	log.Tracef("Appender range query:\n%s&start=%v&end=%v&step=%v (now=%v)\n", query, bounds.Start.Format(graph.TF), bounds.End.Format(graph.TF), bounds.Step, time.Now().Format(graph.TF))
+
+	promtimer := internalmetrics.GetPrometheusProcessingTimePrometheusTimer("Graph-Appender-" + a.Name())
+	value, warnings, err := api.QueryRange(ctx, query, bounds)
+	if warnings != nil && len(warnings) > 0 {
+		log.Warningf("promQueryRange. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
+	}
+	graph.CheckUnavailable(err)
+	promtimer.ObserveDuration() // notice we only collect metrics for successful prom queries
+
+	switch t := value.Type(); t {
+	case model.ValMatrix: // Range Vector
+		return value.(model.Matrix)
+	default:
+		graph.Error(fmt.Sprintf("No handling for type %v!\n", t))
+	}
+
+	return nil
+}
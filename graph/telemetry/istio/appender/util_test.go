@@ -38,3 +38,18 @@ func mockQuery(api *prometheustest.PromAPIMock, query string, ret *model.Vector)
 		mock.AnythingOfType("time.Time"),
 	).Return(*ret, nil)
 }
+
+func mockQueryRange(api *prometheustest.PromAPIMock, query string, ret *model.Matrix) {
+	api.On(
+		"QueryRange",
+		mock.AnythingOfType("*context.emptyCtx"),
+		query,
+		mock.AnythingOfType("v1.Range"),
+	).Return(*ret, nil)
+	api.On(
+		"QueryRange",
+		mock.AnythingOfType("*context.cancelCtx"),
+		query,
+		mock.AnythingOfType("v1.Range"),
+	).Return(*ret, nil)
+}
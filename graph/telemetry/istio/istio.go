@@ -40,10 +40,43 @@ const (
 	tsHashMap graph.MetadataKey = "tsHashMap"
 )
 
+const (
+	tcpConnectionsClosedMetric    = "istio_tcp_connections_closed_total"
+	tcpConnectionsLongLivedMetric = "istio_tcp_connections_long_lived" // not an Istio metric, see tcpQuery
+	tcpConnectionsOpenedMetric    = "istio_tcp_connections_opened_total"
+)
+
 var (
 	grpcMetric = regexp.MustCompile(`istio_.*_messages`)
 )
 
+// Vendor is the Istio implementation of graph.TelemetryVendor
+type Vendor struct{}
+
+func init() {
+	graph.RegisterTelemetryVendor(Vendor{})
+}
+
+// Name is required by the graph/TelemetryVendor interface
+func (v Vendor) Name() string {
+	return graph.VendorIstio
+}
+
+// Appenders is required by the graph/TelemetryVendor interface
+func (v Vendor) Appenders() []string {
+	return appender.AppenderNames
+}
+
+// BuildNamespacesTrafficMap is required by the graph/TelemetryVendor interface
+func (v Vendor) BuildNamespacesTrafficMap(o graph.TelemetryOptions, client *prometheus.Client, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	return BuildNamespacesTrafficMap(o, client, globalInfo)
+}
+
+// BuildNodeTrafficMap is required by the graph/TelemetryVendor interface
+func (v Vendor) BuildNodeTrafficMap(o graph.TelemetryOptions, client *prometheus.Client, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	return BuildNodeTrafficMap(o, client, globalInfo)
+}
+
 // BuildNamespacesTrafficMap is required by the graph/TelemtryVendor interface
 func BuildNamespacesTrafficMap(o graph.TelemetryOptions, client *prometheus.Client, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
 	log.Tracef("Build [%s] graph for [%d] namespaces [%v]", o.GraphType, len(o.Namespaces), o.Namespaces)
@@ -54,14 +87,12 @@ func BuildNamespacesTrafficMap(o graph.TelemetryOptions, client *prometheus.Clie
 	for _, namespace := range o.Namespaces {
 		log.Tracef("Build traffic map for namespace [%v]", namespace)
 		namespaceTrafficMap := buildNamespaceTrafficMap(namespace.Name, o, client)
+		pruneHidden(namespaceTrafficMap, o, appenders)
 		namespaceInfo := graph.NewAppenderNamespaceInfo(namespace.Name)
-		for _, a := range appenders {
-			appenderTimer := internalmetrics.GetGraphAppenderTimePrometheusTimer(a.Name())
-			a.AppendGraph(namespaceTrafficMap, globalInfo, namespaceInfo)
-			appenderTimer.ObserveDuration()
-		}
+		telemetry.RunAppenders(appenders, namespaceTrafficMap, globalInfo, namespaceInfo)
 		telemetry.MergeTrafficMaps(trafficMap, namespace.Name, namespaceTrafficMap)
 	}
+	telemetry.RunFinalizers(appender.ParseFinalizers(o), trafficMap, globalInfo)
 
 	// The appenders can add/remove/alter nodes. After the manipulations are complete
 	// we can make some final adjustments:
@@ -77,6 +108,22 @@ func BuildNamespacesTrafficMap(o graph.TelemetryOptions, client *prometheus.Clie
 	return trafficMap
 }
 
+// pruneHidden removes the hidden nodes and edges prior to running the appenders, saving the appender work
+// for them. This is possible only if the hide expression can be evaluated prior to running the appenders, otherwise
+// it is applied to the final TrafficMap. It is not done when the idle node appender runs, as it would add back the
+// nodes orphaned by the pruning, which the final hide then keeps as idle nodes.
+func pruneHidden(trafficMap graph.TrafficMap, o graph.TelemetryOptions, appenders []graph.Appender) {
+	if o.Hide == nil || !o.Hide.IsTelemetryOnly() {
+		return
+	}
+	for _, a := range appenders {
+		if a.Name() == appender.IdleNodeAppenderName {
+			return
+		}
+	}
+	graph.HideTrafficMap(trafficMap, o.Hide)
+}
+
 // buildNamespaceTrafficMap returns a map of all namespace nodes (key=id).  All
 // nodes either directly send and/or receive requests from a node in the namespace.
 func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client *prometheus.Client) graph.TrafficMap {
@@ -92,6 +139,9 @@ func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client
 	if o.Rates.Http == graph.RateRequests || o.Rates.Grpc == graph.RateRequests {
 		metric := "istio_requests_total"
 		groupBy := "source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol,response_code,grpc_response_status,response_flags"
+		if o.IncludeRoutes {
+			groupBy += ",route_name,request_path"
+		}
 
 		// 0) Incoming: query source telemetry to capture unserviced namespace services' incoming traffic
 		query := fmt.Sprintf(`sum(rate(%s{reporter="source",source_workload_namespace!="%s",destination_workload_namespace="unknown",destination_workload="unknown",destination_service=~"^.+\\.%s\\..+$"} [%vs])) by (%s) %s`,
@@ -153,7 +203,7 @@ func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client
 			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
-			// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic	query = fmt.Sprintf(`sum(rate(%s{reporter="destination",destination_service_namespace="%s"} [%vs])) by (%s) %s`,
+			// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic
 			query = fmt.Sprintf(`sum(rate(%s{reporter="destination",destination_workload_namespace="%s"} [%vs])) by (%s) %s`,
 				metric,
 				namespace,
@@ -175,7 +225,7 @@ func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client
 		}
 	}
 
-	// TCP Byte traffic
+	// TCP Byte or connection traffic
 	if o.Rates.Tcp != graph.RateNone {
 		var metrics []string
 		groupBy := "source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags"
@@ -187,40 +237,30 @@ func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client
 			metrics = []string{"istio_tcp_sent_bytes_total"}
 		case graph.RateTotal:
 			metrics = []string{"istio_tcp_sent_bytes_total", "istio_tcp_received_bytes_total"}
+		case graph.RateConnections:
+			metrics = []string{tcpConnectionsOpenedMetric, tcpConnectionsClosedMetric, tcpConnectionsLongLivedMetric}
 		default:
 			metrics = []string{}
 		}
 
 		for _, metric := range metrics {
 			// 0) Incoming: query source telemetry to capture unserviced namespace services' incoming traffic
-			query := fmt.Sprintf(`sum(rate(%s{reporter="source",source_workload_namespace!="%s",destination_workload_namespace="unknown",destination_workload="unknown",destination_service=~"^.+\\.%s\\..+$"} [%vs])) by (%s) %s`,
-				metric,
+			selector := fmt.Sprintf(`reporter="source",source_workload_namespace!="%s",destination_workload_namespace="unknown",destination_workload="unknown",destination_service=~"^.+\\.%s\\..+$"`,
 				namespace,
-				namespace,
-				int(duration.Seconds()), // range duration for the query
-				groupBy,
-				idleCondition)
-			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+				namespace)
+			incomingVector := promQuery(tcpQuery(metric, selector, duration, groupBy, idleCondition), time.Unix(o.QueryTime, 0), client.API())
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
-			// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic	query = fmt.Sprintf(`sum(rate(%s{reporter="destination",destination_service_namespace="%s"} [%vs])) by (%s) %s`,
-			query = fmt.Sprintf(`sum(rate(%s{reporter="destination",destination_workload_namespace="%s"} [%vs])) by (%s) %s`,
-				metric,
-				namespace,
-				int(duration.Seconds()), // range duration for the query
-				groupBy,
-				idleCondition)
-			incomingVector = promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic
+			selector = fmt.Sprintf(`reporter="destination",destination_workload_namespace="%s"`,
+				namespace)
+			incomingVector = promQuery(tcpQuery(metric, selector, duration, groupBy, idleCondition), time.Unix(o.QueryTime, 0), client.API())
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 			// 2) Outgoing: query source telemetry to capture namespace workloads' outgoing traffic
-			query = fmt.Sprintf(`sum(rate(%s{reporter="source",source_workload_namespace="%s"} [%vs])) by (%s) %s`,
-				metric,
-				namespace,
-				int(duration.Seconds()), // range duration for the query
-				groupBy,
-				idleCondition)
-			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			selector = fmt.Sprintf(`reporter="source",source_workload_namespace="%s"`,
+				namespace)
+			outgoingVector := promQuery(tcpQuery(metric, selector, duration, groupBy, idleCondition), time.Unix(o.QueryTime, 0), client.API())
 			populateTrafficMap(trafficMap, &outgoingVector, metric, o)
 		}
 	}
@@ -318,17 +358,27 @@ func populateTrafficMap(trafficMap graph.TrafficMap, vector *model.Vector, metri
 		// make code more readable by setting "host" because "destSvc" holds destination.service.host | request.host | "unknown"
 		host := destSvc
 
+		// the route name is preferred, the request path is only present with customized telemetry
+		route := ""
+		if isRequests && o.IncludeRoutes {
+			if lRoute, routeOk := m["route_name"]; routeOk && graph.IsOK(string(lRoute)) {
+				route = string(lRoute)
+			} else if lPath, pathOk := m["request_path"]; pathOk && graph.IsOK(string(lPath)) {
+				route = string(lPath)
+			}
+		}
+
 		// don't inject a service node if destSvcName is not set or the dest node is already a service node.
 		inject := false
 		if o.InjectServiceNodes && graph.IsOK(destSvcName) {
 			_, destNodeType := graph.Id(destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer, o.GraphType)
 			inject = (graph.NodeTypeService != destNodeType)
 		}
-		addTraffic(trafficMap, metric, inject, val, protocol, code, flags, host, sourceCluster, sourceWlNs, "", sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer, o)
+		addTraffic(trafficMap, metric, inject, val, protocol, code, flags, host, route, sourceCluster, sourceWlNs, "", sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer, o)
 	}
 }
 
-func addTraffic(trafficMap graph.TrafficMap, metric string, inject bool, val float64, protocol, code, flags, host, sourceCluster, sourceNs, sourceSvc, sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer string, o graph.TelemetryOptions) {
+func addTraffic(trafficMap graph.TrafficMap, metric string, inject bool, val float64, protocol, code, flags, host, route, sourceCluster, sourceNs, sourceSvc, sourceWl, sourceApp, sourceVer, destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer string, o graph.TelemetryOptions) {
 	source, _ := addNode(trafficMap, sourceCluster, sourceNs, sourceSvc, sourceNs, sourceWl, sourceApp, sourceVer, o)
 	dest, _ := addNode(trafficMap, destCluster, destSvcNs, destSvcName, destWlNs, destWl, destApp, destVer, o)
 
@@ -336,18 +386,18 @@ func addTraffic(trafficMap graph.TrafficMap, metric string, inject bool, val flo
 	// processing the same information twice we keep track of the time series applied to a particular edge. The
 	// edgeTSHash incorporates information about the time series' source, destination and metric information,
 	// and uses that unique TS has to protect against applying the same intomation twice.
-	edgeTSHash := fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%s:%s:%s:%s:%s:%s", metric, source.Metadata[tsHash], dest.Metadata[tsHash], code, flags, host))))
+	edgeTSHash := fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%s:%s:%s:%s:%s:%s:%s", metric, source.Metadata[tsHash], dest.Metadata[tsHash], code, flags, host, route))))
 
 	if inject {
 		injectedService, _ := addNode(trafficMap, destCluster, destSvcNs, destSvcName, "", "", "", "", o)
-		if addEdgeTraffic(trafficMap, val, protocol, code, flags, host, source, injectedService, edgeTSHash, o) {
+		if addEdgeTraffic(trafficMap, metric, val, protocol, code, flags, host, route, source, injectedService, edgeTSHash, o) {
 			addToDestServices(injectedService.Metadata, destCluster, destSvcNs, destSvcName)
 
-			addEdgeTraffic(trafficMap, val, protocol, code, flags, host, injectedService, dest, edgeTSHash, o)
+			addEdgeTraffic(trafficMap, metric, val, protocol, code, flags, host, route, injectedService, dest, edgeTSHash, o)
 			addToDestServices(dest.Metadata, destCluster, destSvcNs, destSvcName)
 		}
 	} else {
-		if addEdgeTraffic(trafficMap, val, protocol, code, flags, host, source, dest, edgeTSHash, o) {
+		if addEdgeTraffic(trafficMap, metric, val, protocol, code, flags, host, route, source, dest, edgeTSHash, o) {
 			addToDestServices(dest.Metadata, destCluster, destSvcNs, destSvcName)
 		}
 	}
@@ -355,7 +405,7 @@ func addTraffic(trafficMap graph.TrafficMap, metric string, inject bool, val flo
 
 // addEdgeTraffic uses edgeTSHash that the metric information has not been applied to the edge. Returns true
 // if the the metric information is applied, false if it determined to be a duplicate.
-func addEdgeTraffic(trafficMap graph.TrafficMap, val float64, protocol, code, flags, host string, source, dest *graph.Node, edgeTSHash string, o graph.TelemetryOptions) bool {
+func addEdgeTraffic(trafficMap graph.TrafficMap, metric string, val float64, protocol, code, flags, host, route string, source, dest *graph.Node, edgeTSHash string, o graph.TelemetryOptions) bool {
 
 	var edge *graph.Edge
 	for _, e := range source.Edges {
@@ -372,7 +422,17 @@ func addEdgeTraffic(trafficMap graph.TrafficMap, val float64, protocol, code, fl
 
 	if _, ok := edge.Metadata[tsHashMap].(map[string]bool)[edgeTSHash]; !ok {
 		edge.Metadata[tsHashMap].(map[string]bool)[edgeTSHash] = true
-		graph.AddToMetadata(protocol, val, code, flags, host, source.Metadata, dest.Metadata, edge.Metadata)
+		switch metric {
+		case tcpConnectionsClosedMetric:
+			graph.AddToMetadataTCPConnections(val, 0.0, edge.Metadata)
+		case tcpConnectionsLongLivedMetric:
+			graph.AddToMetadataTCPConnections(0.0, val, edge.Metadata)
+		default:
+			graph.AddToMetadata(protocol, val, code, flags, host, source.Metadata, dest.Metadata, edge.Metadata)
+			if route != "" {
+				graph.AddRouteToMetadata(protocol, val, code, route, edge.Metadata)
+			}
+		}
 		return true
 	}
 
@@ -424,14 +484,12 @@ func BuildNodeTrafficMap(o graph.TelemetryOptions, client *prometheus.Client, gl
 
 	appenders := appender.ParseAppenders(o)
 	trafficMap := buildNodeTrafficMap(o.Cluster, o.NodeOptions.Namespace, n, o, client)
+	pruneHidden(trafficMap, o, appenders)
 
 	namespaceInfo := graph.NewAppenderNamespaceInfo(o.NodeOptions.Namespace)
 
-	for _, a := range appenders {
-		appenderTimer := internalmetrics.GetGraphAppenderTimePrometheusTimer(a.Name())
-		a.AppendGraph(trafficMap, globalInfo, namespaceInfo)
-		appenderTimer.ObserveDuration()
-	}
+	telemetry.RunAppenders(appenders, trafficMap, globalInfo, namespaceInfo)
+	telemetry.RunFinalizers(appender.ParseFinalizers(o), trafficMap, globalInfo)
 
 	// The appenders can add/remove/alter nodes. After the manipulations are complete
 	// we can make some final adjustments:
@@ -470,6 +528,9 @@ func buildNodeTrafficMap(cluster, namespace string, n graph.Node, o graph.Teleme
 	if o.Rates.Http == graph.RateRequests || o.Rates.Grpc == graph.RateRequests {
 		metric := "istio_requests_total"
 		groupBy := "source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,request_protocol,response_code,grpc_response_status,response_flags"
+		if o.IncludeRoutes {
+			groupBy += ",route_name,request_path"
+		}
 
 		// query prometheus for request traffic in two queries:
 		// 1) query for incoming traffic
@@ -685,7 +746,7 @@ func buildNodeTrafficMap(cluster, namespace string, n graph.Node, o graph.Teleme
 		}
 	}
 
-	// TCP byte traffic
+	// TCP byte or connection traffic
 	if o.Rates.Tcp != graph.RateNone {
 		var metrics []string
 		groupBy := "source_cluster,source_workload_namespace,source_workload,source_canonical_service,source_canonical_revision,destination_cluster,destination_service_namespace,destination_service,destination_service_name,destination_workload_namespace,destination_workload,destination_canonical_service,destination_canonical_revision,response_flags"
@@ -697,99 +758,73 @@ func buildNodeTrafficMap(cluster, namespace string, n graph.Node, o graph.Teleme
 			metrics = []string{"istio_tcp_sent_bytes_total"}
 		case graph.RateTotal:
 			metrics = []string{"istio_tcp_sent_bytes_total", "istio_tcp_received_bytes_total"}
+		case graph.RateConnections:
+			metrics = []string{tcpConnectionsOpenedMetric, tcpConnectionsClosedMetric, tcpConnectionsLongLivedMetric}
 		default:
 			metrics = []string{}
 		}
 
 		for _, metric := range metrics {
-			var query string
+			var selector string
 
 			switch n.NodeType {
 			case graph.NodeTypeWorkload:
-				query = fmt.Sprintf(`sum(rate(%s{reporter="destination"%s,destination_workload_namespace="%s",destination_workload="%s"} [%vs])) by (%s) %s`,
-					metric,
+				selector = fmt.Sprintf(`reporter="destination"%s,destination_workload_namespace="%s",destination_workload="%s"`,
 					destCluster,
 					namespace,
-					n.Workload,
-					int(duration.Seconds()), // range duration for the query
-					groupBy,
-					idleCondition)
+					n.Workload)
 			case graph.NodeTypeApp:
 				if graph.IsOK(n.Version) {
-					query = fmt.Sprintf(`sum(rate(%s{reporter="destination"%s,destination_service_namespace="%s",destination_canonical_service="%s",destination_canonical_revision="%s"} [%vs])) by (%s) %s`,
-						metric,
+					selector = fmt.Sprintf(`reporter="destination"%s,destination_service_namespace="%s",destination_canonical_service="%s",destination_canonical_revision="%s"`,
 						destCluster,
 						namespace,
 						n.App,
-						n.Version,
-						int(duration.Seconds()), // range duration for the query
-						groupBy,
-						idleCondition)
+						n.Version)
 				} else {
-					query = fmt.Sprintf(`sum(rate(%s{reporter="destination"%s,destination_service_namespace="%s",destination_canonical_service="%s"} [%vs])) by (%s) %s`,
-						metric,
+					selector = fmt.Sprintf(`reporter="destination"%s,destination_service_namespace="%s",destination_canonical_service="%s"`,
 						destCluster,
 						namespace,
-						n.App,
-						int(duration.Seconds()), // range duration for the query
-						groupBy,
-						idleCondition)
+						n.App)
 				}
 			case graph.NodeTypeService:
 				// TODO: Do we need to handle requests from unknown in a special way (like in HTTP above)? Not sure how tcp is reported from unknown.
-				query = fmt.Sprintf(`sum(rate(%s{reporter="destination"%s,destination_service_namespace="%s",destination_service=~"^%s\\.%s\\..*$"} [%vs])) by (%s) %s`,
-					metric,
+				selector = fmt.Sprintf(`reporter="destination"%s,destination_service_namespace="%s",destination_service=~"^%s\\.%s\\..*$"`,
 					destCluster,
 					namespace,
 					n.Service,
-					namespace,
-					int(duration.Seconds()), // range duration for the query
-					groupBy,
-					idleCondition)
+					namespace)
 			default:
 				graph.Error(fmt.Sprintf("NodeType [%s] not supported", n.NodeType))
 			}
-			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			incomingVector := promQuery(tcpQuery(metric, selector, duration, groupBy, idleCondition), time.Unix(o.QueryTime, 0), client.API())
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 			// 2) query for outbound traffic
 			switch n.NodeType {
 			case graph.NodeTypeWorkload:
-				query = fmt.Sprintf(`sum(rate(%s{reporter="source"%s,source_workload_namespace="%s",source_workload="%s"} [%vs])) by (%s) %s`,
-					metric,
+				selector = fmt.Sprintf(`reporter="source"%s,source_workload_namespace="%s",source_workload="%s"`,
 					sourceCluster,
 					namespace,
-					n.Workload,
-					int(duration.Seconds()), // range duration for the query
-					groupBy,
-					idleCondition)
+					n.Workload)
 			case graph.NodeTypeApp:
 				if graph.IsOK(n.Version) {
-					query = fmt.Sprintf(`sum(rate(%s{reporter="source"%s,source_workload_namespace="%s",source_canonical_service="%s",source_canonical_revision="%s"} [%vs])) by (%s) %s`,
-						metric,
+					selector = fmt.Sprintf(`reporter="source"%s,source_workload_namespace="%s",source_canonical_service="%s",source_canonical_revision="%s"`,
 						sourceCluster,
 						namespace,
 						n.App,
-						n.Version,
-						int(duration.Seconds()), // range duration for the query
-						groupBy,
-						idleCondition)
+						n.Version)
 				} else {
-					query = fmt.Sprintf(`sum(rate(%s{reporter="source"%s,source_workload_namespace="%s",source_canonical_service="%s"} [%vs])) by (%s) %s`,
-						metric,
+					selector = fmt.Sprintf(`reporter="source"%s,source_workload_namespace="%s",source_canonical_service="%s"`,
 						sourceCluster,
 						namespace,
-						n.App,
-						int(duration.Seconds()), // range duration for the query
-						groupBy,
-						idleCondition)
+						n.App)
 				}
 			case graph.NodeTypeService:
-				query = ""
+				selector = ""
 			default:
 				graph.Error(fmt.Sprintf("NodeType [%s] not supported", n.NodeType))
 			}
-			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			outgoingVector := promQuery(tcpQuery(metric, selector, duration, groupBy, idleCondition), time.Unix(o.QueryTime, 0), client.API())
 			populateTrafficMap(trafficMap, &outgoingVector, metric, o)
 		}
 	}
@@ -810,11 +845,8 @@ func handleAggregateNodeTrafficMap(o graph.TelemetryOptions, client *prometheus.
 
 	namespaceInfo := graph.NewAppenderNamespaceInfo(o.NodeOptions.Namespace)
 
-	for _, a := range appenders {
-		appenderTimer := internalmetrics.GetGraphAppenderTimePrometheusTimer(a.Name())
-		a.AppendGraph(trafficMap, globalInfo, namespaceInfo)
-		appenderTimer.ObserveDuration()
-	}
+	telemetry.RunAppenders(appenders, trafficMap, globalInfo, namespaceInfo)
+	telemetry.RunFinalizers(appender.ParseFinalizers(o), trafficMap, globalInfo)
 
 	// The appenders can add/remove/alter nodes. After the manipulations are complete
 	// we can make some final adjustments:
@@ -867,6 +899,34 @@ func buildAggregateNodeTrafficMap(namespace string, n graph.Node, o graph.Teleme
 	return trafficMap
 }
 
+// tcpQuery returns the query for a TCP metric and time series selector, or "" for an empty selector. The
+// long-lived connections are estimated as the connections open at query time (opened - closed), less the
+// connections opened during the time window. It is a lower bound, connections opened and closed within the
+// time window can offset connections opened prior to it.
+func tcpQuery(metric, selector string, duration time.Duration, groupBy, idleCondition string) string {
+	if selector == "" {
+		return ""
+	}
+	if metric != tcpConnectionsLongLivedMetric {
+		return fmt.Sprintf(`sum(rate(%s{%s} [%vs])) by (%s) %s`,
+			metric,
+			selector,
+			int(duration.Seconds()), // range duration for the query
+			groupBy,
+			idleCondition)
+	}
+
+	opened := fmt.Sprintf(`sum(%s{%s}) by (%s)`, tcpConnectionsOpenedMetric, selector, groupBy)
+	closed := fmt.Sprintf(`sum(%s{%s}) by (%s)`, tcpConnectionsClosedMetric, selector, groupBy)
+	openedInWindow := fmt.Sprintf(`sum(increase(%s{%s} [%vs])) by (%s)`,
+		tcpConnectionsOpenedMetric,
+		selector,
+		int(duration.Seconds()), // range duration for the query
+		groupBy)
+	// a time series with no closed connections has no closed metric, the "or" preserves its opened connections
+	return fmt.Sprintf(`((%s - %s) or %s) - %s > 0`, opened, closed, opened, openedInWindow)
+}
+
 func promQuery(query string, queryTime time.Time, api prom_v1.API) model.Vector {
 	if query == "" {
 		return model.Vector{}
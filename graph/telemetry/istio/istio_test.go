@@ -0,0 +1,41 @@
+package istio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/telemetry/istio/appender"
+)
+
+func TestPruneHidden(t *testing.T) {
+	assert := assert.New(t)
+
+	newTrafficMap := func() graph.TrafficMap {
+		trafficMap := graph.NewTrafficMap()
+		productpage := graph.NewNode("east", "bookinfo", "", "bookinfo", "productpage-v1", "productpage", "v1", graph.GraphTypeWorkload)
+		reviews := graph.NewNode("east", "bookinfo", "", "bookinfo", "reviews-v1", "reviews", "v1", graph.GraphTypeWorkload)
+		trafficMap[productpage.ID] = &productpage
+		trafficMap[reviews.ID] = &reviews
+		e := productpage.AddEdge(&reviews)
+		e.Metadata[graph.ProtocolKey] = "http"
+		graph.AddToMetadata("http", 0.5, "200", "-", "", productpage.Metadata, reviews.Metadata, e.Metadata)
+		return trafficMap
+	}
+
+	hide, err := graph.ParseFindExpression("http < 1")
+	assert.NoError(err)
+	o := graph.TelemetryOptions{}
+	o.Hide = hide
+
+	// the nodes orphaned by the hidden edge are pruned
+	trafficMap := newTrafficMap()
+	pruneHidden(trafficMap, o, []graph.Appender{appender.DeadNodeAppender{}})
+	assert.Empty(trafficMap)
+
+	// unless the idle node appender would add them back, the hide is then applied to the final TrafficMap
+	trafficMap = newTrafficMap()
+	pruneHidden(trafficMap, o, []graph.Appender{appender.IdleNodeAppender{}})
+	assert.Equal(2, len(trafficMap))
+}
@@ -0,0 +1,581 @@
+// Package jaeger provides the Jaeger implementation of graph/TelemetryProvider.
+package jaeger
+
+// Jaeger.go is responsible for generating TrafficMaps using Jaeger traces.  It implements the
+// TelemetryVendor interface.  Traces still show the call relationships when Prometheus metrics are
+// unavailable, for example due to a short retention.
+//
+// The algorithm is two-pass:
+//   First Pass: Query Jaeger for the traces of the apps in the requested namespaces. Every span with a
+//               parent span reported by a different node is a request from the parent node to the span's
+//               node. Build a traffic map to provide a full representation of nodes and edges.
+//
+//   Second Pass: Apply any requested appenders to alter or append to the graph. Only the Istio appenders
+//                not requiring Prometheus are supported.
+//
+// Traces are typically sampled, and so the request rates reflect only the sampled requests. Only
+// request traffic is supported, TCP traffic is not traced.
+//
+// Supports two vendor-specific query parameters:
+//   responseTime: Must be one of: avg | 50 | 95 | 99 (default: 95)
+//   traceLimit: The maximum number of traces fetched per app (default: 100)
+//
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/telemetry"
+	"github.com/kiali/kiali/graph/telemetry/istio/appender"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/prometheus"
+)
+
+// TraceStatsKey is the edge metadata key for the edge's TraceStats
+const TraceStatsKey graph.MetadataKey = "traceStats"
+
+const (
+	defaultQuantile   = 0.95
+	defaultTraceLimit = 100
+)
+
+// AppenderNames are the supported appenders, those not requiring Prometheus, in run order
+var AppenderNames = []string{
+	appender.ServiceEntryAppenderName,
+	appender.DeadNodeAppenderName,
+	appender.WorkloadEntryAppenderName,
+	appender.HealthConfigAppenderName,
+	appender.IdleNodeAppenderName,
+	appender.IstioAppenderName,
+	appender.SidecarsCheckAppenderName,
+	appender.RootCauseAppenderName,
+}
+
+// TraceStats holds the statistics of the traced requests for an edge. Response times are in millis.
+type TraceStats struct {
+	Errors   int     `json:"errors"`
+	Requests int     `json:"requests"`
+	Avg      float64 `json:"avg"`
+	P50      float64 `json:"p50"`
+	P95      float64 `json:"p95"`
+	P99      float64 `json:"p99"`
+}
+
+// Vendor is the Jaeger implementation of graph.TelemetryVendor
+type Vendor struct{}
+
+func init() {
+	graph.RegisterTelemetryVendor(Vendor{})
+}
+
+// Name is required by the graph/TelemetryVendor interface
+func (v Vendor) Name() string {
+	return graph.VendorJaeger
+}
+
+// Appenders is required by the graph/TelemetryVendor interface
+func (v Vendor) Appenders() []string {
+	return AppenderNames
+}
+
+// BuildNamespacesTrafficMap is required by the graph/TelemetryVendor interface
+func (v Vendor) BuildNamespacesTrafficMap(o graph.TelemetryOptions, client *prometheus.Client, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	return BuildNamespacesTrafficMap(o, globalInfo)
+}
+
+// BuildNodeTrafficMap is required by the graph/TelemetryVendor interface
+func (v Vendor) BuildNodeTrafficMap(o graph.TelemetryOptions, client *prometheus.Client, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	return BuildNodeTrafficMap(o, globalInfo)
+}
+
+// spanNode identifies the node reporting a span
+type spanNode struct {
+	namespace string
+	workload  string
+	app       string
+	version   string
+}
+
+// edgeSamples collects the traced request durations, in millis, for an edge
+type edgeSamples struct {
+	durations []float64
+	errors    int
+}
+
+// trafficBuilder builds a TrafficMap from traces
+type trafficBuilder struct {
+	cluster    string
+	duration   time.Duration
+	include    func(source, dest *graph.Node) bool
+	o          graph.TelemetryOptions
+	samples    map[*graph.Edge]*edgeSamples
+	traceIDs   map[jaegerModels.TraceID]bool
+	trafficMap graph.TrafficMap
+}
+
+// BuildNamespacesTrafficMap returns the traffic map of the requested namespaces
+func BuildNamespacesTrafficMap(o graph.TelemetryOptions, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	log.Tracef("Build [%s] trace graph for [%d] namespaces [%v]", o.GraphType, len(o.Namespaces), o.Namespaces)
+
+	appenders := parseAppenders(o)
+	trafficMap := graph.NewTrafficMap()
+	cluster := homeCluster(globalInfo)
+
+	for _, namespace := range o.Namespaces {
+		log.Tracef("Build trace traffic map for namespace [%v]", namespace)
+		namespaceName := namespace.Name
+		b := newTrafficBuilder(cluster, namespace.Duration, o, func(source, dest *graph.Node) bool {
+			return source.Namespace == namespaceName || dest.Namespace == namespaceName
+		})
+		b.addNamespaceTraces(globalInfo.Business, namespaceName)
+		namespaceTrafficMap := b.finish()
+
+		namespaceInfo := graph.NewAppenderNamespaceInfo(namespaceName)
+		telemetry.RunAppenders(appenders, namespaceTrafficMap, globalInfo, namespaceInfo)
+		telemetry.MergeTrafficMaps(trafficMap, namespaceName, namespaceTrafficMap)
+	}
+	telemetry.RunFinalizers(appender.ParseFinalizers(o), trafficMap, globalInfo)
+
+	// The appenders can add/remove/alter nodes. After the manipulations are complete
+	// we can make some final adjustments:
+	// - mark the outsiders (i.e. nodes not in the requested namespaces)
+	// - mark the insider traffic generators (i.e. inside the namespace and only outgoing edges)
+	telemetry.MarkOutsideOrInaccessible(trafficMap, o)
+	telemetry.MarkTrafficGenerators(trafficMap)
+
+	if graph.GraphTypeService == o.GraphType {
+		trafficMap = telemetry.ReduceToServiceGraph(trafficMap)
+	}
+
+	return trafficMap
+}
+
+// BuildNodeTrafficMap returns the traffic map of the requests to and from the requested node
+func BuildNodeTrafficMap(o graph.TelemetryOptions, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	if o.NodeOptions.Aggregate != "" {
+		graph.BadRequest(fmt.Sprintf("TelemetryVendor [%s] does not support aggregate node graphs", graph.VendorJaeger))
+	}
+
+	log.Tracef("Build trace graph for node [%+v]", o.NodeOptions)
+
+	appenders := parseAppenders(o)
+	namespace := o.NodeOptions.Namespace
+	b := newTrafficBuilder(homeCluster(globalInfo), o.Namespaces[namespace].Duration, o, func(source, dest *graph.Node) bool {
+		return isRequestedNode(source, o.NodeOptions) || isRequestedNode(dest, o.NodeOptions)
+	})
+	b.addNamespaceTraces(globalInfo.Business, namespace)
+	trafficMap := b.finish()
+
+	namespaceInfo := graph.NewAppenderNamespaceInfo(namespace)
+	telemetry.RunAppenders(appenders, trafficMap, globalInfo, namespaceInfo)
+	telemetry.RunFinalizers(appender.ParseFinalizers(o), trafficMap, globalInfo)
+
+	// The appenders can add/remove/alter nodes. After the manipulations are complete
+	// we can make some final adjustments:
+	// - mark the outsiders (i.e. nodes not in the requested namespaces)
+	// - mark the traffic generators
+	telemetry.MarkOutsideOrInaccessible(trafficMap, o)
+	telemetry.MarkTrafficGenerators(trafficMap)
+
+	return trafficMap
+}
+
+// parseAppenders returns the requested appenders, all supported appenders by default
+func parseAppenders(o graph.TelemetryOptions) []graph.Appender {
+	if o.Appenders.All {
+		o.Appenders = graph.RequestedAppenders{All: false, AppenderNames: AppenderNames}
+	}
+	return appender.ParseAppenders(o)
+}
+
+// homeCluster resolves the cluster for the traced nodes, in the same way as the appenders
+func homeCluster(globalInfo *graph.AppenderGlobalInfo) string {
+	if globalInfo.HomeCluster == "" {
+		globalInfo.HomeCluster = business.DefaultClusterID
+		c, err := globalInfo.Business.Mesh.ResolveKialiControlPlaneCluster(nil)
+		graph.CheckError(err)
+		if c != nil {
+			globalInfo.HomeCluster = c.Name
+		}
+	}
+	return globalInfo.HomeCluster
+}
+
+// isRequestedNode returns true if the node is the node requested for a node graph
+func isRequestedNode(n *graph.Node, no graph.NodeOptions) bool {
+	if n.Namespace != no.Namespace {
+		return false
+	}
+	switch {
+	case no.Workload != "":
+		return n.NodeType == graph.NodeTypeWorkload && n.Workload == no.Workload
+	case no.App != "":
+		return n.NodeType == graph.NodeTypeApp && n.App == no.App && (no.Version == "" || n.Version == no.Version)
+	case no.Service != "":
+		return n.NodeType == graph.NodeTypeService && n.Service == no.Service
+	}
+	return false
+}
+
+func newTrafficBuilder(cluster string, duration time.Duration, o graph.TelemetryOptions, include func(source, dest *graph.Node) bool) *trafficBuilder {
+	return &trafficBuilder{
+		cluster:    cluster,
+		duration:   duration,
+		include:    include,
+		o:          o,
+		samples:    make(map[*graph.Edge]*edgeSamples),
+		traceIDs:   make(map[jaegerModels.TraceID]bool),
+		trafficMap: graph.NewTrafficMap(),
+	}
+}
+
+// addNamespaceTraces adds the traced requests of every app in the namespace
+func (b *trafficBuilder) addNamespaceTraces(layer *business.Layer, namespace string) {
+	apps, err := layer.Jaeger.GetNamespaceApps(namespace)
+	graph.CheckError(err)
+
+	traceLimit := defaultTraceLimit
+	if traceLimitString := b.o.Params.Get("traceLimit"); traceLimitString != "" {
+		if traceLimit, err = strconv.Atoi(traceLimitString); err != nil || traceLimit <= 0 {
+			graph.BadRequest(fmt.Sprintf("Invalid traceLimit, must be a positive integer: [%s]", traceLimitString))
+		}
+	}
+
+	end := time.Unix(b.o.QueryTime, 0)
+	query := models.TracingQuery{
+		Start: end.Add(-b.duration),
+		End:   end,
+		Limit: traceLimit,
+	}
+
+	for _, app := range apps {
+		r, err := layer.Jaeger.GetAppTraces(namespace, app, query)
+		graph.CheckError(err)
+		for i := range r.Data {
+			b.addTrace(&r.Data[i])
+		}
+	}
+}
+
+// addTrace adds the requests of a trace not yet processed
+func (b *trafficBuilder) addTrace(trace *jaegerModels.Trace) {
+	if b.traceIDs[trace.TraceID] {
+		return
+	}
+	b.traceIDs[trace.TraceID] = true
+
+	spans := make(map[jaegerModels.SpanID]*jaegerModels.Span, len(trace.Spans))
+	for i := range trace.Spans {
+		spans[trace.Spans[i].SpanID] = &trace.Spans[i]
+	}
+
+	for i := range trace.Spans {
+		span := &trace.Spans[i]
+		parent, ok := spans[parentSpanID(span)]
+		if !ok {
+			continue
+		}
+		sourceNode := getSpanNode(parent, trace.Processes[parent.ProcessID])
+		destNode := getSpanNode(span, trace.Processes[span.ProcessID])
+		if sourceNode == destNode {
+			continue
+		}
+		b.addRequest(parent, span, sourceNode, destNode)
+	}
+}
+
+// addRequest adds a single request from the parent (client) span's node to the span's node
+func (b *trafficBuilder) addRequest(parent, span *jaegerModels.Span, sourceNode, destNode spanNode) {
+	protocol, code := getProtocolAndCode(span)
+	if (protocol == "http" && b.o.Rates.Http != graph.RateRequests) || (protocol == "grpc" && b.o.Rates.Grpc != graph.RateRequests) {
+		return
+	}
+	flags, ok := getTag(span.Tags, "response_flags")
+	if !ok {
+		flags = "-"
+	}
+	host, destSvcNs, destSvc := getDestService(parent)
+
+	source := b.getNode(sourceNode.namespace, "", sourceNode)
+	dest := b.getNode(destNode.namespace, destSvc, destNode)
+	var service *graph.Node
+	if (b.o.InjectServiceNodes || b.o.GraphType == graph.GraphTypeService) && graph.IsOK(destSvc) {
+		service = b.getNode(destSvcNs, destSvc, spanNode{})
+	}
+	if !b.include(source, dest) && (service == nil || !b.include(service, service)) {
+		return
+	}
+
+	isErr := isError(span, protocol, code)
+	millis := float64(span.Duration) / 1000.0
+
+	if service != nil {
+		b.addEdgeTraffic(source, service, protocol, code, flags, host, millis, isErr)
+		b.addEdgeTraffic(service, dest, protocol, code, flags, host, millis, isErr)
+		addToDestServices(b.trafficMap[service.ID].Metadata, b.cluster, destSvcNs, destSvc)
+	} else {
+		b.addEdgeTraffic(source, dest, protocol, code, flags, host, millis, isErr)
+	}
+	addToDestServices(b.trafficMap[dest.ID].Metadata, b.cluster, destSvcNs, destSvc)
+}
+
+// getNode returns the traffic map node, or a new node not yet added to the traffic map
+func (b *trafficBuilder) getNode(serviceNs, service string, sn spanNode) *graph.Node {
+	if sn.workload == "" && b.o.GraphType != graph.GraphTypeApp && graph.IsOK(sn.app) {
+		// without pod information assume the workload is named for the app
+		sn.workload = sn.app
+	}
+	id, nodeType := graph.Id(b.cluster, serviceNs, service, sn.namespace, sn.workload, sn.app, sn.version, b.o.GraphType)
+	if node, found := b.trafficMap[id]; found {
+		return node
+	}
+	namespace := sn.namespace
+	if !graph.IsOK(namespace) {
+		namespace = serviceNs
+	}
+	node := graph.NewNodeExplicit(id, b.cluster, namespace, sn.workload, sn.app, sn.version, service, nodeType, b.o.GraphType)
+	return &node
+}
+
+func (b *trafficBuilder) addEdgeTraffic(source, dest *graph.Node, protocol, code, flags, host string, millis float64, isErr bool) {
+	// the nodes may already be in the traffic map, e.g. source and dest are the same node for a self-call
+	if node, found := b.trafficMap[source.ID]; found {
+		source = node
+	} else {
+		b.trafficMap[source.ID] = source
+	}
+	if node, found := b.trafficMap[dest.ID]; found {
+		dest = node
+	} else {
+		b.trafficMap[dest.ID] = dest
+	}
+
+	var edge *graph.Edge
+	for _, e := range source.Edges {
+		if dest.ID == e.Dest.ID && e.Metadata[graph.ProtocolKey] == protocol {
+			edge = e
+			break
+		}
+	}
+	if nil == edge {
+		edge = source.AddEdge(dest)
+		edge.Metadata[graph.ProtocolKey] = protocol
+		b.samples[edge] = &edgeSamples{}
+	}
+
+	// each traced request contributes its share of the per-second rate
+	graph.AddToMetadata(protocol, 1.0/b.duration.Seconds(), code, flags, host, source.Metadata, dest.Metadata, edge.Metadata)
+
+	samples := b.samples[edge]
+	samples.durations = append(samples.durations, millis)
+	if isErr {
+		samples.errors++
+	}
+}
+
+// finish sets the edge response times and trace stats, and returns the traffic map
+func (b *trafficBuilder) finish() graph.TrafficMap {
+	quantile := defaultQuantile
+	if responseTimeString := b.o.Params.Get("responseTime"); responseTimeString != "" {
+		switch responseTimeString {
+		case "avg":
+			quantile = 0.0
+		case "50":
+			quantile = 0.5
+		case "95":
+			quantile = 0.95
+		case "99":
+			quantile = 0.99
+		default:
+			graph.BadRequest(fmt.Sprintf(`Invalid responseTime, must be one of: avg | 50 | 95 | 99: [%s]`, responseTimeString))
+		}
+	}
+
+	for edge, samples := range b.samples {
+		stats := newTraceStats(samples)
+		edge.Metadata[TraceStatsKey] = stats
+		switch quantile {
+		case 0.0:
+			edge.Metadata[graph.ResponseTime] = stats.Avg
+		case 0.5:
+			edge.Metadata[graph.ResponseTime] = stats.P50
+		case 0.95:
+			edge.Metadata[graph.ResponseTime] = stats.P95
+		case 0.99:
+			edge.Metadata[graph.ResponseTime] = stats.P99
+		}
+	}
+
+	return b.trafficMap
+}
+
+func newTraceStats(samples *edgeSamples) TraceStats {
+	durations := append([]float64{}, samples.durations...)
+	sort.Float64s(durations)
+
+	sum := 0.0
+	for _, d := range durations {
+		sum += d
+	}
+
+	return TraceStats{
+		Errors:   samples.errors,
+		Requests: len(durations),
+		Avg:      sum / float64(len(durations)),
+		P50:      percentile(durations, 0.5),
+		P95:      percentile(durations, 0.95),
+		P99:      percentile(durations, 0.99),
+	}
+}
+
+// percentile returns the nearest-rank percentile of the sorted values
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func addToDestServices(md graph.Metadata, cluster, namespace, service string) {
+	if !graph.IsOK(service) {
+		return
+	}
+	destServices, ok := md[graph.DestServices]
+	if !ok {
+		destServices = graph.NewDestServicesMetadata()
+		md[graph.DestServices] = destServices
+	}
+	destService := graph.ServiceName{Cluster: cluster, Namespace: namespace, Name: service}
+	destServices.(graph.DestServicesMetadata)[destService.Key()] = destService
+}
+
+// parentSpanID returns the ID of the span's parent, or "" for a root span
+func parentSpanID(span *jaegerModels.Span) jaegerModels.SpanID {
+	for _, ref := range span.References {
+		if ref.RefType == jaegerModels.ChildOf && ref.TraceID == span.TraceID {
+			return ref.SpanID
+		}
+	}
+	return span.ParentSpanID
+}
+
+// getSpanNode returns the node reporting the span. Istio (Envoy) spans identify the node with tags,
+// otherwise the node is derived from the process.
+func getSpanNode(span *jaegerModels.Span, process jaegerModels.Process) spanNode {
+	sn := spanNode{namespace: graph.Unknown, app: graph.Unknown, version: graph.Unknown}
+
+	// For Envoy spans node_id is like: sidecar~172.17.0.20~reviews-v1-6d8996bff-ztg6z.bookinfo~bookinfo.svc.cluster.local
+	pod := ""
+	if nodeID, ok := getTag(span.Tags, "node_id"); ok {
+		if parts := strings.Split(nodeID, "~"); len(parts) >= 3 {
+			if i := strings.LastIndex(parts[2], "."); i > 0 {
+				pod = parts[2][:i]
+				sn.namespace = parts[2][i+1:]
+			}
+		}
+	}
+	if pod == "" {
+		pod, _ = getTag(process.Tags, "hostname")
+	}
+	sn.workload = podWorkload(pod)
+
+	// The process service name is like: reviews.bookinfo (or reviews, without the tracing namespace selector)
+	serviceName := strings.SplitN(process.ServiceName, ".", 2)
+	if serviceName[0] != "" {
+		sn.app = serviceName[0]
+	}
+	if len(serviceName) == 2 && sn.namespace == graph.Unknown {
+		sn.namespace = serviceName[1]
+	}
+
+	if namespace, ok := getTag(span.Tags, "istio.namespace"); ok {
+		sn.namespace = namespace
+	}
+	if app, ok := getTag(span.Tags, "istio.canonical_service"); ok {
+		sn.app = app
+	}
+	if version, ok := getTag(span.Tags, "istio.canonical_revision"); ok && version != "latest" {
+		sn.version = version
+	}
+
+	return sn
+}
+
+// podWorkload returns the workload name for a pod name like: reviews-v1-6d8996bff-ztg6z. The last two
+// segments are assumed to be generated by the workload's controller.
+func podWorkload(pod string) string {
+	parts := strings.Split(pod, "-")
+	if len(parts) < 3 {
+		return pod
+	}
+	return strings.Join(parts[:len(parts)-2], "-")
+}
+
+// getDestService returns the destination host and service of a client span. For Envoy spans upstream_cluster
+// is like: outbound|9080||reviews.bookinfo.svc.cluster.local
+func getDestService(span *jaegerModels.Span) (host, namespace, service string) {
+	upstreamCluster, ok := getTag(span.Tags, "upstream_cluster")
+	if !ok {
+		return "", "", ""
+	}
+	parts := strings.Split(upstreamCluster, "|")
+	if len(parts) != 4 || parts[0] != "outbound" {
+		return "", "", ""
+	}
+	host = parts[3]
+	hostParts := strings.Split(host, ".")
+	if len(hostParts) < 2 {
+		return host, "", ""
+	}
+	return host, hostParts[1], hostParts[0]
+}
+
+// getProtocolAndCode returns the request protocol and response code of a server span
+func getProtocolAndCode(span *jaegerModels.Span) (protocol, code string) {
+	if code, ok := getTag(span.Tags, "grpc.status_code"); ok {
+		return "grpc", code
+	}
+	if code, ok := getTag(span.Tags, "http.status_code"); ok {
+		return "http", code
+	}
+	if isErrorTag(span) {
+		return "http", "-"
+	}
+	return "http", "200"
+}
+
+func isError(span *jaegerModels.Span, protocol, code string) bool {
+	switch {
+	case code == "-":
+		return true
+	case protocol == "grpc":
+		return graph.IsGRPCErr(code)
+	case graph.IsHTTPErr(code):
+		return true
+	}
+	return isErrorTag(span)
+}
+
+func isErrorTag(span *jaegerModels.Span) bool {
+	val, ok := getTag(span.Tags, "error")
+	return ok && val == "true"
+}
+
+// getTag returns the string form of the tag value
+func getTag(tags []jaegerModels.KeyValue, key string) (string, bool) {
+	for _, tag := range tags {
+		if tag.Key == key {
+			return fmt.Sprintf("%v", tag.Value), true
+		}
+	}
+	return "", false
+}
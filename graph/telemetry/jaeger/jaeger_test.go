@@ -0,0 +1,276 @@
+package jaeger
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/jaeger"
+	"github.com/kiali/kiali/jaeger/jaegertest"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+)
+
+func tag(key string, value interface{}) jaegerModels.KeyValue {
+	return jaegerModels.KeyValue{Key: key, Value: value}
+}
+
+func childOf(traceID jaegerModels.TraceID, spanID jaegerModels.SpanID) []jaegerModels.Reference {
+	return []jaegerModels.Reference{{RefType: jaegerModels.ChildOf, TraceID: traceID, SpanID: spanID}}
+}
+
+// mockTrace returns the trace of a request: istio-ingressgateway -> productpage-v1 -> reviews-v1
+func mockTrace(traceID jaegerModels.TraceID, reviewsCode float64, reviewsDuration uint64) jaegerModels.Trace {
+	return jaegerModels.Trace{
+		TraceID: traceID,
+		Spans: []jaegerModels.Span{{
+			TraceID:   traceID,
+			SpanID:    "a",
+			ProcessID: "p1",
+			Duration:  10000,
+			Tags: []jaegerModels.KeyValue{
+				tag("node_id", "router~172.17.0.10~istio-ingressgateway-5d9f7b8c4-xyz12.istio-system~istio-system.svc.cluster.local"),
+				tag("upstream_cluster", "outbound|9080||productpage.bookinfo.svc.cluster.local"),
+				tag("http.status_code", float64(200)),
+			},
+		}, {
+			TraceID:    traceID,
+			SpanID:     "b",
+			ProcessID:  "p2",
+			References: childOf(traceID, "a"),
+			Duration:   9000,
+			Tags: []jaegerModels.KeyValue{
+				tag("node_id", "sidecar~172.17.0.20~productpage-v1-6b746f74dc-abcde.bookinfo~bookinfo.svc.cluster.local"),
+				tag("istio.canonical_revision", "v1"),
+				tag("upstream_cluster", "inbound|9080||"),
+				tag("http.status_code", float64(200)),
+			},
+		}, {
+			TraceID:    traceID,
+			SpanID:     "c",
+			ProcessID:  "p2",
+			References: childOf(traceID, "b"),
+			Duration:   reviewsDuration + 1000,
+			Tags: []jaegerModels.KeyValue{
+				tag("node_id", "sidecar~172.17.0.20~productpage-v1-6b746f74dc-abcde.bookinfo~bookinfo.svc.cluster.local"),
+				tag("istio.canonical_revision", "v1"),
+				tag("upstream_cluster", "outbound|9080||reviews.bookinfo.svc.cluster.local"),
+				tag("http.status_code", reviewsCode),
+			},
+		}, {
+			TraceID:    traceID,
+			SpanID:     "d",
+			ProcessID:  "p3",
+			References: childOf(traceID, "c"),
+			Duration:   reviewsDuration,
+			Tags: []jaegerModels.KeyValue{
+				tag("node_id", "sidecar~172.17.0.30~reviews-v1-7f99cc4496-fghij.bookinfo~bookinfo.svc.cluster.local"),
+				tag("istio.canonical_revision", "v1"),
+				tag("upstream_cluster", "inbound|9080||"),
+				tag("http.status_code", reviewsCode),
+			},
+		}},
+		Processes: map[jaegerModels.ProcessID]jaegerModels.Process{
+			"p1": {ServiceName: "istio-ingressgateway"},
+			"p2": {ServiceName: "productpage.bookinfo"},
+			"p3": {ServiceName: "reviews.bookinfo"},
+		},
+	}
+}
+
+func setupMocked() *graph.AppenderGlobalInfo {
+	conf := config.NewConfig()
+	conf.ExternalServices.Tracing.NamespaceSelector = true
+	config.Set(conf)
+
+	trace1 := mockTrace("t1", 503, 4000)
+	trace2 := mockTrace("t2", 200, 2000)
+
+	j := new(jaegertest.JaegerClientMock)
+	j.On("GetServices").Return(&jaeger.JaegerServices{Data: []string{"istio-ingressgateway", "productpage.bookinfo", "reviews.bookinfo", "ratings.other"}}, nil)
+	j.On("GetAppTraces", "bookinfo", "productpage", mock.AnythingOfType("models.TracingQuery")).Return(&jaeger.JaegerResponse{Data: []jaegerModels.Trace{trace1, trace2}}, nil)
+	j.On("GetAppTraces", "bookinfo", "reviews", mock.AnythingOfType("models.TracingQuery")).Return(&jaeger.JaegerResponse{Data: []jaegerModels.Trace{trace1}}, nil)
+
+	loader := func() (jaeger.ClientInterface, error) {
+		return j, nil
+	}
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("IsOpenShift").Return(false)
+
+	globalInfo := graph.NewAppenderGlobalInfo()
+	globalInfo.Business = business.NewWithBackends(k8s, nil, loader)
+	globalInfo.HomeCluster = "east"
+	return globalInfo
+}
+
+func mockOptions(graphType string, injectServiceNodes bool) graph.TelemetryOptions {
+	o := graph.TelemetryOptions{
+		Appenders:          graph.RequestedAppenders{All: false, AppenderNames: []string{}},
+		InjectServiceNodes: injectServiceNodes,
+		Namespaces:         graph.NamespaceInfoMap{"bookinfo": graph.NamespaceInfo{Name: "bookinfo", Duration: time.Minute}},
+		Rates:              graph.RequestedRates{Grpc: graph.RateRequests, Http: graph.RateRequests, Tcp: graph.RateSent},
+	}
+	o.GraphType = graphType
+	o.Params = url.Values{}
+	o.QueryTime = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Unix()
+	return o
+}
+
+func TestNamespacesGraph(t *testing.T) {
+	assert := assert.New(t)
+
+	trafficMap := BuildNamespacesTrafficMap(mockOptions(graph.GraphTypeWorkload, false), setupMocked())
+	assert.Equal(3, len(trafficMap))
+
+	ingress, ok := trafficMap["wl_east_istio-system_istio-ingressgateway"]
+	assert.True(ok)
+	assert.Equal(true, ingress.Metadata[graph.IsOutside])
+	assert.Equal(true, ingress.Metadata[graph.IsRoot])
+	assert.Equal(1, len(ingress.Edges))
+
+	productpage, ok := trafficMap["wl_east_bookinfo_productpage-v1"]
+	assert.True(ok)
+	assert.Equal("productpage", productpage.App)
+	assert.Equal("v1", productpage.Version)
+	assert.Equal(productpage, ingress.Edges[0].Dest)
+	assert.Equal(2.0/60.0, ingress.Edges[0].Metadata["http"])
+	assert.Equal(1, len(productpage.Edges))
+
+	reviewsEdge := productpage.Edges[0]
+	assert.Equal("wl_east_bookinfo_reviews-v1", reviewsEdge.Dest.ID)
+	assert.Equal("http", reviewsEdge.Metadata[graph.ProtocolKey])
+	assert.Equal(2.0/60.0, reviewsEdge.Metadata["http"])
+	assert.Equal(1.0/60.0, reviewsEdge.Metadata["http5xx"])
+	assert.Equal(TraceStats{Errors: 1, Requests: 2, Avg: 3.0, P50: 2.0, P95: 4.0, P99: 4.0}, reviewsEdge.Metadata[TraceStatsKey])
+	assert.Equal(4.0, reviewsEdge.Metadata[graph.ResponseTime])
+
+	destServices := reviewsEdge.Dest.Metadata[graph.DestServices].(graph.DestServicesMetadata)
+	reviewsService := graph.ServiceName{Cluster: "east", Namespace: "bookinfo", Name: "reviews"}
+	_, ok = destServices[reviewsService.Key()]
+	assert.True(ok)
+
+	// the traffic map is compatible with the cytoscape config vendor
+	config := cytoscape.NewConfig(trafficMap, graph.ConfigOptions{})
+	assert.Equal(3, len(config.Elements.Nodes))
+	assert.Equal(2, len(config.Elements.Edges))
+}
+
+func TestNamespacesGraphWithServiceInjection(t *testing.T) {
+	assert := assert.New(t)
+
+	o := mockOptions(graph.GraphTypeVersionedApp, true)
+	o.Params.Set("responseTime", "avg")
+	trafficMap := BuildNamespacesTrafficMap(o, setupMocked())
+	assert.Equal(5, len(trafficMap))
+
+	reviewsService, ok := trafficMap["svc_east_bookinfo_reviews"]
+	assert.True(ok)
+	assert.Equal(graph.NodeTypeService, reviewsService.NodeType)
+	assert.Equal(1, len(reviewsService.Edges))
+	assert.Equal("vapp_east_bookinfo_reviews-v1", reviewsService.Edges[0].Dest.ID)
+	assert.Equal(3.0, reviewsService.Edges[0].Metadata[graph.ResponseTime])
+
+	productpage := trafficMap["vapp_east_bookinfo_productpage-v1"]
+	assert.Equal(1, len(productpage.Edges))
+	assert.Equal(reviewsService, productpage.Edges[0].Dest)
+}
+
+func TestNodeGraph(t *testing.T) {
+	assert := assert.New(t)
+
+	o := mockOptions(graph.GraphTypeApp, false)
+	o.NodeOptions = graph.NodeOptions{App: "reviews", Namespace: "bookinfo"}
+	trafficMap := BuildNodeTrafficMap(o, setupMocked())
+	assert.Equal(2, len(trafficMap))
+
+	productpage, ok := trafficMap["app_east_bookinfo_productpage"]
+	assert.True(ok)
+	assert.Equal(1, len(productpage.Edges))
+	assert.Equal("app_east_bookinfo_reviews", productpage.Edges[0].Dest.ID)
+}
+
+func TestSelfCallGraph(t *testing.T) {
+	assert := assert.New(t)
+
+	// reviews-v1 -> reviews-v2, a self-call in the app graph
+	trace := jaegerModels.Trace{
+		TraceID: "t1",
+		Spans: []jaegerModels.Span{{
+			TraceID:   "t1",
+			SpanID:    "a",
+			ProcessID: "p1",
+			Duration:  2000,
+			Tags: []jaegerModels.KeyValue{
+				tag("node_id", "sidecar~172.17.0.30~reviews-v1-7f99cc4496-fghij.bookinfo~bookinfo.svc.cluster.local"),
+				tag("istio.canonical_revision", "v1"),
+				tag("upstream_cluster", "outbound|9080||reviews.bookinfo.svc.cluster.local"),
+				tag("http.status_code", float64(200)),
+			},
+		}, {
+			TraceID:    "t1",
+			SpanID:     "b",
+			ProcessID:  "p2",
+			References: childOf("t1", "a"),
+			Duration:   1000,
+			Tags: []jaegerModels.KeyValue{
+				tag("node_id", "sidecar~172.17.0.31~reviews-v2-5b64b9c6d8-klmno.bookinfo~bookinfo.svc.cluster.local"),
+				tag("istio.canonical_revision", "v2"),
+				tag("upstream_cluster", "inbound|9080||"),
+				tag("http.status_code", float64(200)),
+			},
+		}},
+		Processes: map[jaegerModels.ProcessID]jaegerModels.Process{
+			"p1": {ServiceName: "reviews.bookinfo"},
+			"p2": {ServiceName: "reviews.bookinfo"},
+		},
+	}
+
+	globalInfo := setupMocked()
+	j := new(jaegertest.JaegerClientMock)
+	j.On("GetServices").Return(&jaeger.JaegerServices{Data: []string{"reviews.bookinfo"}}, nil)
+	j.On("GetAppTraces", "bookinfo", "reviews", mock.AnythingOfType("models.TracingQuery")).Return(&jaeger.JaegerResponse{Data: []jaegerModels.Trace{trace}}, nil)
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("IsOpenShift").Return(false)
+	globalInfo.Business = business.NewWithBackends(k8s, nil, func() (jaeger.ClientInterface, error) { return j, nil })
+
+	trafficMap := BuildNamespacesTrafficMap(mockOptions(graph.GraphTypeApp, false), globalInfo)
+	assert.Equal(1, len(trafficMap))
+
+	reviews, ok := trafficMap["app_east_bookinfo_reviews"]
+	assert.True(ok)
+	assert.Equal(1, len(reviews.Edges))
+	assert.Equal(reviews, reviews.Edges[0].Dest)
+	assert.Equal(1.0/60.0, reviews.Edges[0].Metadata["http"])
+	_, ok = reviews.Metadata[graph.DestServices]
+	assert.True(ok)
+}
+
+func TestInvalidTraceLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	o := mockOptions(graph.GraphTypeWorkload, false)
+	o.Params.Set("traceLimit", "none")
+	assert.Panics(func() {
+		BuildNamespacesTrafficMap(o, setupMocked())
+	})
+}
+
+func TestPercentile(t *testing.T) {
+	assert := assert.New(t)
+
+	samples := &edgeSamples{durations: []float64{5, 1, 4, 2, 3, 6, 7, 8, 9, 10}}
+	stats := newTraceStats(samples)
+	assert.Equal(10, stats.Requests)
+	assert.Equal(5.5, stats.Avg)
+	assert.Equal(5.0, stats.P50)
+	assert.Equal(10.0, stats.P95)
+	assert.Equal(10.0, stats.P99)
+}
@@ -0,0 +1,46 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/prometheus"
+)
+
+type mockTelemetryVendor struct {
+	name string
+}
+
+func (v mockTelemetryVendor) Name() string {
+	return v.name
+}
+
+func (v mockTelemetryVendor) Appenders() []string {
+	return []string{"mockAppender"}
+}
+
+func (v mockTelemetryVendor) BuildNamespacesTrafficMap(o TelemetryOptions, client *prometheus.Client, globalInfo *AppenderGlobalInfo) TrafficMap {
+	return NewTrafficMap()
+}
+
+func (v mockTelemetryVendor) BuildNodeTrafficMap(o TelemetryOptions, client *prometheus.Client, globalInfo *AppenderGlobalInfo) TrafficMap {
+	return NewTrafficMap()
+}
+
+func TestRegisterTelemetryVendor(t *testing.T) {
+	assert := assert.New(t)
+
+	_, found := GetTelemetryVendor("mockVendor")
+	assert.False(found)
+
+	RegisterTelemetryVendor(mockTelemetryVendor{name: "mockVendor"})
+	vendor, found := GetTelemetryVendor("mockVendor")
+	assert.True(found)
+	assert.Equal([]string{"mockAppender"}, vendor.Appenders())
+	assert.Contains(TelemetryVendorNames(), "mockVendor")
+
+	assert.Panics(func() {
+		RegisterTelemetryVendor(mockTelemetryVendor{name: "mockVendor"})
+	})
+}
@@ -14,25 +14,48 @@ package handlers
 //
 // The current Handlers:
 //   GraphNamespaces: Generate a graph for one or more requested namespaces.
+//   GraphNamespacesDiff: Generate a namespaces graph comparing two time windows (see baseQueryTime below).
+//   GraphNamespacesMatrix: Reduce a namespaces graph to the traffic between each pair of namespaces (see format below).
+//   GraphNamespacesPath: Find and rank the paths between two nodes of a namespaces graph (see source below).
+//   GraphNamespacesStream: Stream a namespaces graph, pushing deltas on a refresh interval (see refreshInterval below).
+//   GraphSnapshotSave: Generate a namespaces graph and save it as a snapshot (see name below).
+//   GraphSnapshots: List the saved graph snapshots.
+//   GraphSnapshot: Replay a saved graph snapshot.
 //   GraphNode:       Generate a graph for a specific node, detailing the immediate incoming and outgoing traffic.
 //
 // The handlers accept the following query parameters (see notes below)
 //   appenders:       Comma-separated list of TelemetryVendor-specific appenders to run. (default: all)
-//   configVendor:    default: cytoscape
+//   baseDuration:    Diff only, duration for the base time window (default: duration)
+//   baseQueryTime:   Diff only, required, Unix time (seconds) for the base time window
+//   configVendor:    cytoscape | dot | graphml | jgf (default: cytoscape)
+//   dest:            Path only, required, TrafficMap ID of the path destination node
 //   duration:        time.Duration indicating desired query range duration, (default: 10m)
+//   format:          Matrix only, json | csv (default: json)
 //   graphType:       Determines how to present the telemetry data. app | service | versionedApp | workload (default: workload)
-//   boxBy:           If supported by vendor, visually box by a specified node attribute (default: none)
+//   hide:            Find/hide expression for the nodes and edges to remove, e.g. "ns = foo || http < 1" (default: none)
+//   includeRoutes:   Break down request traffic by route (default: false)
+//   limit:           Path only, the maximum number of paths returned (default: 10)
+//   maxNodes:        Collapse low-traffic leaf nodes into an aggregate node per namespace, to limit the number of nodes (default: 0, no limit)
+//   boxBy:           If supported by vendor, visually box by specified node attributes: app | cluster | label:<key> | namespace (default: none)
+//   name:            Snapshot only, optional name of the saved snapshot
 //   namespaces:      Comma-separated list of namespace names to use in the graph. Will override namespace path param
 //   queryTime:       Unix time (seconds) for query such that range is queryTime-duration..queryTime (default now)
-//   TelemetryVendor: default: istio
+//   rankBy:          Path only, errorRate | responseTime (default: responseTime)
+//   refreshInterval: Stream only, time.Duration indicating how often the graph is regenerated (default: 5s, minimum: 5s, maximum: 10s)
+//   source:          Path only, required, TrafficMap ID of the path source node
+//   telemetryVendor: istio | jaeger, or any other registered TelemetryVendor (default: istio)
 //
 //  Note: some handlers may ignore some query parameters.
 //  Note: vendors may support additional, vendor-specific query parameters.
 //
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"runtime/debug"
+	"time"
+
+	"github.com/gorilla/mux"
 
 	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/graph/api"
@@ -52,6 +75,136 @@ func GraphNamespaces(w http.ResponseWriter, r *http.Request) {
 	respond(w, code, payload)
 }
 
+// GraphNamespacesDiff is a REST http.HandlerFunc handling diff graph generation for 1 or more namespaces
+func GraphNamespacesDiff(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	o := graph.NewDiffOptions(r)
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	code, payload := api.GraphNamespacesDiff(business, o)
+	respond(w, code, payload)
+}
+
+// GraphNamespacesPath is a REST http.HandlerFunc handling path analysis between two nodes of a namespaces graph
+func GraphNamespacesPath(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	o := graph.NewPathOptions(r)
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	code, payload := api.GraphNamespacesPath(business, o)
+	respond(w, code, payload)
+}
+
+// GraphNamespacesMatrix is a REST http.HandlerFunc reducing a namespaces graph to a namespace dependency matrix
+func GraphNamespacesMatrix(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	o := graph.NewMatrixOptions(r)
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	code, payload := api.GraphNamespacesMatrix(business, o)
+	respond(w, code, payload)
+}
+
+// streamMaxLifetime keeps a graph stream connection within the server's write timeout. SSE clients
+// transparently reconnect, and are immediately sent the most recent graph of the shared stream. The
+// maximum refreshInterval is set so that a connection receives at least two deltas.
+const streamMaxLifetime = 25 * time.Second
+
+// GraphNamespacesStream is a REST http.HandlerFunc streaming graph updates, as Server-Sent Events, for 1 or
+// more namespaces. The first event provides the full graph, subsequent events provide deltas.
+func GraphNamespacesStream(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	o := graph.NewStreamOptions(r)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		graph.Error("Graph streaming is not supported by the response writer")
+	}
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	subscription := api.SubscribeGraphStream(business, o)
+	defer api.UnsubscribeGraphStream(subscription)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintf(w, "retry: %d\n\n", time.Second.Milliseconds())
+	flusher.Flush()
+
+	lifetime := time.NewTimer(streamMaxLifetime)
+	defer lifetime.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-lifetime.C:
+			return
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				log.Errorf("Failed to marshal graph stream event: %v", err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// GraphSnapshotSave is a REST http.HandlerFunc generating a namespaces graph and saving it as a snapshot
+func GraphSnapshotSave(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	o := graph.NewSnapshotOptions(r)
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	code, payload := api.SaveGraphSnapshot(business, o)
+	respond(w, code, payload)
+}
+
+// GraphSnapshots is a REST http.HandlerFunc listing the saved graph snapshots
+func GraphSnapshots(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	code, payload := api.ListGraphSnapshots(business)
+	respond(w, code, payload)
+}
+
+// GraphSnapshot is a REST http.HandlerFunc replaying a saved graph snapshot
+func GraphSnapshot(w http.ResponseWriter, r *http.Request) {
+	defer handlePanic(w)
+
+	business, err := getBusiness(r)
+	graph.CheckError(err)
+
+	code, payload := api.GetGraphSnapshot(business, mux.Vars(r)["snapshot"])
+	respond(w, code, payload)
+}
+
 // GraphNode is a REST http.HandlerFunc handling node-detail graph config generation.
 func GraphNode(w http.ResponseWriter, r *http.Request) {
 	defer handlePanic(w)
@@ -94,8 +247,25 @@ func handlePanic(w http.ResponseWriter) {
 
 func respond(w http.ResponseWriter, code int, payload interface{}) {
 	if code == http.StatusOK {
+		if rawConfig, ok := payload.(graph.RawConfig); ok {
+			respondRaw(w, rawConfig)
+			return
+		}
 		RespondWithJSONIndent(w, code, payload)
 		return
 	}
 	RespondWithError(w, code, payload.(string))
 }
+
+// respondRaw writes a graph config that is not rendered as JSON (e.g. GraphML or DOT)
+func respondRaw(w http.ResponseWriter, rawConfig graph.RawConfig) {
+	content, err := rawConfig.Marshal()
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", rawConfig.ContentType())
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
@@ -194,6 +194,18 @@ func IstioConfigUpdate(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusBadRequest, "Update request with bad update patch: "+err.Error())
 	}
 	jsonPatch := string(body)
+
+	// On a dry run, the validations the update would produce are returned, nothing is updated
+	if r.URL.Query().Get("dryRun") == "true" {
+		patchedConfigDetails, err := business.IstioConfig.GetPatchedIstioConfigDetail(namespace, objectType, object, jsonPatch)
+		if err != nil {
+			handleErrorResponse(w, err)
+			return
+		}
+		respondWithProposedValidations(w, business, patchedConfigDetails)
+		return
+	}
+
 	updatedConfigDetails, err := business.IstioConfig.UpdateIstioConfigDetail(namespace, objectType, object, jsonPatch)
 
 	if err != nil {
@@ -228,6 +240,17 @@ func IstioConfigCreate(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusBadRequest, "Create request could not be read: "+err.Error())
 	}
 
+	// On a dry run, the validations the creation would produce are returned, nothing is created
+	if r.URL.Query().Get("dryRun") == "true" {
+		proposedConfigDetails, err := business.IstioConfig.GetProposedIstioConfigDetail(namespace, objectType, body)
+		if err != nil {
+			handleErrorResponse(w, err)
+			return
+		}
+		respondWithProposedValidations(w, business, proposedConfigDetails)
+		return
+	}
+
 	createdConfigDetails, err := business.IstioConfig.CreateIstioConfigDetail(namespace, objectType, body)
 	if err != nil {
 		handleErrorResponse(w, err)
@@ -238,6 +261,16 @@ func IstioConfigCreate(w http.ResponseWriter, r *http.Request) {
 	RespondWithJSON(w, http.StatusOK, createdConfigDetails)
 }
 
+// respondWithProposedValidations responds with the namespace validations as they would be with the proposed object
+func respondWithProposedValidations(w http.ResponseWriter, business *business.Layer, proposed models.IstioConfigDetails) {
+	validations, err := business.Validations.GetProposedValidations(proposed)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, validations)
+}
+
 func checkObjectType(objectType string) bool {
 	return business.GetIstioAPI(objectType)
 }
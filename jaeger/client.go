@@ -33,6 +33,7 @@ type ClientInterface interface {
 	GetTraceDetail(traceId string) (*JaegerSingleTrace, error)
 	GetErrorTraces(ns, app string, duration time.Duration) (errorTraces int, err error)
 	GetServiceStatus() (available bool, err error)
+	GetServices() (services *JaegerServices, err error)
 }
 
 // Client for Jaeger API.
@@ -220,6 +221,24 @@ func (in *Client) GetServiceStatus() (bool, error) {
 	return err == nil, err
 }
 
+// GetServices fetches the names of the services reporting traces
+func (in *Client) GetServices() (*JaegerServices, error) {
+	if in.grpcClient == nil {
+		return getServicesHTTP(in.httpClient, in.baseURL)
+	}
+
+	ctx, cancel := context.WithTimeout(in.ctx, 4*time.Second)
+	defer cancel()
+
+	res, err := in.grpcClient.GetServices(ctx, &jaegerModel.GetServicesRequest{})
+	if err != nil {
+		err = fmt.Errorf("GetServices, Jaeger GRPC client error: %v", err)
+		log.Error(err.Error())
+		return nil, err
+	}
+	return &JaegerServices{Data: res.Services}, nil
+}
+
 type SpansStreamer interface {
 	Recv() (*jaegerModel.SpansResponseChunk, error)
 	grpc.ClientStream
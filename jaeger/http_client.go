@@ -60,6 +60,22 @@ func getServiceStatusHTTP(client http.Client, baseURL *url.URL) (bool, error) {
 	return reqError == nil, reqError
 }
 
+func getServicesHTTP(client http.Client, baseURL *url.URL) (*JaegerServices, error) {
+	url := *baseURL
+	url.Path = path.Join(url.Path, "/api/services")
+	resp, code, reqError := makeRequest(client, url.String(), nil)
+	if reqError != nil {
+		log.Errorf("Jaeger query error: %s [code: %d, URL: %v]", reqError, code, url)
+		return nil, reqError
+	}
+	var response JaegerServices
+	if errMarshal := json.Unmarshal(resp, &response); errMarshal != nil {
+		log.Errorf("Error unmarshalling Jaeger response: %s [URL: %v]", errMarshal, url)
+		return nil, errMarshal
+	}
+	return &response, nil
+}
+
 func queryTracesHTTP(client http.Client, u *url.URL) (*JaegerResponse, error) {
 	// HTTP and GRPC requests co-exist, but when minDuration is present, for HTTP it requires a unit (ms)
 	// https://github.com/kiali/kiali/issues/3939
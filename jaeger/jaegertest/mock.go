@@ -32,3 +32,8 @@ func (j *JaegerClientMock) GetServiceStatus() (available bool, err error) {
 	args := j.Called()
 	return args.Get(0).(bool), args.Error(1)
 }
+
+func (j *JaegerClientMock) GetServices() (services *jaeger.JaegerServices, err error) {
+	args := j.Called()
+	return args.Get(0).(*jaeger.JaegerServices), args.Error(1)
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
 	"github.com/kiali/kiali/prometheus/internalmetrics"
 	"github.com/kiali/kiali/server"
 	"github.com/kiali/kiali/status"
@@ -168,6 +169,11 @@ func validateConfig() error {
 		log.Warningf("Some validation errors will be ignored %v. If these errors do occur, they will still be logged. If you think the validation errors you see are incorrect, please report them to the Kiali team if you have not done so already and provide the details of your scenario. This will keep Kiali validations strong for the whole community.", cfg.KialiFeatureFlags.Validations.Ignore)
 	}
 
+	// Check the validation rules
+	if err := models.ValidateValidationRules(cfg.KialiFeatureFlags.Validations.Rules); err != nil {
+		return err
+	}
+
 	return nil
 }
 
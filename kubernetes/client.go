@@ -40,6 +40,7 @@ type ClientInterface interface {
 	GetToken() string
 	GetAuthInfo() *api.AuthInfo
 	IsOpenShift() bool
+	GatewayAPIClientInterface
 	K8SClientInterface
 	IstioClientInterface
 	Iter8ClientInterface
@@ -65,6 +66,11 @@ type K8SClient struct {
 	// It is represented as a pointer to include the initialization phase.
 	// See iter8.go#IsIter8Api() for more details
 	isIter8Api *bool
+
+	// isGatewayAPI private variable will check if the Kubernetes Gateway API is present.
+	// It is represented as a pointer to include the initialization phase.
+	// See gateway_api.go#IsGatewayAPI() for more details
+	isGatewayAPI *bool
 }
 
 // GetK8sApi returns the clientset referencing all K8s rest clients
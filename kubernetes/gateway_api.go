@@ -0,0 +1,158 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The Kubernetes Gateway API is not a dependency of Kiali, its resources are read through the Kubernetes
+// REST client. Only the fields used by Kiali are defined.
+// Linked with https://github.com/kubernetes-sigs/gateway-api/tree/master/apis/v1alpha2
+
+var (
+	K8sGatewayAPIGroupVersion = schema.GroupVersion{
+		Group:   "gateway.networking.k8s.io",
+		Version: "v1alpha2",
+	}
+	ApiK8sGatewayAPIVersion = K8sGatewayAPIGroupVersion.Group + "/" + K8sGatewayAPIGroupVersion.Version
+)
+
+const (
+	K8sGatewayType   = "Gateway"
+	K8sGateways      = "gateways"
+	K8sHTTPRouteType = "HTTPRoute"
+	K8sHTTPRoutes    = "httproutes"
+
+	// K8sGatewayNameLabel is set by Istio on the deployments it generates for a Gateway API Gateway
+	K8sGatewayNameLabel = "istio.io/gateway-name"
+	// K8sGatewayClassWaypoint is the class of the Gateway API Gateways deployed as Istio ambient waypoint proxies
+	K8sGatewayClassWaypoint = "istio-waypoint"
+)
+
+// K8sGateway is a Gateway API Gateway
+type K8sGateway struct {
+	meta_v1.TypeMeta   `json:",inline"`
+	meta_v1.ObjectMeta `json:"metadata,omitempty"`
+	Spec               K8sGatewaySpec `json:"spec"`
+}
+
+type K8sGatewaySpec struct {
+	GatewayClassName string               `json:"gatewayClassName"`
+	Listeners        []K8sGatewayListener `json:"listeners"`
+	Addresses        []K8sGatewayAddress  `json:"addresses,omitempty"`
+}
+
+type K8sGatewayListener struct {
+	Name     string  `json:"name"`
+	Hostname *string `json:"hostname,omitempty"`
+	Port     int32   `json:"port"`
+	Protocol string  `json:"protocol"`
+}
+
+type K8sGatewayAddress struct {
+	Type  *string `json:"type,omitempty"`
+	Value string  `json:"value"`
+}
+
+type K8sGatewayList struct {
+	meta_v1.TypeMeta `json:",inline"`
+	meta_v1.ListMeta `json:"metadata,omitempty"`
+	Items            []K8sGateway `json:"items"`
+}
+
+// K8sHTTPRoute is a Gateway API HTTPRoute
+type K8sHTTPRoute struct {
+	meta_v1.TypeMeta   `json:",inline"`
+	meta_v1.ObjectMeta `json:"metadata,omitempty"`
+	Spec               K8sHTTPRouteSpec `json:"spec"`
+}
+
+type K8sHTTPRouteSpec struct {
+	ParentRefs []K8sParentReference `json:"parentRefs,omitempty"`
+	Hostnames  []string             `json:"hostnames,omitempty"`
+}
+
+// K8sParentReference identifies the resource (usually a Gateway) a route attaches to
+type K8sParentReference struct {
+	Group       *string `json:"group,omitempty"`
+	Kind        *string `json:"kind,omitempty"`
+	Namespace   *string `json:"namespace,omitempty"`
+	Name        string  `json:"name"`
+	SectionName *string `json:"sectionName,omitempty"`
+}
+
+type K8sHTTPRouteList struct {
+	meta_v1.TypeMeta `json:",inline"`
+	meta_v1.ListMeta `json:"metadata,omitempty"`
+	Items            []K8sHTTPRoute `json:"items"`
+}
+
+// IsParent returns true if the route attaches to the Gateway
+func (in K8sHTTPRoute) IsParent(gateway K8sGateway) bool {
+	for _, ref := range in.Spec.ParentRefs {
+		if ref.Group != nil && *ref.Group != K8sGatewayAPIGroupVersion.Group {
+			continue
+		}
+		if ref.Kind != nil && *ref.Kind != K8sGatewayType {
+			continue
+		}
+		namespace := in.Namespace
+		if ref.Namespace != nil {
+			namespace = *ref.Namespace
+		}
+		if ref.Name == gateway.Name && namespace == gateway.Namespace {
+			return true
+		}
+	}
+	return false
+}
+
+type GatewayAPIClientInterface interface {
+	GetK8sGateways(namespace string) ([]K8sGateway, error)
+	GetK8sHTTPRoutes(namespace string) ([]K8sHTTPRoute, error)
+	IsGatewayAPI() bool
+}
+
+func (in *K8SClient) IsGatewayAPI() bool {
+	if in.isGatewayAPI == nil {
+		isGatewayAPI := false
+		_, err := in.k8s.RESTClient().Get().AbsPath("/apis/" + ApiK8sGatewayAPIVersion).Do(in.ctx).Raw()
+		if err == nil {
+			isGatewayAPI = true
+		}
+		in.isGatewayAPI = &isGatewayAPI
+	}
+	return *in.isGatewayAPI
+}
+
+func (in *K8SClient) GetK8sGateways(namespace string) ([]K8sGateway, error) {
+	result, err := in.getK8sGatewayAPIResources(namespace, K8sGateways)
+	if err != nil {
+		return nil, err
+	}
+	gatewayList := K8sGatewayList{}
+	if err := json.Unmarshal(result, &gatewayList); err != nil {
+		return nil, fmt.Errorf("%s doesn't return a Gateway API Gateway list: %v", namespace, err)
+	}
+	return gatewayList.Items, nil
+}
+
+func (in *K8SClient) GetK8sHTTPRoutes(namespace string) ([]K8sHTTPRoute, error) {
+	result, err := in.getK8sGatewayAPIResources(namespace, K8sHTTPRoutes)
+	if err != nil {
+		return nil, err
+	}
+	routeList := K8sHTTPRouteList{}
+	if err := json.Unmarshal(result, &routeList); err != nil {
+		return nil, fmt.Errorf("%s doesn't return a Gateway API HTTPRoute list: %v", namespace, err)
+	}
+	return routeList.Items, nil
+}
+
+func (in *K8SClient) getK8sGatewayAPIResources(namespace, resourceType string) ([]byte, error) {
+	path := fmt.Sprintf("/apis/%s/namespaces/%s/%s", ApiK8sGatewayAPIVersion, namespace, resourceType)
+	return in.k8s.RESTClient().Get().AbsPath(path).Do(in.ctx).Raw()
+}
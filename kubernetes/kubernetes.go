@@ -29,9 +29,12 @@ import (
 )
 
 type K8SClientInterface interface {
+	CreateConfigMap(namespace string, configMap *core_v1.ConfigMap) (*core_v1.ConfigMap, error)
+	DeleteConfigMap(namespace, name string) error
 	ForwardGetRequest(namespace, podName string, localPort, destinationPort int, path string) ([]byte, error)
 	GetClusterServicesByLabels(labelsSelector string) ([]core_v1.Service, error)
 	GetConfigMap(namespace, name string) (*core_v1.ConfigMap, error)
+	GetConfigMaps(namespace, labelSelector string) ([]core_v1.ConfigMap, error)
 	GetCronJobs(namespace string) ([]batch_v1beta1.CronJob, error)
 	GetDaemonSet(namespace string, name string) (*apps_v1.DaemonSet, error)
 	GetDaemonSets(namespace string) ([]apps_v1.DaemonSet, error)
@@ -117,6 +120,30 @@ func (in *K8SClient) GetConfigMap(namespace, name string) (*core_v1.ConfigMap, e
 	return configMap, nil
 }
 
+// GetConfigMaps fetches and returns the ConfigMaps of a namespace matching the optional labelSelector
+func (in *K8SClient) GetConfigMaps(namespace, labelSelector string) ([]core_v1.ConfigMap, error) {
+	listOptions := emptyListOptions
+	if len(labelSelector) > 0 {
+		listOptions = meta_v1.ListOptions{LabelSelector: labelSelector}
+	}
+
+	if configMapList, err := in.k8s.CoreV1().ConfigMaps(namespace).List(in.ctx, listOptions); err == nil {
+		return configMapList.Items, nil
+	} else {
+		return []core_v1.ConfigMap{}, err
+	}
+}
+
+// CreateConfigMap creates the ConfigMap in the namespace, returning the created definition
+func (in *K8SClient) CreateConfigMap(namespace string, configMap *core_v1.ConfigMap) (*core_v1.ConfigMap, error) {
+	return in.k8s.CoreV1().ConfigMaps(namespace).Create(in.ctx, configMap, meta_v1.CreateOptions{})
+}
+
+// DeleteConfigMap deletes the specified ConfigMap
+func (in *K8SClient) DeleteConfigMap(namespace, name string) error {
+	return in.k8s.CoreV1().ConfigMaps(namespace).Delete(in.ctx, name, meta_v1.DeleteOptions{})
+}
+
 // GetNamespace fetches and returns the specified namespace definition
 // from the cluster
 func (in *K8SClient) GetNamespace(namespace string) (*core_v1.Namespace, error) {
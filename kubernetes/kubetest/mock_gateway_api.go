@@ -0,0 +1,18 @@
+package kubetest
+
+import "github.com/kiali/kiali/kubernetes"
+
+func (o *K8SClientMock) GetK8sGateways(namespace string) ([]kubernetes.K8sGateway, error) {
+	args := o.Called(namespace)
+	return args.Get(0).([]kubernetes.K8sGateway), args.Error(1)
+}
+
+func (o *K8SClientMock) GetK8sHTTPRoutes(namespace string) ([]kubernetes.K8sHTTPRoute, error) {
+	args := o.Called(namespace)
+	return args.Get(0).([]kubernetes.K8sHTTPRoute), args.Error(1)
+}
+
+func (o *K8SClientMock) IsGatewayAPI() bool {
+	args := o.Called()
+	return args.Get(0).(bool)
+}
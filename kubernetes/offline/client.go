@@ -0,0 +1,238 @@
+package offline
+
+import (
+	"context"
+
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	istio "istio.io/client-go/pkg/clientset/versioned"
+	istio_fake "istio.io/client-go/pkg/clientset/versioned/fake"
+	apps_v1 "k8s.io/api/apps/v1"
+	batch_v1 "k8s.io/api/batch/v1"
+	batch_v1beta1 "k8s.io/api/batch/v1beta1"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/log"
+)
+
+// Client is a kubernetes.ClientInterface serving the loaded Objects, to run the Kiali validations on YAML files
+// without a cluster. Only the methods used by the validations are implemented, any other method panics.
+// The Istio objects are served by a fake Istio clientset.
+type Client struct {
+	kubernetes.ClientInterface
+	istioClientset *istio_fake.Clientset
+	namespaces     []core_v1.Namespace
+	objects        *Objects
+}
+
+// NewClient returns a Client serving the objects. The namespaces of the objects, and the Istio namespace, are
+// served as Namespaces even when not loaded as such.
+func NewClient(objects *Objects) *Client {
+	client := &Client{
+		istioClientset: newIstioClientset(objects.Istio),
+		objects:        objects,
+	}
+
+	loaded := make(map[string]bool, len(objects.Namespaces))
+	for _, ns := range objects.Namespaces {
+		loaded[ns.Name] = true
+		client.namespaces = append(client.namespaces, ns)
+	}
+	for _, name := range append(objects.NamespaceNames(), config.Get().IstioNamespace) {
+		if !loaded[name] {
+			loaded[name] = true
+			client.namespaces = append(client.namespaces, core_v1.Namespace{ObjectMeta: meta_v1.ObjectMeta{Name: name}})
+		}
+	}
+
+	return client
+}
+
+func newIstioClientset(objects []runtime.Object) *istio_fake.Clientset {
+	// Istio Fake client has a problem with Gateways, NewSimpleClientset() stores them under a wrong "gatewais"
+	// resource, as a workaround they are created explicitly (see kubetest.K8SClientMock.MockIstio())
+	others := make([]runtime.Object, 0, len(objects))
+	gateways := []*networking_v1alpha3.Gateway{}
+	for _, obj := range objects {
+		if gw, ok := obj.(*networking_v1alpha3.Gateway); ok {
+			gateways = append(gateways, gw)
+		} else {
+			others = append(others, obj)
+		}
+	}
+
+	clientset := istio_fake.NewSimpleClientset(others...)
+	for _, gw := range gateways {
+		if _, err := clientset.NetworkingV1alpha3().Gateways(gw.Namespace).Create(context.TODO(), gw, meta_v1.CreateOptions{}); err != nil {
+			log.Errorf("Error loading Gateway [%s/%s]: %s", gw.Namespace, gw.Name, err)
+		}
+	}
+	return clientset
+}
+
+func (in *Client) Istio() istio.Interface {
+	return in.istioClientset
+}
+
+func (in *Client) GetToken() string {
+	return ""
+}
+
+func (in *Client) IsGatewayAPI() bool {
+	return false
+}
+
+func (in *Client) IsOpenShift() bool {
+	return false
+}
+
+// GetConfigMap returns the loaded ConfigMap. The Istio ConfigMap is optional, when not loaded an empty one
+// is returned, i.e. the default mesh configuration.
+func (in *Client) GetConfigMap(namespace, name string) (*core_v1.ConfigMap, error) {
+	for _, cm := range in.objects.ConfigMaps {
+		if cm.Namespace == namespace && cm.Name == name {
+			return cm.DeepCopy(), nil
+		}
+	}
+	cfg := config.Get()
+	if namespace == cfg.IstioNamespace && name == cfg.ExternalServices.Istio.ConfigMapName {
+		return &core_v1.ConfigMap{ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: namespace}}, nil
+	}
+	return nil, notFound("configmaps", name)
+}
+
+func (in *Client) GetCronJobs(namespace string) ([]batch_v1beta1.CronJob, error) {
+	result := []batch_v1beta1.CronJob{}
+	for _, cj := range in.objects.CronJobs {
+		if cj.Namespace == namespace {
+			result = append(result, cj)
+		}
+	}
+	return result, nil
+}
+
+func (in *Client) GetDaemonSets(namespace string) ([]apps_v1.DaemonSet, error) {
+	result := []apps_v1.DaemonSet{}
+	for _, ds := range in.objects.DaemonSets {
+		if ds.Namespace == namespace {
+			result = append(result, ds)
+		}
+	}
+	return result, nil
+}
+
+func (in *Client) GetDeployments(namespace string) ([]apps_v1.Deployment, error) {
+	result := []apps_v1.Deployment{}
+	for _, d := range in.objects.Deployments {
+		if d.Namespace == namespace {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (in *Client) GetJobs(namespace string) ([]batch_v1.Job, error) {
+	result := []batch_v1.Job{}
+	for _, j := range in.objects.Jobs {
+		if j.Namespace == namespace {
+			result = append(result, j)
+		}
+	}
+	return result, nil
+}
+
+func (in *Client) GetNamespace(namespace string) (*core_v1.Namespace, error) {
+	for _, ns := range in.namespaces {
+		if ns.Name == namespace {
+			return ns.DeepCopy(), nil
+		}
+	}
+	return nil, notFound("namespaces", namespace)
+}
+
+func (in *Client) GetNamespaces(labelSelector string) ([]core_v1.Namespace, error) {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	result := []core_v1.Namespace{}
+	for _, ns := range in.namespaces {
+		if selector.Matches(labels.Set(ns.Labels)) {
+			result = append(result, ns)
+		}
+	}
+	return result, nil
+}
+
+func (in *Client) GetPods(namespace, labelSelector string) ([]core_v1.Pod, error) {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+	result := []core_v1.Pod{}
+	for _, p := range in.objects.Pods {
+		if p.Namespace == namespace && selector.Matches(labels.Set(p.Labels)) {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (in *Client) GetReplicaSets(namespace string) ([]apps_v1.ReplicaSet, error) {
+	result := []apps_v1.ReplicaSet{}
+	for _, rs := range in.objects.ReplicaSets {
+		if rs.Namespace == namespace {
+			result = append(result, rs)
+		}
+	}
+	return result, nil
+}
+
+func (in *Client) GetReplicationControllers(namespace string) ([]core_v1.ReplicationController, error) {
+	result := []core_v1.ReplicationController{}
+	for _, rc := range in.objects.ReplicationControllers {
+		if rc.Namespace == namespace {
+			result = append(result, rc)
+		}
+	}
+	return result, nil
+}
+
+// GetServices returns the namespace services or, given selectorLabels, the services selecting them
+// (see kubernetes.K8SClient.GetServices())
+func (in *Client) GetServices(namespace string, selectorLabels map[string]string) ([]core_v1.Service, error) {
+	result := []core_v1.Service{}
+	for _, svc := range in.objects.Services {
+		if svc.Namespace != namespace {
+			continue
+		}
+		if selectorLabels != nil {
+			svcSelector := labels.Set(svc.Spec.Selector).AsSelector()
+			if svcSelector.Empty() || !svcSelector.Matches(labels.Set(selectorLabels)) {
+				continue
+			}
+		}
+		result = append(result, svc)
+	}
+	return result, nil
+}
+
+func (in *Client) GetStatefulSets(namespace string) ([]apps_v1.StatefulSet, error) {
+	result := []apps_v1.StatefulSet{}
+	for _, ss := range in.objects.StatefulSets {
+		if ss.Namespace == namespace {
+			result = append(result, ss)
+		}
+	}
+	return result, nil
+}
+
+func notFound(resource, name string) error {
+	return errors.NewNotFound(schema.GroupResource{Resource: resource}, name)
+}
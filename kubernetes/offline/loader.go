@@ -0,0 +1,301 @@
+package offline
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	security_v1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
+	apps_v1 "k8s.io/api/apps/v1"
+	batch_v1 "k8s.io/api/batch/v1"
+	batch_v1beta1 "k8s.io/api/batch/v1beta1"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+)
+
+// Location is the position of an object in the YAML files it was loaded from
+type Location struct {
+	File string `json:"file"`
+	Line int    `json:"line"` // 1-based line of the first line of the object's document
+}
+
+// Objects holds the Kubernetes and Istio objects loaded from YAML files, in the order they were loaded.
+// Locations is keyed like the validations, by the object type (the lowercase kind), name and namespace.
+type Objects struct {
+	ConfigMaps             []core_v1.ConfigMap
+	CronJobs               []batch_v1beta1.CronJob
+	DaemonSets             []apps_v1.DaemonSet
+	Deployments            []apps_v1.Deployment
+	Istio                  []runtime.Object
+	Jobs                   []batch_v1.Job
+	Locations              map[models.IstioValidationKey]Location
+	Namespaces             []core_v1.Namespace
+	Pods                   []core_v1.Pod
+	ReplicaSets            []apps_v1.ReplicaSet
+	ReplicationControllers []core_v1.ReplicationController
+	Services               []core_v1.Service
+	StatefulSets           []apps_v1.StatefulSet
+
+	// DefaultNamespace is assigned to the namespaced objects without a namespace, as kubectl would do
+	DefaultNamespace string
+}
+
+// NewObjects returns an empty set of objects, assigning defaultNamespace to objects loaded without a namespace
+func NewObjects(defaultNamespace string) *Objects {
+	return &Objects{
+		Locations:        make(map[models.IstioValidationKey]Location),
+		DefaultNamespace: defaultNamespace,
+	}
+}
+
+// LoadPaths loads the YAML files, and the .yaml and .yml files found walking the directories
+func (o *Objects) LoadPaths(paths ...string) error {
+	for _, path := range paths {
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			// Files given explicitly are loaded whatever their extension
+			if ext := filepath.Ext(file); file != path && ext != ".yaml" && ext != ".yml" {
+				return nil
+			}
+			return o.LoadFile(file)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile loads the objects of a, possibly multi-document, YAML file
+func (o *Objects) LoadFile(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return o.Load(file, data)
+}
+
+// Load loads the objects of the, possibly multi-document, YAML data read from file. Documents of a kind not
+// used by the validations are skipped.
+func (o *Objects) Load(file string, data []byte) error {
+	for _, doc := range splitDocuments(string(data)) {
+		if err := o.loadDocument(Location{File: file, Line: doc.line}, doc.content); err != nil {
+			return fmt.Errorf("%s:%d: %v", file, doc.line, err)
+		}
+	}
+	return nil
+}
+
+// NamespaceNames returns the names of the loaded Namespaces and of the namespaces of the loaded objects, without duplicates
+func (o *Objects) NamespaceNames() []string {
+	names := []string{}
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, ns := range o.Namespaces {
+		add(ns.Name)
+	}
+	for key := range o.Locations {
+		add(key.Namespace)
+	}
+	return names
+}
+
+type document struct {
+	content string
+	line    int
+}
+
+// splitDocuments splits a multi-document YAML on the "---" separators, keeping the line of the first
+// significant (not blank nor a comment) line of each document. Documents without content are dropped.
+func splitDocuments(data string) []document {
+	docs := []document{}
+	var b strings.Builder
+	line := 0
+	flush := func() {
+		if line > 0 {
+			docs = append(docs, document{content: b.String(), line: line})
+		}
+		b.Reset()
+		line = 0
+	}
+
+	for i, l := range strings.Split(data, "\n") {
+		trimmed := strings.TrimSpace(l)
+		if trimmed == "---" || strings.HasPrefix(l, "--- ") {
+			flush()
+			continue
+		}
+		if line == 0 && trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			line = i + 1
+		}
+		b.WriteString(l)
+		b.WriteString("\n")
+	}
+	flush()
+
+	return docs
+}
+
+func (o *Objects) loadDocument(location Location, content string) error {
+	js, err := yaml.ToJSON([]byte(content))
+	if err != nil {
+		return err
+	}
+	typeMeta := meta_v1.TypeMeta{}
+	if err = json.Unmarshal(js, &typeMeta); err != nil {
+		return err
+	}
+	gv, err := schema.ParseGroupVersion(typeMeta.APIVersion)
+	if err != nil {
+		return err
+	}
+
+	var obj interface{}
+	switch gv.Group {
+	case "networking.istio.io":
+		obj = newNetworkingObject(typeMeta.Kind)
+	case "security.istio.io":
+		obj = newSecurityObject(typeMeta.Kind)
+	case "", "apps", "batch":
+		obj = newKubernetesObject(typeMeta.Kind)
+	}
+	if obj == nil {
+		log.Debugf("Skipping [%s] [%s] at %s:%d", typeMeta.APIVersion, typeMeta.Kind, location.File, location.Line)
+		return nil
+	}
+	if err = json.Unmarshal(js, obj); err != nil {
+		return err
+	}
+
+	meta, ok := obj.(meta_v1.Object)
+	if !ok {
+		return fmt.Errorf("[%s] has no metadata", typeMeta.Kind)
+	}
+	if meta.GetName() == "" {
+		return fmt.Errorf("[%s] has no name", typeMeta.Kind)
+	}
+	if meta.GetNamespace() == "" && typeMeta.Kind != "Namespace" {
+		meta.SetNamespace(o.DefaultNamespace)
+	}
+
+	// The same object loaded twice, e.g. from overlapping paths, can not be held by the fake clients
+	key := models.IstioValidationKey{ObjectType: strings.ToLower(typeMeta.Kind), Name: meta.GetName(), Namespace: meta.GetNamespace()}
+	if previous, found := o.Locations[key]; found {
+		return fmt.Errorf("[%s] [%s] in namespace [%s] is already defined at %s:%d", typeMeta.Kind, meta.GetName(), meta.GetNamespace(), previous.File, previous.Line)
+	}
+
+	o.add(obj)
+	o.Locations[key] = location
+	return nil
+}
+
+func newNetworkingObject(kind string) interface{} {
+	switch kind {
+	case "DestinationRule":
+		return &networking_v1alpha3.DestinationRule{}
+	case "EnvoyFilter":
+		return &networking_v1alpha3.EnvoyFilter{}
+	case "Gateway":
+		return &networking_v1alpha3.Gateway{}
+	case "ServiceEntry":
+		return &networking_v1alpha3.ServiceEntry{}
+	case "Sidecar":
+		return &networking_v1alpha3.Sidecar{}
+	case "VirtualService":
+		return &networking_v1alpha3.VirtualService{}
+	case "WorkloadEntry":
+		return &networking_v1alpha3.WorkloadEntry{}
+	case "WorkloadGroup":
+		return &networking_v1alpha3.WorkloadGroup{}
+	}
+	return nil
+}
+
+func newSecurityObject(kind string) interface{} {
+	switch kind {
+	case "AuthorizationPolicy":
+		return &security_v1beta1.AuthorizationPolicy{}
+	case "PeerAuthentication":
+		return &security_v1beta1.PeerAuthentication{}
+	case "RequestAuthentication":
+		return &security_v1beta1.RequestAuthentication{}
+	}
+	return nil
+}
+
+func newKubernetesObject(kind string) interface{} {
+	switch kind {
+	case "ConfigMap":
+		return &core_v1.ConfigMap{}
+	case "CronJob":
+		return &batch_v1beta1.CronJob{}
+	case "DaemonSet":
+		return &apps_v1.DaemonSet{}
+	case "Deployment":
+		return &apps_v1.Deployment{}
+	case "Job":
+		return &batch_v1.Job{}
+	case "Namespace":
+		return &core_v1.Namespace{}
+	case "Pod":
+		return &core_v1.Pod{}
+	case "ReplicaSet":
+		return &apps_v1.ReplicaSet{}
+	case "ReplicationController":
+		return &core_v1.ReplicationController{}
+	case "Service":
+		return &core_v1.Service{}
+	case "StatefulSet":
+		return &apps_v1.StatefulSet{}
+	}
+	return nil
+}
+
+func (o *Objects) add(obj interface{}) {
+	switch t := obj.(type) {
+	case *core_v1.ConfigMap:
+		o.ConfigMaps = append(o.ConfigMaps, *t)
+	case *batch_v1beta1.CronJob:
+		o.CronJobs = append(o.CronJobs, *t)
+	case *apps_v1.DaemonSet:
+		o.DaemonSets = append(o.DaemonSets, *t)
+	case *apps_v1.Deployment:
+		o.Deployments = append(o.Deployments, *t)
+	case *batch_v1.Job:
+		o.Jobs = append(o.Jobs, *t)
+	case *core_v1.Namespace:
+		o.Namespaces = append(o.Namespaces, *t)
+	case *core_v1.Pod:
+		o.Pods = append(o.Pods, *t)
+	case *apps_v1.ReplicaSet:
+		o.ReplicaSets = append(o.ReplicaSets, *t)
+	case *core_v1.ReplicationController:
+		o.ReplicationControllers = append(o.ReplicationControllers, *t)
+	case *core_v1.Service:
+		o.Services = append(o.Services, *t)
+	case *apps_v1.StatefulSet:
+		o.StatefulSets = append(o.StatefulSets, *t)
+	case runtime.Object:
+		o.Istio = append(o.Istio, t)
+	}
+}
@@ -0,0 +1,139 @@
+package offline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/models"
+)
+
+const testYaml = `# bookinfo
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: bookinfo
+  labels:
+    istio-injection: enabled
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: reviews
+  namespace: bookinfo
+spec:
+  selector:
+    app: reviews
+---
+
+# no namespace
+apiVersion: networking.istio.io/v1alpha3
+kind: Gateway
+metadata:
+  name: gateway
+spec:
+  selector:
+    istio: ingressgateway
+---
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: Gateway
+metadata:
+  name: skipped
+---
+apiVersion: networking.istio.io/v1beta1
+kind: VirtualService
+metadata:
+  name: reviews
+  namespace: bookinfo
+spec:
+  hosts:
+  - reviews
+`
+
+func TestLoad(t *testing.T) {
+	assert := assert.New(t)
+
+	objects := NewObjects("default")
+	assert.NoError(objects.Load("bookinfo.yaml", []byte(testYaml)))
+
+	assert.Len(objects.Namespaces, 1)
+	assert.Len(objects.Services, 1)
+	assert.Len(objects.Istio, 2)
+	assert.Equal(map[models.IstioValidationKey]Location{
+		{ObjectType: "namespace", Name: "bookinfo"}:                            {File: "bookinfo.yaml", Line: 2},
+		{ObjectType: "service", Name: "reviews", Namespace: "bookinfo"}:        {File: "bookinfo.yaml", Line: 9},
+		{ObjectType: "gateway", Name: "gateway", Namespace: "default"}:         {File: "bookinfo.yaml", Line: 20},
+		{ObjectType: "virtualservice", Name: "reviews", Namespace: "bookinfo"}: {File: "bookinfo.yaml", Line: 33},
+	}, objects.Locations)
+
+	gw, ok := objects.Istio[0].(*networking_v1alpha3.Gateway)
+	assert.True(ok)
+	assert.Equal("default", gw.Namespace)
+	assert.Equal(map[string]string{"istio": "ingressgateway"}, gw.Spec.Selector)
+
+	assert.ElementsMatch([]string{"bookinfo", "default"}, objects.NamespaceNames())
+}
+
+func TestLoadErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	objects := NewObjects("default")
+	err := objects.Load("bad.yaml", []byte("apiVersion: v1\nkind: Service\nmetadata:\n  namespace: bookinfo\n"))
+	assert.EqualError(err, "bad.yaml:1: [Service] has no name")
+
+	err = objects.Load("bad.yaml", []byte("---\napiVersion: v1\nkind: Service\nmetadata: [\n"))
+	assert.Error(err)
+	assert.Contains(err.Error(), "bad.yaml:2: ")
+}
+
+func TestLoadDuplicate(t *testing.T) {
+	assert := assert.New(t)
+
+	objects := NewObjects("default")
+	assert.NoError(objects.Load("bookinfo.yaml", []byte(testYaml)))
+
+	// the same file loaded twice, e.g. given along with its directory
+	err := objects.Load("dir/bookinfo.yaml", []byte(testYaml))
+	assert.EqualError(err, "dir/bookinfo.yaml:2: [Namespace] [bookinfo] in namespace [] is already defined at bookinfo.yaml:2")
+
+	objects = NewObjects("default")
+	err = objects.Load("reviews.yaml", []byte(testYaml+"---\napiVersion: networking.istio.io/v1beta1\nkind: VirtualService\nmetadata:\n  name: reviews\n  namespace: bookinfo\n"))
+	assert.EqualError(err, "reviews.yaml:42: [VirtualService] [reviews] in namespace [bookinfo] is already defined at reviews.yaml:33")
+}
+
+func TestClient(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	objects := NewObjects("default")
+	assert.NoError(objects.Load("bookinfo.yaml", []byte(testYaml)))
+	client := NewClient(objects)
+
+	namespaces, err := client.GetNamespaces("istio-injection=enabled")
+	assert.NoError(err)
+	assert.Len(namespaces, 1)
+	assert.Equal("bookinfo", namespaces[0].Name)
+
+	// namespaces of the objects and the Istio namespace are served
+	for _, name := range []string{"bookinfo", "default", "istio-system"} {
+		ns, err := client.GetNamespace(name)
+		assert.NoError(err)
+		assert.Equal(name, ns.Name)
+	}
+	_, err = client.GetNamespace("unknown")
+	assert.Error(err)
+
+	services, err := client.GetServices("bookinfo", map[string]string{"app": "reviews", "version": "v1"})
+	assert.NoError(err)
+	assert.Len(services, 1)
+	services, err = client.GetServices("bookinfo", map[string]string{"app": "ratings"})
+	assert.NoError(err)
+	assert.Empty(services)
+
+	// the default mesh configuration is served when not loaded
+	cm, err := client.GetConfigMap("istio-system", "istio")
+	assert.NoError(err)
+	assert.Nil(cm.Data)
+}
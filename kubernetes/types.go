@@ -177,6 +177,8 @@ type ExportedResources struct {
 	VirtualServices  []networking_v1alpha3.VirtualService  `json:"virtualservices"`
 	DestinationRules []networking_v1alpha3.DestinationRule `json:"destinationrules"`
 	ServiceEntries   []networking_v1alpha3.ServiceEntry    `json:"serviceentries"`
+	// EnvoyFilters of the root namespace, applying to the workloads of every namespace
+	EnvoyFilters []networking_v1alpha3.EnvoyFilter `json:"envoyfilters"`
 }
 
 type ProxyStatus struct {
@@ -25,6 +25,12 @@ build: go-check
 	${GO_BUILD_ENVVARS} ${GO} build \
 		-o ${GOPATH}/bin/kiali -ldflags "-X main.version=${VERSION} -X main.commitHash=${COMMIT_HASH}"
 
+## build-validate: Build the kiali-validate binary, running the Kiali validations on Istio YAML files
+build-validate: go-check
+	@echo Building kiali-validate...
+	${GO_BUILD_ENVVARS} ${GO} build \
+		-o ${GOPATH}/bin/kiali-validate ./cmd/kiali-validate
+
 ## build-linux-multi-arch: Build Kiali binary with arch suffix for multi-arch
 build-linux-multi-arch:
 	@for arch in ${TARGET_ARCHS}; do \
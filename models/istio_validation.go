@@ -2,11 +2,21 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/log"
 )
 
+// ValidationsIgnoreAnnotation lists the codes of the checks that are not reported for the annotated
+// object, separated by commas, e.g. "KIA1101,KIA1106"
+const ValidationsIgnoreAnnotation = "kiali.io/validations-ignore"
+
 // NamespaceValidations represents a set of IstioValidations grouped by namespace
 type NamespaceValidations map[string]IstioValidations
 
@@ -96,6 +106,7 @@ var ObjectTypeSingular = map[string]string{
 	"gateways":               "gateway",
 	"virtualservices":        "virtualservice",
 	"destinationrules":       "destinationrule",
+	"envoyfilters":           "envoyfilter",
 	"serviceentries":         "serviceentry",
 	"rules":                  "rule",
 	"quotaspecs":             "quotaspec",
@@ -108,6 +119,8 @@ var ObjectTypeSingular = map[string]string{
 	"sidecars":               "sidecar",
 	"peerauthentications":    "peerauthentication",
 	"requestauthentications": "requestauthentication",
+	"workloadentries":        "workloadentry",
+	"workloadgroups":         "workloadgroup",
 }
 
 var checkDescriptors = map[string]IstioCheck{
@@ -176,6 +189,21 @@ var checkDescriptors = map[string]IstioCheck{
 		Message:  "This subset has not labels",
 		Severity: WarningSeverity,
 	},
+	"envoyfilter.listener.samepriority": {
+		Code:     "KIA1201",
+		Message:  "More than one EnvoyFilter patching the same listener of the same workloads with the same priority",
+		Severity: WarningSeverity,
+	},
+	"envoyfilter.proxy.versionnotfound": {
+		Code:     "KIA1202",
+		Message:  "No running sidecar matches this proxy version",
+		Severity: WarningSeverity,
+	},
+	"envoyfilter.proxy.invalidversion": {
+		Code:     "KIA1203",
+		Message:  "Proxy version is not a valid regular expression",
+		Severity: ErrorSeverity,
+	},
 	"gateways.multimatch": {
 		Code:     "KIA0301",
 		Message:  "More than one Gateway for the same host port combination",
@@ -306,6 +334,26 @@ var checkDescriptors = map[string]IstioCheck{
 		Message:  "Subset not found",
 		Severity: WarningSeverity,
 	},
+	"workloadentry.labels.serviceentrynotfound": {
+		Code:     "KIA1301",
+		Message:  "No ServiceEntry workloadSelector or Service selector matches the labels of this WorkloadEntry",
+		Severity: WarningSeverity,
+	},
+	"workloadentry.address.multimatch": {
+		Code:     "KIA1302",
+		Message:  "More than one WorkloadEntry with the same address in the same network",
+		Severity: ErrorSeverity,
+	},
+	"workloadentry.ports.notfound": {
+		Code:     "KIA1303",
+		Message:  "Port not declared by the ServiceEntry selecting this WorkloadEntry",
+		Severity: WarningSeverity,
+	},
+	"workloadgroup.template.entryconflict": {
+		Code:     "KIA1401",
+		Message:  "Template conflicts with the WorkloadEntries having the labels of this WorkloadGroup",
+		Severity: WarningSeverity,
+	},
 	"validation.unable.cross-namespace": {
 		Code:     "KIA0001",
 		Message:  "Unable to verify the validity, cross-namespace validation is not supported for this field",
@@ -487,3 +535,137 @@ func (iv *IstioValidations) StripIgnoredChecks() {
 		}
 	}
 }
+
+// ApplyValidationRules removes the checks ignored by the ValidationsIgnoreAnnotation of their object and applies
+// the validation rules of the configuration to the remaining checks. The objects hold the labels and
+// annotations of the validated objects: rules with a label selector never match a check of an object not
+// found in them. The validity of an object changed by the rules is given by its remaining error checks.
+func (iv IstioValidations) ApplyValidationRules(objects map[IstioValidationKey]meta_v1.ObjectMeta) {
+	rules := compileValidationRules(config.Get().KialiFeatureFlags.Validations.Rules)
+
+	for key, validation := range iv {
+		object, found := objects[key]
+		ignoredCodes := map[string]bool{}
+		for _, code := range strings.Split(object.Annotations[ValidationsIgnoreAnnotation], ",") {
+			if code = strings.TrimSpace(code); code != "" {
+				ignoredCodes[code] = true
+			}
+		}
+
+		changed := false
+		idx := 0
+		for _, check := range validation.Checks {
+			if ignoredCodes[check.Code] {
+				log.Infof("Ignoring validation failure [%+v] for object [%s:%s] in namespace [%s] by annotation", check, key.ObjectType, key.Name, key.Namespace)
+				changed = true
+				continue
+			}
+			if rule := findValidationRule(rules, check.Code, key.Namespace, object.Labels, found); rule != nil {
+				if rule.Ignore {
+					log.Infof("Ignoring validation failure [%+v] for object [%s:%s] in namespace [%s] by rule", check, key.ObjectType, key.Name, key.Namespace)
+					changed = true
+					continue
+				}
+				if rule.Severity != "" && rule.Severity != check.Severity {
+					// checks may be shared by several validations, the rule only applies to this object
+					overridden := *check
+					overridden.Severity = rule.Severity
+					check = &overridden
+					changed = true
+				}
+			}
+			validation.Checks[idx] = check
+			idx++
+		}
+		// Prevent memory leak - nil out ignored checks
+		for extraIdx := idx; extraIdx < len(validation.Checks); extraIdx++ {
+			validation.Checks[extraIdx] = nil
+		}
+		validation.Checks = validation.Checks[:idx]
+
+		if changed {
+			validation.Valid = true
+			for _, check := range validation.Checks {
+				if check.Severity == ErrorSeverity {
+					validation.Valid = false
+				}
+			}
+		}
+	}
+}
+
+// ValidateValidationRules returns an error for the first invalid rule of the configuration
+func ValidateValidationRules(rules []config.ValidationRule) error {
+	for i, rule := range rules {
+		if rule.Code == "" {
+			return fmt.Errorf("validation rule [%d] has no code", i)
+		}
+		switch SeverityLevel(rule.Severity) {
+		case "", ErrorSeverity, WarningSeverity:
+		default:
+			return fmt.Errorf("validation rule [%d] for code [%s] has an invalid severity [%s]", i, rule.Code, rule.Severity)
+		}
+		for _, ns := range rule.Namespaces {
+			if _, err := regexp.Compile(ns); err != nil {
+				return fmt.Errorf("validation rule [%d] for code [%s] has an invalid namespace [%s]: %v", i, rule.Code, ns, err)
+			}
+		}
+		if _, err := labels.Parse(rule.LabelSelector); err != nil {
+			return fmt.Errorf("validation rule [%d] for code [%s] has an invalid label selector [%s]: %v", i, rule.Code, rule.LabelSelector, err)
+		}
+	}
+	return nil
+}
+
+type validationRule struct {
+	Code          string
+	Ignore        bool
+	LabelSelector labels.Selector
+	Namespaces    []*regexp.Regexp
+	Severity      SeverityLevel
+}
+
+// compileValidationRules returns the valid rules of the configuration, ready to be matched
+func compileValidationRules(rules []config.ValidationRule) []validationRule {
+	compiled := make([]validationRule, 0, len(rules))
+	for _, rule := range rules {
+		if err := ValidateValidationRules([]config.ValidationRule{rule}); err != nil {
+			log.Warningf("Skipping invalid %v", err)
+			continue
+		}
+		vr := validationRule{Code: rule.Code, Ignore: rule.Ignore, Severity: SeverityLevel(rule.Severity)}
+		if rule.LabelSelector != "" {
+			vr.LabelSelector, _ = labels.Parse(rule.LabelSelector)
+		}
+		for _, ns := range rule.Namespaces {
+			vr.Namespaces = append(vr.Namespaces, regexp.MustCompile(ns))
+		}
+		compiled = append(compiled, vr)
+	}
+	return compiled
+}
+
+func findValidationRule(rules []validationRule, code, namespace string, objectLabels map[string]string, found bool) *validationRule {
+	for i, rule := range rules {
+		if rule.Code != code {
+			continue
+		}
+		if len(rule.Namespaces) > 0 {
+			nsMatch := false
+			for _, ns := range rule.Namespaces {
+				if ns.MatchString(namespace) {
+					nsMatch = true
+					break
+				}
+			}
+			if !nsMatch {
+				continue
+			}
+		}
+		if rule.LabelSelector != nil && (!found || !rule.LabelSelector.Matches(labels.Set(objectLabels))) {
+			continue
+		}
+		return &rules[i]
+	}
+	return nil
+}
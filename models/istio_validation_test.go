@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/kiali/kiali/config"
 )
@@ -84,3 +85,108 @@ func TestSummarizeValidations(t *testing.T) {
 	assert.Equal(1, summary.Warnings)
 	assert.Equal(1, summary.Errors)
 }
+
+func fakeRulesValidations() IstioValidations {
+	shared := &IstioCheck{Code: "KIA1106", Severity: WarningSeverity, Message: "More than one Virtual Service for same host"}
+	return IstioValidations{
+		BuildKey("virtualservice", "reviews", "bookinfo"): &IstioValidation{
+			Name:       "reviews",
+			ObjectType: "virtualservice",
+			Valid:      false,
+			Checks: []*IstioCheck{
+				{Code: "KIA1101", Severity: ErrorSeverity, Message: "DestinationWeight on route doesn't have a valid service (host not found)"},
+				{Code: "KIA1104", Severity: WarningSeverity, Message: "The weight is assumed to be 100 because there is only one route destination"},
+				shared,
+			},
+		},
+		BuildKey("virtualservice", "reviews-canary", "bookinfo"): &IstioValidation{
+			Name:       "reviews-canary",
+			ObjectType: "virtualservice",
+			Valid:      true,
+			Checks:     []*IstioCheck{shared},
+		},
+		BuildKey("virtualservice", "ratings", "travels"): &IstioValidation{
+			Name:       "ratings",
+			ObjectType: "virtualservice",
+			Valid:      false,
+			Checks: []*IstioCheck{
+				{Code: "KIA1101", Severity: ErrorSeverity, Message: "DestinationWeight on route doesn't have a valid service (host not found)"},
+			},
+		},
+	}
+}
+
+func TestApplyValidationRulesAnnotation(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	validations := fakeRulesValidations()
+	validations.ApplyValidationRules(map[IstioValidationKey]meta_v1.ObjectMeta{
+		BuildKey("virtualservice", "reviews", "bookinfo"): {
+			Name:        "reviews",
+			Namespace:   "bookinfo",
+			Annotations: map[string]string{ValidationsIgnoreAnnotation: "KIA1101, KIA1106"},
+		},
+	})
+
+	reviews := validations[BuildKey("virtualservice", "reviews", "bookinfo")]
+	assert.Len(reviews.Checks, 1)
+	assert.Equal("KIA1104", reviews.Checks[0].Code)
+	assert.True(reviews.Valid)
+
+	// other objects are not changed
+	assert.Len(validations[BuildKey("virtualservice", "reviews-canary", "bookinfo")].Checks, 1)
+	assert.Len(validations[BuildKey("virtualservice", "ratings", "travels")].Checks, 1)
+	assert.False(validations[BuildKey("virtualservice", "ratings", "travels")].Valid)
+}
+
+func TestApplyValidationRules(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	conf.KialiFeatureFlags.Validations.Rules = []config.ValidationRule{
+		{Code: "KIA1101", Severity: "warning", Namespaces: []string{"^book.*"}},
+		{Code: "KIA1101", Ignore: true},
+		{Code: "KIA1106", Severity: "error", LabelSelector: "release=canary"},
+		{Code: "KIA1104", Severity: "unknown"},
+	}
+	config.Set(conf)
+
+	validations := fakeRulesValidations()
+	validations.ApplyValidationRules(map[IstioValidationKey]meta_v1.ObjectMeta{
+		BuildKey("virtualservice", "reviews-canary", "bookinfo"): {
+			Name:      "reviews-canary",
+			Namespace: "bookinfo",
+			Labels:    map[string]string{"app": "reviews", "release": "canary"},
+		},
+	})
+
+	// the first matching rule applies
+	reviews := validations[BuildKey("virtualservice", "reviews", "bookinfo")]
+	assert.Len(reviews.Checks, 3)
+	assert.Equal(WarningSeverity, reviews.Checks[0].Severity)
+	assert.Equal(WarningSeverity, reviews.Checks[1].Severity)
+	assert.Equal(WarningSeverity, reviews.Checks[2].Severity)
+	assert.True(reviews.Valid)
+
+	canary := validations[BuildKey("virtualservice", "reviews-canary", "bookinfo")]
+	assert.Len(canary.Checks, 1)
+	assert.Equal(ErrorSeverity, canary.Checks[0].Severity)
+	assert.False(canary.Valid)
+
+	ratings := validations[BuildKey("virtualservice", "ratings", "travels")]
+	assert.Empty(ratings.Checks)
+	assert.True(ratings.Valid)
+}
+
+func TestValidateValidationRules(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(ValidateValidationRules([]config.ValidationRule{
+		{Code: "KIA1101", Severity: "warning", Namespaces: []string{"^book.*"}, LabelSelector: "release in (canary)"},
+		{Code: "KIA1106", Ignore: true},
+	}))
+	assert.EqualError(ValidateValidationRules([]config.ValidationRule{{Severity: "warning"}}), "validation rule [0] has no code")
+	assert.EqualError(ValidateValidationRules([]config.ValidationRule{{Code: "KIA1101", Severity: "info"}}), "validation rule [0] for code [KIA1101] has an invalid severity [info]")
+	assert.Error(ValidateValidationRules([]config.ValidationRule{{Code: "KIA1101", Namespaces: []string{"book("}}}))
+	assert.Error(ValidateValidationRules([]config.ValidationRule{{Code: "KIA1101", LabelSelector: "release in canary"}}))
+}
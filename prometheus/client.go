@@ -14,6 +14,7 @@ import (
 	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
 
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/kubernetes"
@@ -115,6 +116,24 @@ func NewClientForConfig(cfg config.PrometheusConfig) (*Client, error) {
 	return &client, nil
 }
 
+// NewClientForRestConfig creates a new client to a Prometheus API proxied by a Kubernetes API server, authenticated
+// with the rest config credentials. It is meant for querying the Prometheus of a remote cluster through its API.
+// Note that the Prom Cache is not keyed by cluster, so its cached methods must not be used for a remote cluster.
+// It returns an error on any problem.
+func NewClientForRestConfig(url string, restConfig *rest.Config) (*Client, error) {
+	roundTripper, err := rest.TransportFor(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	p8s, err := api.NewClient(api.Config{Address: url, RoundTripper: roundTripper})
+	if err != nil {
+		return nil, errors.NewServiceUnavailable(err.Error())
+	}
+	client := Client{p8s: p8s, api: prom_v1.NewAPI(p8s), ctx: context.Background()}
+	return &client, nil
+}
+
 // Inject allows for replacing the API with a mock For testing
 func (in *Client) Inject(api prom_v1.API) {
 	in.api = api
@@ -119,6 +119,13 @@ func (srw *statusResponseWriter) WriteHeader(code int) {
 	srw.StatusCode = code
 }
 
+// Flush implements http.Flusher, which is required by streaming handlers
+func (srw *statusResponseWriter) Flush() {
+	if flusher, ok := srw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 // updateMetric evaluates the StatusCode, if there is an error, increase the API failure counter, otherwise save the duration
 func updateMetric(route string, srw *statusResponseWriter, timer *prometheus.Timer) {
 	// Always measure the duration even if the API call ended in an error
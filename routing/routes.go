@@ -287,6 +287,7 @@ func NewRoutes() (r *Routes) {
 		// swagger:route PATCH /namespaces/{namespace}/istio/{object_type}/{object} config istioConfigUpdate
 		// ---
 		// Endpoint to update the Istio Config of an Istio object used for templates and adapters using Json Merge Patch strategy.
+		// On a dry run nothing is updated, the validations of the namespace with the updated object are returned instead.
 		//
 		//     Consumes:
 		//	   - application/json
@@ -312,6 +313,7 @@ func NewRoutes() (r *Routes) {
 		// swagger:route POST /namespaces/{namespace}/istio/{object_type} config istioConfigCreate
 		// ---
 		// Endpoint to create an Istio object by using an Istio Config item
+		// On a dry run nothing is created, the validations of the namespace with the created object are returned instead.
 		//
 		//     Produces:
 		//     - application/json
@@ -1110,6 +1112,154 @@ func NewRoutes() (r *Routes) {
 			handlers.GraphNamespaces,
 			true,
 		},
+		// swagger:route GET /namespaces/graph/diff graphs graphNamespacesDiff
+		// ---
+		// The backing JSON for a namespaces graph comparing two time windows. Nodes and edges are tagged as added, removed, changed or unchanged.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      200: graphResponse
+		//
+		{
+			"GraphNamespacesDiff",
+			"GET",
+			"/api/namespaces/graph/diff",
+			handlers.GraphNamespacesDiff,
+			true,
+		},
+		// swagger:route GET /namespaces/graph/matrix graphs graphNamespacesMatrix
+		// ---
+		// The namespace dependency matrix of a namespaces graph, the traffic between each pair of namespaces.
+		//
+		//     Produces:
+		//     - application/json
+		//     - text/csv
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      200: graphMatrixResponse
+		//
+		{
+			"GraphNamespacesMatrix",
+			"GET",
+			"/api/namespaces/graph/matrix",
+			handlers.GraphNamespacesMatrix,
+			true,
+		},
+		// swagger:route GET /namespaces/graph/path graphs graphNamespacesPath
+		// ---
+		// The simple paths between two nodes of a namespaces graph, ranked by cumulative response time or error rate.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      200: graphPathResponse
+		//
+		{
+			"GraphNamespacesPath",
+			"GET",
+			"/api/namespaces/graph/path",
+			handlers.GraphNamespacesPath,
+			true,
+		},
+		// swagger:route GET /namespaces/graph/stream graphs graphNamespacesStream
+		// ---
+		// A namespaces graph stream, as Server-Sent Events. The first event provides the full graph, subsequent events provide deltas.
+		//
+		//     Produces:
+		//     - text/event-stream
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      200: graphResponse
+		//
+		{
+			"GraphNamespacesStream",
+			"GET",
+			"/api/namespaces/graph/stream",
+			handlers.GraphNamespacesStream,
+			true,
+		},
+		// swagger:route POST /namespaces/graph/snapshots graphs graphSnapshotSave
+		// ---
+		// Generates a namespaces graph and saves it as a snapshot, for later replay. The oldest snapshots beyond the configured retention limits are removed.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      200: graphSnapshotInfoResponse
+		//
+		{
+			"GraphSnapshotSave",
+			"POST",
+			"/api/namespaces/graph/snapshots",
+			handlers.GraphSnapshotSave,
+			true,
+		},
+		// swagger:route GET /namespaces/graph/snapshots graphs graphSnapshots
+		// ---
+		// The saved graph snapshots whose namespaces are accessible to the client, most recent first.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      200: graphSnapshotsResponse
+		//
+		{
+			"GraphSnapshots",
+			"GET",
+			"/api/namespaces/graph/snapshots",
+			handlers.GraphSnapshots,
+			true,
+		},
+		// swagger:route GET /namespaces/graph/snapshots/{snapshot} graphs graphSnapshot
+		// ---
+		// A saved graph snapshot, the graph exactly as it was generated and the options used to generate it.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      404: notFoundError
+		//      500: internalError
+		//      200: graphSnapshotResponse
+		//
+		{
+			"GraphSnapshot",
+			"GET",
+			"/api/namespaces/graph/snapshots/{snapshot}",
+			handlers.GraphSnapshot,
+			true,
+		},
 		// swagger:route GET /namespaces/{namespace}/aggregates/{aggregate}/{aggregateValue}/graph graphs graphAggregate
 		// ---
 		// The backing JSON for an aggregate node detail graph. (supported graphTypes: app | versionedApp | workload)
@@ -0,0 +1,45 @@
+package data
+
+import (
+	api_networking_v1alpha3 "istio.io/api/networking/v1alpha3"
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+)
+
+func CreateEnvoyFilter(name string, namespace string) *networking_v1alpha3.EnvoyFilter {
+	ef := networking_v1alpha3.EnvoyFilter{}
+	ef.Name = name
+	ef.Namespace = namespace
+	ef.ClusterName = "svc.cluster.local"
+	return &ef
+}
+
+func AddSelectorToEnvoyFilter(selector map[string]string, ef *networking_v1alpha3.EnvoyFilter) *networking_v1alpha3.EnvoyFilter {
+	ef.Spec.WorkloadSelector = &api_networking_v1alpha3.WorkloadSelector{
+		Labels: selector,
+	}
+	return ef
+}
+
+func AddListenerPatchToEnvoyFilter(context api_networking_v1alpha3.EnvoyFilter_PatchContext, portNumber uint32, proxyVersion string, ef *networking_v1alpha3.EnvoyFilter) *networking_v1alpha3.EnvoyFilter {
+	match := &api_networking_v1alpha3.EnvoyFilter_EnvoyConfigObjectMatch{
+		Context: context,
+		ObjectTypes: &api_networking_v1alpha3.EnvoyFilter_EnvoyConfigObjectMatch_Listener{
+			Listener: &api_networking_v1alpha3.EnvoyFilter_ListenerMatch{
+				PortNumber: portNumber,
+			},
+		},
+	}
+	if proxyVersion != "" {
+		match.Proxy = &api_networking_v1alpha3.EnvoyFilter_ProxyMatch{
+			ProxyVersion: proxyVersion,
+		}
+	}
+	ef.Spec.ConfigPatches = append(ef.Spec.ConfigPatches, &api_networking_v1alpha3.EnvoyFilter_EnvoyConfigObjectPatch{
+		ApplyTo: api_networking_v1alpha3.EnvoyFilter_LISTENER,
+		Match:   match,
+		Patch: &api_networking_v1alpha3.EnvoyFilter_Patch{
+			Operation: api_networking_v1alpha3.EnvoyFilter_Patch_MERGE,
+		},
+	})
+	return ef
+}
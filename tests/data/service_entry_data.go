@@ -54,3 +54,10 @@ func CreateEmptyPortDefinition(port uint32, portName, protocolName string) *api_
 	}
 	return &p
 }
+
+func AddWorkloadSelectorToServiceEntry(selector map[string]string, se *networking_v1alpha3.ServiceEntry) *networking_v1alpha3.ServiceEntry {
+	se.Spec.WorkloadSelector = &api_networking_v1alpha3.WorkloadSelector{
+		Labels: selector,
+	}
+	return se
+}
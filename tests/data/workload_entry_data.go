@@ -0,0 +1,34 @@
+package data
+
+import (
+	api_networking_v1alpha3 "istio.io/api/networking/v1alpha3"
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+)
+
+func CreateWorkloadEntry(name, namespace, address string, labels map[string]string) *networking_v1alpha3.WorkloadEntry {
+	we := networking_v1alpha3.WorkloadEntry{}
+	we.Name = name
+	we.Namespace = namespace
+	we.Spec.Address = address
+	we.Spec.Labels = labels
+	return &we
+}
+
+func AddPortToWorkloadEntry(name string, number uint32, we *networking_v1alpha3.WorkloadEntry) *networking_v1alpha3.WorkloadEntry {
+	if we.Spec.Ports == nil {
+		we.Spec.Ports = map[string]uint32{}
+	}
+	we.Spec.Ports[name] = number
+	return we
+}
+
+func CreateWorkloadGroup(name, namespace string, labels map[string]string, template *api_networking_v1alpha3.WorkloadEntry) *networking_v1alpha3.WorkloadGroup {
+	wg := networking_v1alpha3.WorkloadGroup{}
+	wg.Name = name
+	wg.Namespace = namespace
+	wg.Spec.Metadata = &api_networking_v1alpha3.WorkloadGroup_ObjectMeta{
+		Labels: labels,
+	}
+	wg.Spec.Template = template
+	return &wg
+}